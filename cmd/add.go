@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/donaldgifford/forge/internal/create"
+	"github.com/donaldgifford/forge/internal/ui"
+)
+
+var (
+	addSetVars     []string
+	addValuesFiles []string
+	addProjectDir  string
+	addRegistryDir string
+	addUseDefault  bool
+	addRunHooks    bool
+	addDryRun      bool
+	addStrict      bool
+	addSeed        string
+	addOnConflict  string
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <blueprint>",
+	Short: "Apply a blueprint to an existing project",
+	Long: `Render a blueprint into an existing project, e.g. to adopt ci/github in a
+service created earlier. The blueprint is specified as for create.
+
+Files the project does not have yet are created. Files that exist with
+different content are handled according to --on-conflict:
+
+  skip       keep the existing file (default)
+  overwrite  replace it with the blueprint's version
+  merge      merge the blueprint's version in, with conflict markers where
+             they differ
+
+Variables the project already has values for (from .forge-lock.yaml) are
+not prompted again. The blueprint is recorded in .forge-lock.yaml next to
+the blueprint the project was created from, so check and sync cover its
+defaults and managed files too. Files another applied blueprint already
+tracks are left alone.
+
+The blueprint's post_create hooks are written for new projects (e.g. git
+init) and are not run unless --run-hooks is given.
+
+Use --dry-run to print what would happen without writing anything.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdd,
+}
+
+func init() {
+	addCmd.Flags().StringArrayVar(&addSetVars, "set", nil, "set a variable value (key=value, can be repeated)")
+	addCmd.Flags().StringArrayVar(&addValuesFiles, "values", nil,
+		"YAML or JSON file of variable values (can be repeated; later files and --set take precedence)")
+	addCmd.Flags().StringVarP(&addProjectDir, "project-dir", "C", ".", "project directory to add the blueprint to")
+	addCmd.Flags().StringVar(&addRegistryDir, "registry-dir", "", "path or URL to the blueprint registry")
+	addCmd.Flags().BoolVar(&addUseDefault, "defaults", false, "use all default values without prompting")
+	addCmd.Flags().BoolVar(&addRunHooks, "run-hooks", false, "run the blueprint's post-create hooks in the project")
+	addCmd.Flags().BoolVar(&addDryRun, "dry-run", false, "show what would be written without writing anything")
+	addCmd.Flags().BoolVar(&addStrict, "strict", false, "fail on undefined variables in templates")
+	addCmd.Flags().StringVar(&addSeed, "seed", "", "seed for reproducible uuidv4 values in templates")
+	addCmd.Flags().StringVar(&addOnConflict, "on-conflict", create.ConflictSkip,
+		"what to do with existing files that differ (skip, overwrite, merge)")
+	rootCmd.AddCommand(addCmd)
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	overrides, err := parseOverrides(addSetVars)
+	if err != nil {
+		return err
+	}
+
+	values, err := loadValuesFiles(addValuesFiles)
+	if err != nil {
+		return err
+	}
+
+	logger := slog.Default()
+	blueprintRef := args[0]
+
+	resolvedDir, regURL, defaultURL, cleanup, err := resolveBlueprintRegistry(cmd.Context(), logger, addRegistryDir, blueprintRef)
+	if err != nil {
+		return err
+	}
+
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	resolvedDir, ref, versionCleanup, err := checkoutVersion(cmd.Context(), logger, resolvedDir, blueprintRef)
+	if err != nil {
+		return err
+	}
+
+	if versionCleanup != nil {
+		defer versionCleanup()
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	now, err := sourceDateEpoch()
+	if err != nil {
+		return err
+	}
+
+	w := ui.NewWriter(noColor)
+
+	result, err := create.Add(&create.Opts{
+		Context:            ctx,
+		BlueprintRef:       blueprintRef,
+		OutputDir:          addProjectDir,
+		RegistryDir:        resolvedDir,
+		RegistryURL:        regURL,
+		DefaultRegistryURL: defaultURL,
+		Ref:                ref,
+		Overrides:          overrides,
+		Values:             values,
+		UseDefaults:        addUseDefault,
+		RunHooks:           addRunHooks,
+		DryRun:             addDryRun,
+		Seed:               addSeed,
+		Strict:             addStrict,
+		Now:                now,
+		OnConflict:         addOnConflict,
+		ForgeVersion:       buildVersion,
+		PromptFn:           interactivePrompt(ctx, addUseDefault),
+		Stdout:             w.Out(),
+		Stderr:             w.ErrOut(),
+		Logger:             logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	printAddSummary(w, result, addDryRun)
+	printHookSummary(w, result.Hooks)
+
+	return nil
+}
+
+// printAddSummary reports what add did, or would do, with each file.
+func printAddSummary(w *ui.Writer, result *create.AddResult, dryRun bool) {
+	counts := make(map[create.AddAction]int)
+
+	for _, f := range result.Files {
+		counts[f.Action]++
+
+		switch {
+		case f.TrackedBy != "":
+			w.Infof("skipped: %s (tracked by %s)", f.Path, f.TrackedBy)
+		case f.Action == create.ActionConflict:
+			w.Warningf("conflict: %s", f.Path)
+		case f.Action == create.ActionSkipped:
+			w.Infof("skipped: %s (exists)", f.Path)
+		case f.Action != create.ActionUnchanged:
+			w.Successf("%s: %s", f.Action, f.Path)
+		}
+	}
+
+	verb := "Added"
+	if dryRun {
+		verb = "Would add"
+	}
+
+	w.Infof("%s %q to %s: %d created, %d overwritten, %d merged, %d conflicts, %d skipped, %d unchanged",
+		verb, result.Blueprint, result.ProjectDir,
+		counts[create.ActionCreated], counts[create.ActionOverwritten], counts[create.ActionMerged],
+		counts[create.ActionConflict], counts[create.ActionSkipped], counts[create.ActionUnchanged])
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
 	"github.com/donaldgifford/forge/internal/check"
+	"github.com/donaldgifford/forge/internal/lockfile"
 )
 
 var (
@@ -25,7 +27,10 @@ Without --registry-dir, compares local file hashes against lockfile hashes
 to detect local modifications.
 
 With --registry-dir, also compares against the registry source to detect
-upstream changes. Statuses: modified-locally, upstream-changed, both-changed.`,
+upstream changes. Statuses: modified-locally, upstream-changed, both-changed.
+Upstream changes are those since the commit recorded in the lockfile, so
+local edits kept by a merge are not mistaken for them.
+Files whose permissions differ from the lockfile are reported as mode-changed.`,
 	RunE: runCheck,
 }
 
@@ -47,9 +52,13 @@ func runCheck(cmd *cobra.Command, _ []string) error {
 		defer cleanup()
 	}
 
+	baseDir, baseCleanup := resolveCheckBase(cmd.Context(), logger)
+	defer baseCleanup()
+
 	opts := &check.Opts{
 		ProjectDir:   ".",
 		RegistryDir:  resolvedRegistryDir,
+		BaseDir:      baseDir,
 		OutputFormat: checkOutputFormat,
 		Writer:       os.Stdout,
 	}
@@ -76,3 +85,26 @@ func resolveCheckRegistry(
 
 	return localDir, cleanup, nil
 }
+
+// resolveCheckBase resolves the --registry-dir registry at the commit the
+// project was last synced at, so upstream changes are told apart from the
+// result of earlier merges. It returns an empty directory if there is no
+// registry or commit to resolve.
+func resolveCheckBase(ctx context.Context, logger *slog.Logger) (string, func()) {
+	if checkRegistryDir == "" {
+		return "", func() {}
+	}
+
+	lock, err := lockfile.Read(filepath.Join(".", lockfile.FileName))
+	if err != nil {
+		// check.Run reports the unreadable lockfile.
+		return "", func() {}
+	}
+
+	dir, cleanup, err := resolveBaseRegistry(ctx, logger, checkRegistryDir, lock.Blueprint.Commit)
+	if err != nil {
+		logger.Warn("could not fetch base registry, comparing upstream files against the lockfile", "error", err)
+	}
+
+	return dir, cleanup
+}
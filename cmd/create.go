@@ -4,26 +4,39 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/donaldgifford/forge/internal/config"
 	"github.com/donaldgifford/forge/internal/create"
 	"github.com/donaldgifford/forge/internal/getter"
+	"github.com/donaldgifford/forge/internal/hooks"
+	"github.com/donaldgifford/forge/internal/prompt"
 	"github.com/donaldgifford/forge/internal/registry"
 	"github.com/donaldgifford/forge/internal/ui"
 )
 
 var (
 	setVars     []string
+	valuesFiles []string
 	outputDir   string
 	useDefault  bool
 	noHooks     bool
 	registryDir string
 	forceCreate bool
+	dryRun      bool
+	createSeed  string
+	strictMode  bool
+
+	createOutputFormat string
 )
 
 var createCmd = &cobra.Command{
@@ -33,68 +46,109 @@ var createCmd = &cobra.Command{
 specified as a short name (e.g., "go/api"), a pinned reference (e.g.,
 "go/api@v1.0.0"), or a full go-getter URL.
 
+A standalone blueprint — a repository or directory with blueprint.yaml at
+its root — is specified by its git URL (e.g.,
+"https://github.com/someone/blueprint.git?ref=v1.0.0", "file:///srv/bp.git")
+or a local path starting with "/", "./" or "../".
+
 Use --registry-dir to specify a local directory or remote go-getter URL
-as the blueprint registry source.`,
+as the blueprint registry source.
+
+Use --dry-run to print the files that would be created, their source layer,
+and the files excluded by conditions, without writing anything or running
+hooks. Combine with --output json for machine-readable output.
+
+Use --strict to fail on references to undefined variables in templates
+(as if the blueprint set strict: true); every such reference is reported
+with its file, line and column.
+
+Use --seed to make uuidv4 in templates reproducible. When SOURCE_DATE_EPOCH
+is set, the now template function reports that time instead of the clock.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runCreate,
 }
 
 func init() {
 	createCmd.Flags().StringArrayVar(&setVars, "set", nil, "set a variable value (key=value, can be repeated)")
+	createCmd.Flags().StringArrayVar(&valuesFiles, "values", nil,
+		"YAML or JSON file of variable values (can be repeated; later files and --set take precedence)")
 	createCmd.Flags().StringVarP(&outputDir, "output-dir", "o", "", "target output directory")
 	createCmd.Flags().StringVar(&registryDir, "registry-dir", "", "path or URL to the blueprint registry")
 	createCmd.Flags().BoolVar(&useDefault, "defaults", false, "use all default values without prompting")
 	createCmd.Flags().BoolVar(&noHooks, "no-hooks", false, "skip post-create hooks")
 	createCmd.Flags().BoolVar(&forceCreate, "force", false, "overwrite existing non-empty output directory")
+	createCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the files that would be created without writing anything")
+	createCmd.Flags().BoolVar(&strictMode, "strict", false, "fail on undefined variables in templates")
+	createCmd.Flags().StringVar(&createSeed, "seed", "", "seed for reproducible uuidv4 values in templates")
+	// -o is already --output-dir, so the plan format has no shorthand.
+	createCmd.Flags().StringVar(&createOutputFormat, "output", "text", "dry-run output format (text, json)")
 	rootCmd.AddCommand(createCmd)
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
-	overrides := parseOverrides(setVars)
+	overrides, err := parseOverrides(setVars)
+	if err != nil {
+		return err
+	}
+
+	values, err := loadValuesFiles(valuesFiles)
+	if err != nil {
+		return err
+	}
+
 	logger := slog.Default()
 	blueprintRef := args[0]
 
-	var (
-		resolvedDir string
-		regURL      string
-		cleanup     func()
-		defaultURL  string
-		err         error
-	)
-
-	if registryDir != "" {
-		// Explicit --registry-dir: resolve as local path or go-getter URL.
-		resolvedDir, regURL, cleanup, err = resolveRegistrySource(
-			cmd.Context(), logger, registryDir,
-		)
-		if err != nil {
-			return err
-		}
-	} else {
-		// No --registry-dir: resolve from global config or full URL in blueprint ref.
-		resolvedDir, regURL, defaultURL, cleanup, err = resolveFromConfig(
-			cmd.Context(), logger, blueprintRef,
-		)
-		if err != nil {
-			return err
-		}
+	resolvedDir, regURL, defaultURL, cleanup, err := resolveBlueprintRegistry(cmd.Context(), logger, registryDir, blueprintRef)
+	if err != nil {
+		return err
 	}
 
 	if cleanup != nil {
 		defer cleanup()
 	}
 
+	resolvedDir, ref, versionCleanup, err := checkoutVersion(cmd.Context(), logger, resolvedDir, blueprintRef)
+	if err != nil {
+		return err
+	}
+
+	if versionCleanup != nil {
+		defer versionCleanup()
+	}
+
+	// Cancel on Ctrl-C so an interrupted create leaves the output directory untouched.
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	now, err := sourceDateEpoch()
+	if err != nil {
+		return err
+	}
+
+	w := ui.NewWriter(noColor)
+
 	opts := &create.Opts{
+		Context:            ctx,
 		BlueprintRef:       blueprintRef,
 		OutputDir:          outputDir,
 		RegistryDir:        resolvedDir,
 		RegistryURL:        regURL,
 		DefaultRegistryURL: defaultURL,
+		Ref:                ref,
 		Overrides:          overrides,
+		Values:             values,
 		UseDefaults:        useDefault,
 		NoHooks:            noHooks,
 		ForceCreate:        forceCreate,
+		DryRun:             dryRun,
+		Seed:               createSeed,
+		Strict:             strictMode,
+		Now:                now,
 		ForgeVersion:       buildVersion,
+		PromptFn:           interactivePrompt(ctx, useDefault),
+		Stdout:             w.Out(),
+		Stderr:             w.ErrOut(),
 		Logger:             logger,
 	}
 
@@ -103,12 +157,182 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	w := ui.NewWriter(noColor)
+	if result.Plan != nil {
+		return create.RenderPlan(w.Out(), createOutputFormat, result.Plan)
+	}
+
 	w.Successf("Created project %q in %s (%d files)", result.Blueprint, result.OutputDir, result.FilesCreated)
+	printHookSummary(w, result.Hooks)
 
 	return nil
 }
 
+// sourceDateEpoch returns the time in SOURCE_DATE_EPOCH (Unix seconds), the
+// reproducible-builds convention for pinning timestamps, or the zero time
+// when it is unset.
+func sourceDateEpoch() (time.Time, error) {
+	raw := os.Getenv("SOURCE_DATE_EPOCH")
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	secs, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", raw, err)
+	}
+
+	return time.Unix(secs, 0).UTC(), nil
+}
+
+// interactivePrompt returns a terminal prompter when stdin is an interactive
+// terminal. It returns nil (non-interactive: defaults and --set only) when
+// --defaults is set or stdin is not a TTY, e.g. in CI or when piped.
+// Prompts return, with the terminal restored, when ctx is cancelled.
+func interactivePrompt(ctx context.Context, useDefaults bool) prompt.PromptFn {
+	if useDefaults || !prompt.IsTerminal(os.Stdin) {
+		return nil
+	}
+
+	return prompt.NewTerminal(os.Stdin, os.Stderr).PromptContext(ctx)
+}
+
+// printHookSummary reports the outcome of each post-create hook.
+func printHookSummary(w *ui.Writer, results []hooks.Result) {
+	failed := 0
+
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			w.Warningf("hook failed: %s (%v)", r.Command, r.Err)
+
+			continue
+		}
+
+		w.Successf("hook: %s", r.Command)
+	}
+
+	if failed > 0 {
+		w.Warningf("%d of %d post-create hook(s) failed; project files were kept", failed, len(results))
+	}
+}
+
+// resolveBlueprintRegistry resolves the registry holding blueprintRef: the
+// repository or directory of a standalone blueprint, else the --registry-dir
+// value flagDir if set, else the default registry from global config or the
+// full URL in the reference.
+func resolveBlueprintRegistry(
+	ctx context.Context,
+	logger *slog.Logger,
+	flagDir, blueprintRef string,
+) (localDir, registryURL, defaultRegistryURL string, cleanup func(), err error) {
+	if resolved, resolveErr := registry.Resolve(blueprintRef, ""); resolveErr == nil && resolved.Standalone {
+		localDir, registryURL, cleanup, err = resolveStandalone(ctx, logger, resolved)
+
+		return localDir, registryURL, "", cleanup, err
+	}
+
+	if flagDir != "" {
+		// Explicit --registry-dir: resolve as local path or go-getter URL.
+		localDir, registryURL, cleanup, err = resolveRegistrySource(ctx, logger, flagDir)
+
+		return localDir, registryURL, "", cleanup, err
+	}
+
+	// No --registry-dir: resolve from global config or full URL in blueprint ref.
+	return resolveFromConfig(ctx, logger, blueprintRef)
+}
+
+// resolveStandalone fetches a standalone blueprint (see registry.Resolve)
+// at its ref. A local directory is used in place. The registry URL returned
+// for the lockfile is the go-getter URL without the ref.
+func resolveStandalone(
+	ctx context.Context,
+	logger *slog.Logger,
+	resolved *registry.ResolvedBlueprint,
+) (localDir, registryURL string, cleanup func(), err error) {
+	if info, statErr := os.Stat(resolved.RegistryURL); statErr == nil && info.IsDir() {
+		if resolved.Ref == "" {
+			return resolveRegistrySource(ctx, logger, resolved.RegistryURL)
+		}
+
+		abs, absErr := filepath.Abs(resolved.RegistryURL)
+		if absErr != nil {
+			return "", "", nil, fmt.Errorf("resolving blueprint path: %w", absErr)
+		}
+
+		localDir, cleanup, err = registry.Checkout(ctx, abs, resolved.Ref)
+
+		return localDir, abs, cleanup, err
+	}
+
+	unpinned := *resolved
+	unpinned.Ref = ""
+
+	localDir, _, cleanup, err = resolveRegistrySource(ctx, logger, resolved.GetterURL())
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return localDir, unpinned.GetterURL(), cleanup, nil
+}
+
+// checkoutVersion checks out the release of the registry at dir that the
+// version constraint in blueprintRef selects (e.g., "go/api@^2.0"), or the
+// literal git ref it pins (e.g., "go/api@v2.1.0"), which the lockfile
+// records and sync checks out. It returns dir itself for references
+// without a ref; ref is only set for a constraint.
+func checkoutVersion(
+	ctx context.Context,
+	logger *slog.Logger,
+	dir, blueprintRef string,
+) (versionDir, ref string, cleanup func(), err error) {
+	resolved, err := registry.Resolve(blueprintRef, dir)
+	if err != nil || resolved.Standalone || resolved.Ref == "" {
+		return dir, "", nil, nil
+	}
+
+	if registry.IsConstraint(resolved.Ref) {
+		return checkoutConstraint(ctx, logger, dir, resolved.BlueprintPath, resolved.Ref)
+	}
+
+	logger.Info("checking out registry", "ref", resolved.Ref)
+
+	versionDir, cleanup, err = registry.Checkout(ctx, dir, resolved.Ref)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return versionDir, "", cleanup, nil
+}
+
+// checkoutConstraint checks out the highest release of the blueprint at
+// blueprintPath in the registry at dir that satisfies constraint, unless
+// dir already holds it. It returns the registry directory to use and the
+// ref of the release.
+func checkoutConstraint(
+	ctx context.Context,
+	logger *slog.Logger,
+	dir, blueprintPath, constraint string,
+) (versionDir, ref string, cleanup func(), err error) {
+	v, err := registry.ResolveVersion(dir, blueprintPath, constraint)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	logger.Info("resolved version", "blueprint", blueprintPath, "constraint", constraint, "version", v.Version, "ref", v.Ref)
+
+	if v.Ref == "" || v.Ref == registry.ResolveCommit(dir, blueprintPath) {
+		return dir, v.Ref, nil, nil
+	}
+
+	versionDir, cleanup, err = registry.Checkout(ctx, dir, v.Ref)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return versionDir, v.Ref, cleanup, nil
+}
+
 // resolveFromConfig resolves a registry from global config when --registry-dir
 // is not provided. For full go-getter URLs (containing "//"), it fetches the
 // registry directly. For short names, it looks up the default registry from
@@ -205,15 +429,35 @@ func resolveRegistrySource(
 }
 
 // parseOverrides converts --set key=value strings to a map.
-func parseOverrides(setFlags []string) map[string]string {
+// Entries without "=" or with an empty key are rejected.
+func parseOverrides(setFlags []string) (map[string]string, error) {
 	overrides := make(map[string]string, len(setFlags))
 
 	for _, s := range setFlags {
 		key, value, found := strings.Cut(s, "=")
-		if found {
-			overrides[key] = value
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --set %q: expected key=value", s)
 		}
+
+		overrides[key] = value
+	}
+
+	return overrides, nil
+}
+
+// loadValuesFiles reads --values files in order. Keys in later files replace
+// the same keys from earlier files.
+func loadValuesFiles(paths []string) (map[string]any, error) {
+	values := make(map[string]any)
+
+	for _, path := range paths {
+		fileValues, err := config.LoadValues(path)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(values, fileValues)
 	}
 
-	return overrides
+	return values, nil
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/donaldgifford/forge/internal/lint"
+)
+
+var (
+	lintOutputFormat string
+	lintRegistryDir  string
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [blueprint...]",
+	Short: "Statically check blueprint templates",
+	Long: `Parse every template file, path template, condition, rename rule and
+variable expression of a blueprint without rendering it, and report:
+
+  - references to variables the blueprint does not declare
+  - declared variables that nothing uses (warning)
+  - calls to unknown template functions
+  - template syntax errors
+
+Blueprints are given as paths relative to --registry-dir (e.g., go/api).
+Without arguments, every blueprint in registry.yaml is linted. The command
+fails when any error is found, so it can gate CI; use -o json for
+machine-readable output.`,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintOutputFormat, "output", "o", "text", "output format (text, json)")
+	lintCmd.Flags().StringVar(&lintRegistryDir, "registry-dir", ".", "registry root directory")
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(_ *cobra.Command, args []string) error {
+	result, err := lint.Run(&lint.Opts{
+		RegistryDir:  lintRegistryDir,
+		Blueprints:   args,
+		OutputFormat: lintOutputFormat,
+		Writer:       os.Stdout,
+	})
+	if err != nil {
+		return err
+	}
+
+	if n := result.Errors(); n > 0 {
+		return fmt.Errorf("lint found %d error(s)", n)
+	}
+
+	return nil
+}
@@ -11,16 +11,18 @@ import (
 
 	"github.com/donaldgifford/forge/internal/getter"
 	"github.com/donaldgifford/forge/internal/lockfile"
+	"github.com/donaldgifford/forge/internal/registry"
 	forgesync "github.com/donaldgifford/forge/internal/sync"
 	"github.com/donaldgifford/forge/internal/ui"
 )
 
 var (
-	syncDryRun      bool
-	syncForce       bool
-	syncFileFilter  string
-	syncRegistryDir string
-	syncRef         string
+	syncDryRun        bool
+	syncForce         bool
+	syncFileFilter    string
+	syncRegistryDir   string
+	syncRef           string
+	syncConflictStyle string
 )
 
 var syncCmd = &cobra.Command{
@@ -31,7 +33,17 @@ blueprint and registry. Defaults use overwrite strategy; managed files use
 overwrite or three-way merge depending on their configuration.
 
 Use --registry-dir to override the registry source from the lockfile.
-Use --ref to sync against a specific registry version.`,
+Use --ref to sync against a specific registry version: a tag, branch or
+commit pins the project to it, and a version range (e.g. "^2.0") moves the
+project to the latest release within it. Without --ref, sync uses the ref
+or range recorded in the lockfile. Local registries are checked out at the
+ref from their git repository.
+
+Merged files use the registry at the commit recorded in the lockfile as the
+common ancestor, fetched like the registry or, for a local registry, checked
+out from its git repository. Without it, merged files are overwritten.
+Where local and upstream changes conflict, the file is written with conflict
+markers; --conflict-style diff3 also shows the base's lines between them.`,
 	RunE: runSync,
 }
 
@@ -40,7 +52,9 @@ func init() {
 	syncCmd.Flags().BoolVarP(&syncForce, "force", "f", false, "skip confirmation prompts")
 	syncCmd.Flags().StringVar(&syncFileFilter, "file", "", "sync only a specific file path")
 	syncCmd.Flags().StringVar(&syncRegistryDir, "registry-dir", "", "override registry source (local path or go-getter URL)")
-	syncCmd.Flags().StringVar(&syncRef, "ref", "", "sync against a specific registry version/ref")
+	syncCmd.Flags().StringVar(&syncRef, "ref", "", "sync against a specific registry version/ref or version range")
+	syncCmd.Flags().StringVar(&syncConflictStyle, "conflict-style", string(forgesync.ConflictStyleMerge),
+		"how to write merge conflicts (merge, diff3)")
 	rootCmd.AddCommand(syncCmd)
 }
 
@@ -59,37 +73,42 @@ func runSync(cmd *cobra.Command, _ []string) error {
 	ctx := cmd.Context()
 
 	// Determine registry source and ref.
-	regSource, ref := resolveSyncSource(lock)
+	regSource, ref, constraint := resolveSyncSource(lock)
 
 	// Output which ref is being used.
-	if ref != "" {
+	switch {
+	case constraint != "":
+		w.Infof("syncing against the latest release within %q", constraint)
+	case ref != "":
 		w.Infof("syncing against ref %q", ref)
-	} else {
+	default:
 		w.Info("syncing against latest")
 	}
 
-	// Resolve registry directory (local path or remote fetch).
-	registryDir, regCleanup, err := resolveSyncRegistry(ctx, logger, regSource)
+	// Resolve registry directory (local path or remote fetch) at the ref.
+	registryDir, ref, regCleanup, err := resolveSyncVersion(ctx, logger, regSource, lock.Blueprint.Path, ref, constraint)
 	if err != nil {
 		return fmt.Errorf("resolving registry: %w", err)
 	}
 
-	if regCleanup != nil {
-		defer regCleanup()
+	defer regCleanup()
+
+	// Blueprints added from other registries are synced from those.
+	appliedDirs, appliedCleanup, err := resolveAppliedRegistries(ctx, logger, lock)
+	if err != nil {
+		return fmt.Errorf("resolving registry: %w", err)
 	}
 
-	// Fetch base registry content for three-way merge support.
-	var baseDir string
+	defer appliedCleanup()
 
-	if lock.Blueprint.Commit != "" {
-		baseDir, err = fetchRegistry(ctx, logger, regSource, lock.Blueprint.Commit)
-		if err != nil {
-			logger.Warn("could not fetch base registry for merge, falling back to overwrite", "error", err)
-		} else {
-			defer cleanupDir(logger, baseDir)
-		}
+	// Fetch base registry content for three-way merge support.
+	baseDir, baseCleanup, err := resolveBaseRegistry(ctx, logger, regSource, lock.Blueprint.Commit)
+	if err != nil {
+		logger.Warn("could not fetch base registry for merge, falling back to overwrite", "error", err)
 	}
 
+	defer baseCleanup()
+
 	opts := &forgesync.Opts{
 		ProjectDir:  projectDir,
 		RegistryDir: registryDir,
@@ -97,6 +116,12 @@ func runSync(cmd *cobra.Command, _ []string) error {
 		DryRun:      syncDryRun,
 		Force:       syncForce,
 		FileFilter:  syncFileFilter,
+		Ref:         ref,
+		Constraint:  constraint,
+		Commit:      registry.ResolveCommit(registryDir, lock.Blueprint.Path),
+
+		ConflictStyle: forgesync.ConflictStyle(syncConflictStyle),
+		RegistryDirs:  appliedDirs,
 	}
 
 	result, err := forgesync.Run(opts)
@@ -114,41 +139,183 @@ func runSync(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
-// resolveSyncSource determines the registry source URL and ref for syncing.
-// --registry-dir overrides the lockfile's registry_url.
-// --ref overrides the lockfile's blueprint ref.
-func resolveSyncSource(lock *lockfile.Lockfile) (source, ref string) {
+// resolveSyncSource determines the registry source URL and the ref or
+// version range for syncing. --registry-dir overrides the lockfile's
+// registry_url. --ref overrides the lockfile's blueprint ref and range: a
+// version range (e.g. "^2.0") replaces the range, any other ref pins the
+// project to it.
+func resolveSyncSource(lock *lockfile.Lockfile) (source, ref, constraint string) {
 	source = lock.Blueprint.RegistryURL
 	if syncRegistryDir != "" {
 		source = syncRegistryDir
 	}
 
-	ref = lock.Blueprint.Ref
+	ref, constraint = lock.Blueprint.Ref, lock.Blueprint.Constraint
+
 	if syncRef != "" {
-		ref = syncRef
+		ref, constraint = syncRef, ""
+
+		if registry.IsConstraint(syncRef) {
+			ref, constraint = "", syncRef
+		}
+	}
+
+	return source, ref, constraint
+}
+
+// resolveSyncVersion resolves the registry source to a local directory
+// holding the version to sync against: the highest release within
+// constraint if set, else ref. It returns the directory, the ref it holds
+// and a function removing any temporary copy.
+func resolveSyncVersion(
+	ctx context.Context,
+	logger *slog.Logger,
+	source, blueprintPath, ref, constraint string,
+) (dir, resolvedRef string, cleanup func(), err error) {
+	// Releases within a range are looked up in the latest registry content.
+	if constraint != "" {
+		ref = ""
 	}
 
-	return source, ref
+	dir, regCleanup, err := resolveSyncRegistry(ctx, logger, source, ref)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	cleanup = func() {
+		if regCleanup != nil {
+			regCleanup()
+		}
+	}
+
+	if constraint == "" {
+		return dir, ref, cleanup, nil
+	}
+
+	versionDir, versionRef, versionCleanup, err := checkoutConstraint(ctx, logger, dir, blueprintPath, constraint)
+	if err != nil {
+		cleanup()
+
+		return "", "", nil, fmt.Errorf("resolving version: %w", err)
+	}
+
+	if versionCleanup != nil {
+		cleanup = func() {
+			versionCleanup()
+
+			if regCleanup != nil {
+				regCleanup()
+			}
+		}
+	}
+
+	return versionDir, versionRef, cleanup, nil
 }
 
-// resolveSyncRegistry resolves a registry source to a local directory.
-// Uses the same logic as create: local paths are used directly, remote
-// go-getter URLs are fetched into a temp directory.
+// resolveSyncRegistry resolves a registry source to a local directory
+// holding the registry at ref (its current content if ref is empty). Uses
+// the same logic as create: local paths are used directly, or checked out
+// at ref if they are git repositories; remote go-getter URLs are fetched
+// into a temp directory.
 func resolveSyncRegistry(
 	ctx context.Context,
 	logger *slog.Logger,
-	source string,
+	source, ref string,
 ) (string, func(), error) {
 	if source == "" {
 		return "", nil, fmt.Errorf("no registry source — set --registry-dir or ensure lockfile has registry_url")
 	}
 
-	localDir, _, cleanup, err := resolveRegistrySource(ctx, logger, source)
+	if ref == "" {
+		localDir, _, cleanup, err := resolveRegistrySource(ctx, logger, source)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return localDir, cleanup, nil
+	}
+
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return "", nil, fmt.Errorf("resolving registry-dir path: %w", err)
+		}
+
+		return registry.Checkout(ctx, abs, ref)
+	}
+
+	logger.Info("fetching registry", "source", source, "ref", ref)
+
+	dir, err := fetchRegistry(ctx, logger, source, ref)
 	if err != nil {
-		return "", nil, err
+		return "", nil, fmt.Errorf("fetching registry from %s at %s: %w", source, ref, err)
+	}
+
+	return dir, func() { cleanupDir(logger, dir) }, nil
+}
+
+// resolveBaseRegistry resolves the registry source at commit, the commit
+// the project was last synced at, as the base of three-way merges: a local
+// registry is checked out at the commit from its git repository, a remote
+// one fetched at it. Without a commit there is no base and the returned
+// directory is empty. The cleanup function is never nil.
+func resolveBaseRegistry(ctx context.Context, logger *slog.Logger, source, commit string) (string, func(), error) {
+	if commit == "" {
+		return "", func() {}, nil
+	}
+
+	dir, cleanup, err := resolveSyncRegistry(ctx, logger, source, commit)
+	if err != nil {
+		return "", func() {}, err
+	}
+
+	return dir, cleanup, nil
+}
+
+// resolveAppliedRegistries resolves the registries of blueprints added to
+// the project (forge add) from a registry other than the project's own,
+// keyed by registry URL. --registry-dir overrides them all, so none are
+// resolved when it is set.
+func resolveAppliedRegistries(
+	ctx context.Context,
+	logger *slog.Logger,
+	lock *lockfile.Lockfile,
+) (map[string]string, func(), error) {
+	dirs := make(map[string]string)
+
+	var cleanups []func()
+
+	cleanup := func() {
+		for _, fn := range cleanups {
+			fn()
+		}
+	}
+
+	if syncRegistryDir != "" {
+		return dirs, cleanup, nil
+	}
+
+	for i := range lock.Applied {
+		url := lock.Applied[i].Blueprint.RegistryURL
+		if _, ok := dirs[url]; ok || url == "" || url == lock.Blueprint.RegistryURL {
+			continue
+		}
+
+		dir, fn, err := resolveSyncRegistry(ctx, logger, url, "")
+		if err != nil {
+			cleanup()
+
+			return nil, nil, err
+		}
+
+		if fn != nil {
+			cleanups = append(cleanups, fn)
+		}
+
+		dirs[url] = dir
 	}
 
-	return localDir, cleanup, nil
+	return dirs, cleanup, nil
 }
 
 func fetchRegistry(ctx context.Context, logger *slog.Logger, registryURL, ref string) (string, error) {
@@ -2,6 +2,7 @@
 package check
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"text/tabwriter"
 
+	"github.com/donaldgifford/forge/internal/defaults"
 	"github.com/donaldgifford/forge/internal/lockfile"
 	tmpl "github.com/donaldgifford/forge/internal/template"
 )
@@ -20,6 +22,12 @@ type Opts struct {
 	// RegistryDir is the local path to the current registry content.
 	// When set, enables three-way comparison (local vs lockfile vs registry).
 	RegistryDir string
+	// BaseDir is the local path to the registry content at the commit the
+	// project was last synced at. When set, a file is upstream-changed if the
+	// registry renders it differently than the base does, rather than
+	// differently from its lockfile hash, which for merged files is the
+	// merge result.
+	BaseDir string
 	// OutputFormat is "text" or "json".
 	OutputFormat string
 	// Writer is the output destination.
@@ -37,6 +45,7 @@ const (
 	StatusModifiedLocally FileStatus = "modified-locally"
 	StatusUpstreamChanged FileStatus = "upstream-changed"
 	StatusBothChanged     FileStatus = "both-changed"
+	StatusModeChanged     FileStatus = "mode-changed"
 )
 
 // FileUpdate describes the drift state of a single file.
@@ -48,8 +57,9 @@ type FileUpdate struct {
 
 // Result holds the check comparison results.
 type Result struct {
-	DefaultsUpdates []FileUpdate `json:"defaults_updates"`
-	ManagedUpdates  []FileUpdate `json:"managed_updates"`
+	DefaultsUpdates  []FileUpdate `json:"defaults_updates"`
+	ManagedUpdates   []FileUpdate `json:"managed_updates"`
+	GeneratedUpdates []FileUpdate `json:"generated_updates,omitempty"`
 }
 
 // Run executes the check workflow.
@@ -66,9 +76,35 @@ func Run(opts *Opts) (*Result, error) {
 		return nil, fmt.Errorf("reading lockfile: %w (is this a forge project?)", err)
 	}
 
-	renderer := tmpl.NewRenderer()
 	result := &Result{}
 
+	for _, applied := range lock.Blueprints() {
+		// The base holds the project's registry at its commit, so it is only
+		// the base of blueprints last synced at that commit.
+		baseDir := opts.BaseDir
+		if applied.Blueprint.Commit != lock.Blueprint.Commit || applied.Blueprint.RegistryURL != lock.Blueprint.RegistryURL {
+			baseDir = ""
+		}
+
+		if err := checkBlueprint(opts.RegistryDir, baseDir, projectDir, applied, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, renderResult(opts.Writer, opts.OutputFormat, result)
+}
+
+// checkBlueprint checks the files tracked for one blueprint applied to the
+// project.
+func checkBlueprint(registryDir, baseDir, projectDir string, lock *lockfile.Lockfile, result *Result) error {
+	partials, err := defaults.ResolvePartials(registryDir, lock.Sources()...)
+	if err != nil {
+		return fmt.Errorf("resolving partials: %w", err)
+	}
+
+	renderer := tmpl.NewRenderer().WithFuncOptions(tmpl.FuncOptions{Seed: lock.Seed}).WithPartials(partials)
+	vars := lock.TemplateVars()
+
 	// Check defaults.
 	for i := range lock.Defaults {
 		d := &lock.Defaults[i]
@@ -76,8 +112,10 @@ func Run(opts *Opts) (*Result, error) {
 		renderedPath := tmpl.StripTemplateExtension(d.Path)
 		localPath := filepath.Join(projectDir, renderedPath)
 
-		registryHash := resolveRegistryHash(opts.RegistryDir, d.Path, lock.Variables, renderer)
-		update := checkFile(localPath, renderedPath, d.Source, d.Hash, registryHash)
+		fileRenderer := renderer.WithDelimiterPair(d.Delimiters).WithSeedKey(d.Path)
+		registryHash := resolveRegistryHash(registryDir, d.Path, d.Verbatim, vars, fileRenderer)
+		baseHash := resolveRegistryHash(baseDir, d.Path, d.Verbatim, vars, fileRenderer)
+		update := checkFile(localPath, renderedPath, d.Source, d.Hash, d.Mode, registryHash, baseHash)
 		result.DefaultsUpdates = append(result.DefaultsUpdates, update)
 	}
 
@@ -86,25 +124,52 @@ func Run(opts *Opts) (*Result, error) {
 		mf := &lock.ManagedFiles[i]
 		localPath := filepath.Join(projectDir, mf.Path)
 
-		registryHash := resolveRegistryHashForManaged(
-			opts.RegistryDir, lock.Blueprint.Path, mf.Path, lock.Variables, renderer,
-		)
-		update := checkFile(localPath, mf.Path, mf.Strategy, mf.Hash, registryHash)
+		bpPath, fileRenderer := cmp.Or(mf.Blueprint, lock.Blueprint.Path), renderer.WithDelimiterPair(mf.Delimiters).WithSeedKey(mf.Path)
+		registryHash := resolveRegistryHashForManaged(registryDir, bpPath, mf.Path, mf.Verbatim, vars, fileRenderer)
+		baseHash := resolveRegistryHashForManaged(baseDir, bpPath, mf.Path, mf.Verbatim, vars, fileRenderer)
+		update := checkFile(localPath, mf.Path, mf.Strategy, mf.Hash, mf.Mode, registryHash, baseHash)
 		result.ManagedUpdates = append(result.ManagedUpdates, update)
 	}
 
-	return result, renderResult(opts.Writer, opts.OutputFormat, result)
+	// Check generated files, rendering their source with the item in scope.
+	for i := range lock.Generated {
+		g := &lock.Generated[i]
+		localPath := filepath.Join(projectDir, g.Path)
+
+		bpPath, fileRenderer := cmp.Or(g.Blueprint, lock.Blueprint.Path), renderer.WithDelimiterPair(g.Delimiters).WithSeedKey(g.Path)
+		registryHash := resolveRegistryHashForManaged(registryDir, bpPath, g.Template, false, g.Vars(lock), fileRenderer)
+		baseHash := resolveRegistryHashForManaged(baseDir, bpPath, g.Template, false, g.Vars(lock), fileRenderer)
+		update := checkFile(localPath, g.Path, g.Template, g.Hash, g.Mode, registryHash, baseHash)
+		result.GeneratedUpdates = append(result.GeneratedUpdates, update)
+	}
+
+	return nil
 }
 
 // checkFile determines the drift status of a file.
-// lockfileHash is the hash stored at create/sync time.
-// registryHash is the hash of the current registry source (empty if no registry).
-func checkFile(localPath, relPath, source, lockfileHash, registryHash string) FileUpdate {
+// lockfileHash and lockfileMode are the hash and permission bits stored at create/sync time.
+// registryHash is the hash of the current registry source (empty if no registry) and
+// baseHash that of the registry source at the project's commit (empty if unknown).
+func checkFile(localPath, relPath, source, lockfileHash, lockfileMode, registryHash, baseHash string) FileUpdate {
 	content, err := os.ReadFile(filepath.Clean(localPath))
 	if err != nil {
 		return FileUpdate{Path: relPath, Status: StatusMissing, Source: source}
 	}
 
+	update := checkContent(content, relPath, source, lockfileHash, registryHash, baseHash)
+
+	// Content drift takes precedence; otherwise report permission changes.
+	if update.Status == StatusUpToDate && lockfileMode != "" {
+		if mode := lockfile.FileMode(localPath); mode != lockfileMode {
+			update.Status = StatusModeChanged
+		}
+	}
+
+	return update
+}
+
+// checkContent determines the content drift status of a file.
+func checkContent(content []byte, relPath, source, lockfileHash, registryHash, baseHash string) FileUpdate {
 	// If no hash stored in lockfile, existence is sufficient.
 	if lockfileHash == "" {
 		return FileUpdate{Path: relPath, Status: StatusUpToDate, Source: source}
@@ -122,8 +187,10 @@ func checkFile(localPath, relPath, source, lockfileHash, registryHash string) Fi
 		return FileUpdate{Path: relPath, Status: StatusUpToDate, Source: source}
 	}
 
-	// Three-way comparison: local vs lockfile vs registry.
-	upstreamChanged := registryHash != lockfileHash
+	// Three-way comparison: local vs lockfile vs registry, where the
+	// registry changed if it differs from the base or, without one, from
+	// what was last written.
+	upstreamChanged := registryHash != cmp.Or(baseHash, lockfileHash)
 
 	switch {
 	case localChanged && upstreamChanged:
@@ -141,6 +208,7 @@ func checkFile(localPath, relPath, source, lockfileHash, registryHash string) Fi
 // Returns empty string if registry dir is not set or the file cannot be resolved.
 func resolveRegistryHash(
 	registryDir, relPath string,
+	verbatim bool,
 	vars map[string]any,
 	renderer *tmpl.Renderer,
 ) string {
@@ -148,12 +216,12 @@ func resolveRegistryHash(
 		return ""
 	}
 
-	sourcePath := findSourceFile(registryDir, relPath)
+	sourcePath := defaults.FindSource(registryDir, relPath)
 	if sourcePath == "" {
 		return ""
 	}
 
-	content, err := readSourceContent(sourcePath, vars, renderer)
+	content, err := renderer.ReadSource(sourcePath, verbatim, vars)
 	if err != nil {
 		return ""
 	}
@@ -164,6 +232,7 @@ func resolveRegistryHash(
 // resolveRegistryHashForManaged computes the content hash of a managed file from the registry.
 func resolveRegistryHashForManaged(
 	registryDir, blueprintPath, relPath string,
+	verbatim bool,
 	vars map[string]any,
 	renderer *tmpl.Renderer,
 ) string {
@@ -171,17 +240,17 @@ func resolveRegistryHashForManaged(
 		return ""
 	}
 
-	sourcePath := findSourceFile(registryDir, relPath)
+	sourcePath := defaults.FindSource(registryDir, relPath)
 	if sourcePath == "" {
 		// Check in blueprint directory.
-		sourcePath = findBlueprintFile(registryDir, blueprintPath, relPath)
+		sourcePath = defaults.FindBlueprintFile(registryDir, blueprintPath, relPath)
 	}
 
 	if sourcePath == "" {
 		return ""
 	}
 
-	content, err := readSourceContent(sourcePath, vars, renderer)
+	content, err := renderer.ReadSource(sourcePath, verbatim, vars)
 	if err != nil {
 		return ""
 	}
@@ -189,40 +258,6 @@ func resolveRegistryHashForManaged(
 	return lockfile.ContentHash(content)
 }
 
-// findSourceFile looks for a file in known registry locations.
-func findSourceFile(registryDir, relPath string) string {
-	candidate := filepath.Join(registryDir, relPath)
-	if _, err := os.Stat(candidate); err == nil {
-		return candidate
-	}
-
-	candidate = filepath.Join(registryDir, "_defaults", relPath)
-	if _, err := os.Stat(candidate); err == nil {
-		return candidate
-	}
-
-	return ""
-}
-
-// findBlueprintFile looks for a file in the blueprint directory.
-func findBlueprintFile(registryDir, blueprintPath, relPath string) string {
-	candidate := filepath.Join(registryDir, blueprintPath, relPath)
-	if _, err := os.Stat(candidate); err == nil {
-		return candidate
-	}
-
-	return ""
-}
-
-// readSourceContent reads a source file, rendering templates if needed.
-func readSourceContent(sourcePath string, vars map[string]any, renderer *tmpl.Renderer) ([]byte, error) {
-	if tmpl.IsTemplate(sourcePath) {
-		return renderer.RenderFile(sourcePath, vars)
-	}
-
-	return os.ReadFile(filepath.Clean(sourcePath))
-}
-
 func renderResult(w io.Writer, format string, result *Result) error {
 	switch format {
 	case "json":
@@ -256,6 +291,13 @@ func renderText(w io.Writer, result *Result) error {
 		}
 	}
 
+	for i := range result.GeneratedUpdates {
+		u := &result.GeneratedUpdates[i]
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", u.Path, statusIcon(u.Status), u.Source); err != nil {
+			return err
+		}
+	}
+
 	return tw.Flush()
 }
 
@@ -271,6 +313,8 @@ func statusIcon(s FileStatus) string {
 		return "upstream-changed"
 	case StatusBothChanged:
 		return "both-changed"
+	case StatusModeChanged:
+		return "mode-changed"
 	default:
 		return string(s)
 	}
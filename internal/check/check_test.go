@@ -3,6 +3,7 @@ package check_test
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -376,6 +377,42 @@ func TestRun_RegistryComparison_BothChanged(t *testing.T) {
 	assert.Equal(t, check.StatusBothChanged, jsonResult.DefaultsUpdates[0].Status)
 }
 
+func TestRun_RegistryComparison_BaseDir(t *testing.T) {
+	t.Parallel()
+
+	projectDir, registryDir := setupProjectWithRegistry(t)
+
+	// The project merged a local edit into the registry's file at its
+	// commit, so the lockfile hash is that of the merge result.
+	merged := []byte("root = true\n# local")
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".editorconfig"), merged, 0o644))
+
+	lock, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	lock.Defaults[0].Hash = lockfile.ContentHash(merged)
+	require.NoError(t, lockfile.Write(filepath.Join(projectDir, lockfile.FileName), lock))
+
+	// The base is the registry at that commit.
+	baseDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(baseDir, "_defaults"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "_defaults", ".editorconfig"), []byte("root = true"), 0o644))
+
+	result, err := check.Run(&check.Opts{ProjectDir: projectDir, RegistryDir: registryDir, Writer: io.Discard})
+	require.NoError(t, err)
+	assert.Equal(t, check.StatusUpstreamChanged, result.DefaultsUpdates[0].Status, "without a base the merge reads as upstream drift")
+
+	result, err = check.Run(&check.Opts{ProjectDir: projectDir, RegistryDir: registryDir, BaseDir: baseDir, Writer: io.Discard})
+	require.NoError(t, err)
+	assert.Equal(t, check.StatusUpToDate, result.DefaultsUpdates[0].Status)
+
+	// An upstream change since the base is reported.
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "_defaults", ".editorconfig"), []byte("root = false"), 0o644))
+
+	result, err = check.Run(&check.Opts{ProjectDir: projectDir, RegistryDir: registryDir, BaseDir: baseDir, Writer: io.Discard})
+	require.NoError(t, err)
+	assert.Equal(t, check.StatusUpstreamChanged, result.DefaultsUpdates[0].Status)
+}
+
 func TestRun_RegistryComparison_ManagedFile_UpstreamChanged(t *testing.T) {
 	t.Parallel()
 
@@ -423,3 +460,57 @@ func TestRun_TextOutput(t *testing.T) {
 	assert.Contains(t, output, ".editorconfig")
 	assert.Contains(t, output, "ok")
 }
+
+func TestRun_ModeChanged(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	content := []byte("#!/bin/sh\n")
+
+	lock := &lockfile.Lockfile{
+		Defaults: []lockfile.DefaultEntry{
+			{Path: "lint.sh", Source: "registry-default", Strategy: "overwrite", Hash: lockfile.ContentHash(content), Mode: "0755"},
+		},
+	}
+
+	require.NoError(t, lockfile.Write(filepath.Join(dir, lockfile.FileName), lock))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lint.sh"), content, 0o644))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "lint.sh"), 0o644))
+
+	var buf bytes.Buffer
+
+	result, err := check.Run(&check.Opts{ProjectDir: dir, OutputFormat: "text", Writer: &buf})
+	require.NoError(t, err)
+
+	require.Len(t, result.DefaultsUpdates, 1)
+	assert.Equal(t, check.StatusModeChanged, result.DefaultsUpdates[0].Status)
+	assert.Contains(t, buf.String(), "mode-changed")
+}
+
+func TestRun_AppliedBlueprints(t *testing.T) {
+	t.Parallel()
+
+	dir := setupProject(t)
+
+	lock, err := lockfile.Read(filepath.Join(dir, lockfile.FileName))
+	require.NoError(t, err)
+
+	lock.Applied = []lockfile.Lockfile{{
+		Blueprint: lockfile.BlueprintRef{Name: "github", Path: "ci/github"},
+		ManagedFiles: []lockfile.ManagedFileEntry{
+			{Path: "ci.yml", Strategy: "overwrite", Hash: lockfile.ContentHash([]byte("on: push\n"))},
+		},
+	}}
+	require.NoError(t, lockfile.Write(filepath.Join(dir, lockfile.FileName), lock))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on: [push]\n"), 0o644))
+
+	var buf bytes.Buffer
+
+	result, err := check.Run(&check.Opts{ProjectDir: dir, OutputFormat: "text", Writer: &buf})
+	require.NoError(t, err)
+
+	require.Len(t, result.ManagedUpdates, 2)
+	assert.Equal(t, check.StatusUpToDate, result.ManagedUpdates[0].Status)
+	assert.Equal(t, "ci.yml", result.ManagedUpdates[1].Path)
+	assert.Equal(t, check.StatusModified, result.ManagedUpdates[1].Status)
+}
@@ -0,0 +1,208 @@
+// Package compose loads blueprints that build on other blueprints through
+// extends and includes, merging their configuration into one blueprint.
+package compose
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/donaldgifford/forge/internal/config"
+)
+
+// Result is a blueprint merged with every blueprint it extends or includes.
+type Result struct {
+	// Blueprint is the merged configuration.
+	Blueprint *config.Blueprint
+
+	// Sources lists the path of every contributing blueprint, lowest
+	// precedence first. The last entry is the blueprint that was loaded.
+	Sources []string
+}
+
+// Contributors returns the paths of the extended and included blueprints,
+// i.e. Sources without the blueprint itself.
+func (r *Result) Contributors() []string {
+	return r.Sources[:len(r.Sources)-1]
+}
+
+// loader walks the extends/includes graph depth first.
+type loader struct {
+	root string
+
+	// stack holds the blueprints being visited, for cycle detection.
+	stack []string
+
+	sources    []string
+	blueprints []*config.Blueprint
+}
+
+// Load reads the blueprint at bpPath (relative to the registry root) and
+// every blueprint it extends or includes, transitively, and merges them.
+//
+// Contributors are ordered by precedence, lowest first: the extended
+// blueprint, then each include in order, then the blueprint itself. A
+// blueprint reached more than once contributes at its first position.
+// A blueprint that composes nothing is loaded as by config.LoadBlueprint.
+func Load(root, bpPath string) (*Result, error) {
+	l := &loader{root: root}
+
+	if err := l.visit(bpPath); err != nil {
+		return nil, err
+	}
+
+	bp := l.blueprints[0]
+	if len(l.blueprints) > 1 {
+		bp = merge(l.blueprints, l.sources)
+	}
+
+	if err := config.ValidateBlueprint(bp); err != nil {
+		return nil, fmt.Errorf("validating blueprint %s: %w", l.file(bpPath), err)
+	}
+
+	return &Result{Blueprint: bp, Sources: l.sources}, nil
+}
+
+// visit adds bpPath after the blueprints it extends and includes.
+func (l *loader) visit(bpPath string) error {
+	if i := slices.Index(l.stack, bpPath); i >= 0 {
+		cycle := append(slices.Clone(l.stack[i:]), bpPath)
+
+		return fmt.Errorf("blueprint composition cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	if slices.Contains(l.sources, bpPath) {
+		return nil
+	}
+
+	bp, err := config.ReadBlueprint(l.file(bpPath))
+	if err != nil {
+		return err
+	}
+
+	if err := config.ValidateComposition(bp); err != nil {
+		return fmt.Errorf("validating blueprint %s: %w", l.file(bpPath), err)
+	}
+
+	l.stack = append(l.stack, bpPath)
+
+	var parents []string
+	if bp.Extends != "" {
+		parents = append(parents, bp.Extends)
+	}
+
+	for _, parent := range append(parents, bp.Includes...) {
+		if err := l.visit(parent); err != nil {
+			return err
+		}
+	}
+
+	l.stack = l.stack[:len(l.stack)-1]
+	l.sources = append(l.sources, bpPath)
+	l.blueprints = append(l.blueprints, bp)
+
+	return nil
+}
+
+func (l *loader) file(bpPath string) string {
+	return filepath.Join(l.root, bpPath, "blueprint.yaml")
+}
+
+// merge combines blueprints, ordered lowest precedence first, into one:
+//
+//   - name, description, version, tags, strict, preserve_symlinks,
+//     extends and includes come from the blueprint itself (the last one);
+//   - variables and computed variables are merged by name, a later
+//     definition replacing an earlier one in place;
+//   - managed files are merged by path, later wins;
+//   - rename rules and override strategies are merged by key, later wins;
+//   - conditions, hooks, generate rules, copy_without_render patterns,
+//     sync ignores and default exclusions are concatenated;
+//   - the template delimiters of the highest-precedence blueprint that sets
+//     them apply, and delimiter overrides are checked highest precedence first.
+func merge(blueprints []*config.Blueprint, sources []string) *config.Blueprint {
+	last := len(blueprints) - 1
+	self := blueprints[last]
+
+	merged := &config.Blueprint{
+		APIVersion:       self.APIVersion,
+		Name:             self.Name,
+		Description:      self.Description,
+		Version:          self.Version,
+		Tags:             self.Tags,
+		Extends:          self.Extends,
+		Includes:         self.Includes,
+		PreserveSymlinks: self.PreserveSymlinks,
+		Strict:           self.Strict,
+	}
+
+	for i, bp := range blueprints {
+		merged.Variables = mergeBy(merged.Variables, bp.Variables, func(v config.Variable) string { return v.Name })
+		merged.Computed = mergeBy(merged.Computed, bp.Computed, func(c config.Computed) string { return c.Name })
+
+		origin := ""
+		if i != last {
+			origin = sources[i]
+		}
+
+		for _, mf := range bp.Sync.ManagedFiles {
+			mf.Blueprint = origin
+			merged.Sync.ManagedFiles = mergeBy(merged.Sync.ManagedFiles, []config.ManagedFile{mf},
+				func(mf config.ManagedFile) string { return mf.Path })
+		}
+
+		merged.Rename = mergeMap(merged.Rename, bp.Rename)
+		merged.Defaults.OverrideStrategy = mergeMap(merged.Defaults.OverrideStrategy, bp.Defaults.OverrideStrategy)
+
+		merged.Conditions = append(merged.Conditions, bp.Conditions...)
+		merged.Hooks.PostCreate = append(merged.Hooks.PostCreate, bp.Hooks.PostCreate...)
+		merged.Generate = append(merged.Generate, bp.Generate...)
+		merged.CopyWithoutRender = append(merged.CopyWithoutRender, bp.CopyWithoutRender...)
+		merged.Sync.Ignore = append(merged.Sync.Ignore, bp.Sync.Ignore...)
+		merged.Defaults.Exclude = append(merged.Defaults.Exclude, bp.Defaults.Exclude...)
+
+		if len(bp.Template.Delimiters) > 0 {
+			merged.Template.Delimiters = bp.Template.Delimiters
+		}
+	}
+
+	for i := last; i >= 0; i-- {
+		merged.Template.Overrides = append(merged.Template.Overrides, blueprints[i].Template.Overrides...)
+	}
+
+	return merged
+}
+
+// mergeBy appends the items of add to items, replacing in place any item
+// with the same key.
+func mergeBy[T any](items, add []T, key func(T) string) []T {
+	for _, item := range add {
+		i := slices.IndexFunc(items, func(existing T) bool { return key(existing) == key(item) })
+		if i >= 0 {
+			items[i] = item
+
+			continue
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// mergeMap copies add into m, allocating m if needed.
+func mergeMap(m, add map[string]string) map[string]string {
+	if len(add) == 0 {
+		return m
+	}
+
+	if m == nil {
+		m = make(map[string]string, len(add))
+	}
+
+	maps.Copy(m, add)
+
+	return m
+}
@@ -0,0 +1,176 @@
+package compose_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/compose"
+	"github.com/donaldgifford/forge/internal/config"
+)
+
+func writeBlueprint(t *testing.T, root, bpPath, content string) {
+	t.Helper()
+
+	path := filepath.Join(root, bpPath, "blueprint.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestLoad_MergesWithPrecedence(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeBlueprint(t, root, "go/base", `apiVersion: v1
+name: base
+variables:
+  - name: project_name
+    type: string
+  - name: go_version
+    type: string
+    default: "1.22"
+conditions:
+  - when: "{{ false }}"
+    exclude: [base.txt]
+hooks:
+  post_create: ["go mod tidy"]
+sync:
+  managed_files:
+    - path: Makefile
+      strategy: overwrite
+rename:
+  "a/": "b/"
+template:
+  delimiters: ["[[", "]]"]
+`)
+	writeBlueprint(t, root, "ci/github", `name: github
+variables:
+  - name: ci_os
+    type: string
+    default: ubuntu-latest
+hooks:
+  post_create: ["git init"]
+sync:
+  managed_files:
+    - path: .github/workflows/ci.yml
+      strategy: overwrite
+    - path: Makefile
+      strategy: merge
+`)
+	writeBlueprint(t, root, "go/api", `apiVersion: v1
+name: api
+version: "1.0.0"
+extends: go/base
+includes: [ci/github]
+variables:
+  - name: go_version
+    type: string
+    default: "1.23"
+rename:
+  "a/": "c/"
+`)
+
+	res, err := compose.Load(root, "go/api")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"go/base", "ci/github", "go/api"}, res.Sources)
+	assert.Equal(t, []string{"go/base", "ci/github"}, res.Contributors())
+
+	bp := res.Blueprint
+	assert.Equal(t, "api", bp.Name)
+	assert.Equal(t, "1.0.0", bp.Version)
+
+	names := make([]string, 0, len(bp.Variables))
+	for i := range bp.Variables {
+		names = append(names, bp.Variables[i].Name)
+	}
+
+	assert.Equal(t, []string{"project_name", "go_version", "ci_os"}, names)
+	assert.Equal(t, "1.23", bp.Variables[1].Default)
+
+	assert.Equal(t, []string{"go mod tidy", "git init"}, bp.Hooks.PostCreate)
+	assert.Len(t, bp.Conditions, 1)
+	assert.Equal(t, map[string]string{"a/": "c/"}, bp.Rename)
+	assert.Equal(t, []string{"[[", "]]"}, bp.Template.Delimiters)
+	assert.Equal(t, []config.ManagedFile{
+		{Path: "Makefile", Strategy: "merge", Blueprint: "ci/github"},
+		{Path: ".github/workflows/ci.yml", Strategy: "overwrite", Blueprint: "ci/github"},
+	}, bp.Sync.ManagedFiles)
+}
+
+func TestLoad_SingleBlueprint(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeBlueprint(t, root, "go/api", "apiVersion: v1\nname: api\n")
+
+	res, err := compose.Load(root, "go/api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go/api"}, res.Sources)
+	assert.Empty(t, res.Contributors())
+	assert.Equal(t, "api", res.Blueprint.Name)
+}
+
+func TestLoad_SharedBlueprintContributesOnce(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeBlueprint(t, root, "base", "name: base\n")
+	writeBlueprint(t, root, "ci", "name: ci\nextends: base\n")
+	writeBlueprint(t, root, "app", "apiVersion: v1\nname: app\nextends: base\nincludes: [ci]\n")
+
+	res, err := compose.Load(root, "app")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base", "ci", "app"}, res.Sources)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		blueprints map[string]string
+		wantErr    string
+	}{
+		"cycle": {
+			blueprints: map[string]string{
+				"a": "apiVersion: v1\nname: a\nextends: b\n",
+				"b": "name: b\nincludes: [c]\n",
+				"c": "name: c\nextends: a\n",
+			},
+			wantErr: "blueprint composition cycle: a -> b -> c -> a",
+		},
+		"missing contributor": {
+			blueprints: map[string]string{"a": "apiVersion: v1\nname: a\nextends: nope\n"},
+			wantErr:    "reading blueprint file",
+		},
+		"escaping path": {
+			blueprints: map[string]string{"a": "apiVersion: v1\nname: a\nincludes: [../other]\n"},
+			wantErr:    `includes[0]: invalid blueprint path "../other"`,
+		},
+		"invalid merged blueprint": {
+			blueprints: map[string]string{
+				"a": "apiVersion: v1\nname: a\nextends: b\n",
+				"b": "name: b\nvariables:\n  - name: x\n    type: nope\n",
+			},
+			wantErr: "validating blueprint",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			for bpPath, content := range tt.blueprints {
+				writeBlueprint(t, root, bpPath, content)
+			}
+
+			_, err := compose.Load(root, "a")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -3,17 +3,85 @@ package config
 
 // Blueprint represents the configuration of a single blueprint (blueprint.yaml).
 type Blueprint struct {
-	APIVersion  string            `yaml:"apiVersion"`
-	Name        string            `yaml:"name"`
-	Description string            `yaml:"description"`
-	Version     string            `yaml:"version"`
-	Tags        []string          `yaml:"tags"`
-	Defaults    Defaults          `yaml:"defaults"`
-	Variables   []Variable        `yaml:"variables"`
-	Conditions  []Condition       `yaml:"conditions"`
-	Hooks       Hooks             `yaml:"hooks"`
-	Sync        SyncConfig        `yaml:"sync"`
-	Rename      map[string]string `yaml:"rename"`
+	APIVersion  string   `yaml:"apiVersion"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Version     string   `yaml:"version"`
+	Tags        []string `yaml:"tags"`
+
+	// Extends names a blueprint (by registry path, e.g. "go/base") whose
+	// files and configuration this blueprint builds on.
+	Extends string `yaml:"extends"`
+
+	// Includes names further blueprints (e.g. "ci/github") mixed in after
+	// Extends, in order. See the compose package for precedence.
+	Includes []string `yaml:"includes"`
+
+	Defaults   Defaults          `yaml:"defaults"`
+	Variables  []Variable        `yaml:"variables"`
+	Computed   []Computed        `yaml:"computed"`
+	Conditions []Condition       `yaml:"conditions"`
+	Hooks      Hooks             `yaml:"hooks"`
+	Sync       SyncConfig        `yaml:"sync"`
+	Rename     map[string]string `yaml:"rename"`
+
+	// PreserveSymlinks recreates relative symlinks in the output instead of
+	// copying the content they point to. Links must stay inside the project.
+	PreserveSymlinks bool `yaml:"preserve_symlinks"`
+
+	// CopyWithoutRender lists glob patterns for files that are copied
+	// verbatim even when they end in .tmpl. Binary files are never rendered.
+	CopyWithoutRender []string `yaml:"copy_without_render"`
+
+	// Template controls how template files are rendered.
+	Template TemplateConfig `yaml:"template"`
+
+	// Strict makes references to undefined variables in template files an
+	// error instead of rendering them as empty values.
+	Strict bool `yaml:"strict"`
+
+	// Generate renders templates once per item of a list variable.
+	Generate []Generator `yaml:"generate"`
+}
+
+// Generator renders a template file once for each item of a list or
+// multichoice variable, e.g. one cmd/<name>/main.go per sub-command.
+type Generator struct {
+	// Template is the source template's path relative to the blueprint
+	// directory. It is only rendered by this generator, never on its own.
+	Template string `yaml:"template"`
+
+	// Each names the list or multichoice variable to iterate over.
+	Each string `yaml:"each"`
+
+	// As is the variable the current item is available as in Template and
+	// Path. Defaults to "item".
+	As string `yaml:"as"`
+
+	// Path is the output path, a template rendered for each item
+	// (e.g., "cmd/{{ .command }}/main.go").
+	Path string `yaml:"path"`
+
+	// Strategy is the sync strategy for the generated files: overwrite
+	// (the default) or merge.
+	Strategy string `yaml:"strategy"`
+}
+
+// TemplateConfig controls how template files are rendered.
+type TemplateConfig struct {
+	// Delimiters replaces the default {{ and }} action delimiters for every
+	// template file, as a [left, right] pair (e.g., ["[[", "]]"]).
+	Delimiters []string `yaml:"delimiters"`
+
+	// Overrides sets different delimiters for files matching a pattern.
+	// The first matching override wins.
+	Overrides []DelimiterOverride `yaml:"overrides"`
+}
+
+// DelimiterOverride sets the template delimiters for files matching Pattern.
+type DelimiterOverride struct {
+	Pattern    string   `yaml:"pattern"`
+	Delimiters []string `yaml:"delimiters"`
 }
 
 // Defaults controls which inherited default files are included or excluded.
@@ -31,6 +99,36 @@ type Variable struct {
 	Required    bool     `yaml:"required"`
 	Validate    string   `yaml:"validate"`
 	Choices     []string `yaml:"choices"`
+
+	// Min and Max bound int and float values.
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+
+	// MinLength and MaxLength bound the number of characters in a string
+	// value, or the number of entries in a list, multichoice or map value.
+	MinLength *int `yaml:"min_length"`
+	MaxLength *int `yaml:"max_length"`
+
+	// NotIn lists values that are rejected.
+	NotIn []string `yaml:"not_in"`
+
+	// Assert is a template expression, evaluated against earlier variables
+	// and this variable's value, that must render to "true".
+	Assert string `yaml:"assert"`
+
+	// Message replaces the generated error when any validation rule fails.
+	Message string `yaml:"message"`
+
+	// When is a template expression evaluated against earlier variables.
+	// The variable is only prompted when it renders to "true".
+	When string `yaml:"when"`
+}
+
+// Computed is a derived variable whose value is a template expression rendered
+// against the collected variables. Computed variables are never prompted.
+type Computed struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
 }
 
 // Condition defines conditional file inclusion/exclusion based on template expressions.
@@ -54,4 +152,8 @@ type SyncConfig struct {
 type ManagedFile struct {
 	Path     string `yaml:"path"`
 	Strategy string `yaml:"strategy"`
+
+	// Blueprint is the path of the extended or included blueprint the file
+	// comes from. It is set by composition, never read from YAML.
+	Blueprint string `yaml:"-"`
 }
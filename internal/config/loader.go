@@ -9,6 +9,22 @@ import (
 
 // LoadBlueprint reads and parses a blueprint.yaml file from the given path.
 func LoadBlueprint(path string) (*Blueprint, error) {
+	bp, err := ReadBlueprint(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateBlueprint(bp); err != nil {
+		return nil, fmt.Errorf("validating blueprint %s: %w", path, err)
+	}
+
+	return bp, nil
+}
+
+// ReadBlueprint reads and parses a blueprint.yaml file without validating
+// it, for blueprints that are only valid once composed with the blueprints
+// they extend or include.
+func ReadBlueprint(path string) (*Blueprint, error) {
 	data, err := os.ReadFile(path) //nolint:gosec // path is provided by the caller; this is a scaffolding tool that reads user-specified config files
 	if err != nil {
 		return nil, fmt.Errorf("reading blueprint file %s: %w", path, err)
@@ -19,10 +35,6 @@ func LoadBlueprint(path string) (*Blueprint, error) {
 		return nil, fmt.Errorf("parsing blueprint file %s: %w", path, err)
 	}
 
-	if err := ValidateBlueprint(&bp); err != nil {
-		return nil, fmt.Errorf("validating blueprint %s: %w", path, err)
-	}
-
 	return &bp, nil
 }
 
@@ -44,3 +56,19 @@ func LoadRegistry(path string) (*Registry, error) {
 
 	return &reg, nil
 }
+
+// LoadValues reads a values file mapping variable names to values. Both YAML
+// and JSON are accepted since JSON is valid YAML.
+func LoadValues(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by the caller via --values
+	if err != nil {
+		return nil, fmt.Errorf("reading values file %s: %w", path, err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing values file %s: %w", path, err)
+	}
+
+	return values, nil
+}
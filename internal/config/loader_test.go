@@ -132,3 +132,48 @@ func testdataPath(t *testing.T, relPath string) string {
 
 	return absPath
 }
+
+func TestLoadValues_YAML(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	content := "project_name: my-api\nport: 8080\nuse_grpc: true\nservices:\n  - api\n  - worker\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	values, err := config.LoadValues(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-api", values["project_name"])
+	assert.Equal(t, 8080, values["port"])
+	assert.Equal(t, true, values["use_grpc"])
+	assert.Equal(t, []any{"api", "worker"}, values["services"])
+}
+
+func TestLoadValues_JSON(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "values.json")
+	content := `{"project_name": "my-api", "labels": {"team": "core"}}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	values, err := config.LoadValues(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-api", values["project_name"])
+	assert.Equal(t, map[string]any{"team": "core"}, values["labels"])
+}
+
+func TestLoadValues_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := config.LoadValues("/nonexistent/values.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading values file")
+
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- not\n- a map\n"), 0o644))
+
+	_, err = config.LoadValues(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing values file")
+}
@@ -30,4 +30,14 @@ type BlueprintEntry struct {
 	Version      string   `yaml:"version"`
 	Tags         []string `yaml:"tags"`
 	LatestCommit string   `yaml:"latest_commit"`
+	// Versions lists earlier releases of the blueprint, for registries
+	// whose releases are not tagged in git.
+	Versions []VersionEntry `yaml:"versions,omitempty"`
+}
+
+// VersionEntry records a release of a blueprint and the registry commit
+// holding it.
+type VersionEntry struct {
+	Version string `yaml:"version"`
+	Commit  string `yaml:"commit"`
 }
@@ -2,16 +2,25 @@ package config
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
+	"text/template"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
 )
 
 // validVariableTypes are the allowed types for blueprint variables.
 var validVariableTypes = map[string]bool{
-	"string": true,
-	"bool":   true,
-	"choice": true,
-	"int":    true,
+	"string":      true,
+	"bool":        true,
+	"choice":      true,
+	"int":         true,
+	"float":       true,
+	"list":        true,
+	"map":         true,
+	"multichoice": true,
+	"secret":      true,
 }
 
 // validSyncStrategies are the allowed sync strategies.
@@ -36,6 +45,10 @@ func ValidateBlueprint(bp *Blueprint) error {
 		}
 	}
 
+	if err := validateComputed(bp); err != nil {
+		return err
+	}
+
 	for path, strategy := range bp.Defaults.OverrideStrategy {
 		if !validSyncStrategies[strategy] {
 			return fmt.Errorf("invalid override_strategy %q for path %q, must be one of: overwrite, merge", strategy, path)
@@ -51,6 +64,59 @@ func ValidateBlueprint(bp *Blueprint) error {
 		}
 	}
 
+	if err := validateGenerate(bp); err != nil {
+		return err
+	}
+
+	if err := ValidateComposition(bp); err != nil {
+		return err
+	}
+
+	for i, pattern := range bp.CopyWithoutRender {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("copy_without_render[%d]: invalid pattern %q: %w", i, pattern, err)
+		}
+	}
+
+	return validateTemplateConfig(&bp.Template)
+}
+
+// validateTemplateConfig checks the template delimiters and their overrides.
+func validateTemplateConfig(tc *TemplateConfig) error {
+	if tc.Delimiters != nil {
+		if err := validateDelimiters(tc.Delimiters); err != nil {
+			return fmt.Errorf("template.delimiters: %w", err)
+		}
+	}
+
+	for i, o := range tc.Overrides {
+		if _, err := path.Match(o.Pattern, ""); err != nil || o.Pattern == "" {
+			return fmt.Errorf("template.overrides[%d]: invalid pattern %q", i, o.Pattern)
+		}
+
+		if err := validateDelimiters(o.Delimiters); err != nil {
+			return fmt.Errorf("template.overrides[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateDelimiters checks a [left, right] delimiter pair.
+func validateDelimiters(delims []string) error {
+	if len(delims) != 2 {
+		return fmt.Errorf("delimiters must be a [left, right] pair, got %d value(s)", len(delims))
+	}
+
+	left, right := strings.TrimSpace(delims[0]), strings.TrimSpace(delims[1])
+	if left == "" || right == "" || left != delims[0] || right != delims[1] {
+		return fmt.Errorf("delimiters must be non-empty and contain no surrounding whitespace")
+	}
+
+	if left == right {
+		return fmt.Errorf("left and right delimiters must differ, got %q", left)
+	}
+
 	return nil
 }
 
@@ -86,11 +152,14 @@ func validateVariable(v *Variable, index int) error {
 	}
 
 	if !validVariableTypes[v.Type] {
-		return fmt.Errorf("variables[%d] (%s): invalid type %q, must be one of: string, bool, choice, int", index, v.Name, v.Type)
+		return fmt.Errorf(
+			"variables[%d] (%s): invalid type %q, must be one of: string, bool, choice, int, float, list, map, multichoice, secret",
+			index, v.Name, v.Type,
+		)
 	}
 
-	if v.Type == "choice" && len(v.Choices) == 0 {
-		return fmt.Errorf("variables[%d] (%s): choices are required for type \"choice\"", index, v.Name)
+	if (v.Type == "choice" || v.Type == "multichoice") && len(v.Choices) == 0 {
+		return fmt.Errorf("variables[%d] (%s): choices are required for type %q", index, v.Name, v.Type)
 	}
 
 	if v.Validate != "" {
@@ -99,5 +168,133 @@ func validateVariable(v *Variable, index int) error {
 		}
 	}
 
+	if v.When != "" {
+		if _, err := template.New("when").Funcs(tmpl.FuncMap()).Parse(v.When); err != nil {
+			return fmt.Errorf("variables[%d] (%s): invalid when expression %q: %w", index, v.Name, v.When, err)
+		}
+	}
+
+	if err := validateRules(v); err != nil {
+		return fmt.Errorf("variables[%d] (%s): %w", index, v.Name, err)
+	}
+
+	return nil
+}
+
+// validateRules statically checks that a variable's validation rules apply to
+// its type and are internally consistent.
+func validateRules(v *Variable) error {
+	if v.Min != nil || v.Max != nil {
+		if v.Type != "int" && v.Type != "float" {
+			return fmt.Errorf("min and max only apply to int and float variables, not %q", v.Type)
+		}
+
+		if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+			return fmt.Errorf("min %v is greater than max %v", *v.Min, *v.Max)
+		}
+	}
+
+	if v.MinLength != nil || v.MaxLength != nil {
+		switch v.Type {
+		case "bool", "int", "float":
+			return fmt.Errorf("min_length and max_length do not apply to %q variables", v.Type)
+		}
+
+		if (v.MinLength != nil && *v.MinLength < 0) || (v.MaxLength != nil && *v.MaxLength < 0) {
+			return fmt.Errorf("min_length and max_length must not be negative")
+		}
+
+		if v.MinLength != nil && v.MaxLength != nil && *v.MinLength > *v.MaxLength {
+			return fmt.Errorf("min_length %d is greater than max_length %d", *v.MinLength, *v.MaxLength)
+		}
+	}
+
+	if v.Assert != "" {
+		if _, err := template.New("assert").Funcs(tmpl.FuncMap()).Parse(v.Assert); err != nil {
+			return fmt.Errorf("invalid assert expression %q: %w", v.Assert, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateComposition checks that extends and includes name registry paths.
+func ValidateComposition(bp *Blueprint) error {
+	if bp.Extends != "" && !validBlueprintPath(bp.Extends) {
+		return fmt.Errorf("extends: invalid blueprint path %q", bp.Extends)
+	}
+
+	for i, inc := range bp.Includes {
+		if !validBlueprintPath(inc) {
+			return fmt.Errorf("includes[%d]: invalid blueprint path %q", i, inc)
+		}
+	}
+
+	return nil
+}
+
+// validBlueprintPath reports whether p is a clean, relative registry path
+// such as "go/base".
+func validBlueprintPath(p string) bool {
+	return p != "" && p != "." && !path.IsAbs(p) && path.Clean(p) == p && !strings.HasPrefix(p, "../")
+}
+
+// identifierPattern matches names usable as .name in templates.
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// validateGenerate checks that each generator names its template, a list or
+// multichoice variable to iterate over, and an output path.
+func validateGenerate(bp *Blueprint) error {
+	types := make(map[string]string, len(bp.Variables))
+	for i := range bp.Variables {
+		types[bp.Variables[i].Name] = bp.Variables[i].Type
+	}
+
+	for i := range bp.Generate {
+		g := &bp.Generate[i]
+
+		switch {
+		case strings.TrimSpace(g.Template) == "":
+			return fmt.Errorf("generate[%d]: template is required", i)
+		case strings.TrimSpace(g.Path) == "":
+			return fmt.Errorf("generate[%d]: path is required", i)
+		case types[g.Each] != "list" && types[g.Each] != "multichoice":
+			return fmt.Errorf("generate[%d]: each must name a list or multichoice variable, got %q", i, g.Each)
+		case g.As != "" && !identifierPattern.MatchString(g.As):
+			return fmt.Errorf("generate[%d]: invalid as name %q", i, g.As)
+		case g.Strategy != "" && !validSyncStrategies[g.Strategy]:
+			return fmt.Errorf("generate[%d]: invalid strategy %q, must be one of: overwrite, merge", i, g.Strategy)
+		}
+
+		if _, err := template.New("path").Funcs(tmpl.FuncMap()).Parse(g.Path); err != nil {
+			return fmt.Errorf("generate[%d]: invalid path template %q: %w", i, g.Path, err)
+		}
+	}
+
+	return nil
+}
+
+func validateComputed(bp *Blueprint) error {
+	names := make(map[string]bool, len(bp.Variables)+len(bp.Computed))
+	for i := range bp.Variables {
+		names[bp.Variables[i].Name] = true
+	}
+
+	for i, c := range bp.Computed {
+		if strings.TrimSpace(c.Name) == "" {
+			return fmt.Errorf("computed[%d]: name is required", i)
+		}
+
+		if names[c.Name] {
+			return fmt.Errorf("computed[%d] (%s): name conflicts with another variable", i, c.Name)
+		}
+
+		names[c.Name] = true
+
+		if _, err := template.New("computed").Funcs(tmpl.FuncMap()).Parse(c.Value); err != nil {
+			return fmt.Errorf("computed[%d] (%s): invalid template %q: %w", i, c.Name, c.Value, err)
+		}
+	}
+
 	return nil
 }
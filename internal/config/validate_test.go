@@ -53,7 +53,7 @@ func TestValidateBlueprint_InvalidVariableType(t *testing.T) {
 		APIVersion: "v1",
 		Name:       "test",
 		Variables: []config.Variable{
-			{Name: "foo", Type: "float"},
+			{Name: "foo", Type: "uuid"},
 		},
 	}
 	err := config.ValidateBlueprint(bp)
@@ -192,3 +192,344 @@ func TestValidateBlueprint_VariableTypeRequired(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "type is required")
 }
+
+func TestValidateBlueprint_ExtendedVariableTypes(t *testing.T) {
+	t.Parallel()
+
+	bp := &config.Blueprint{
+		APIVersion: "v1",
+		Name:       "test",
+		Variables: []config.Variable{
+			{Name: "ratio", Type: "float"},
+			{Name: "services", Type: "list"},
+			{Name: "labels", Type: "map"},
+			{Name: "features", Type: "multichoice", Choices: []string{"docker", "ci"}},
+			{Name: "token", Type: "secret"},
+		},
+	}
+
+	require.NoError(t, config.ValidateBlueprint(bp))
+}
+
+func TestValidateBlueprint_MultichoiceWithoutChoices(t *testing.T) {
+	t.Parallel()
+
+	bp := &config.Blueprint{
+		APIVersion: "v1",
+		Name:       "test",
+		Variables: []config.Variable{
+			{Name: "features", Type: "multichoice"},
+		},
+	}
+
+	err := config.ValidateBlueprint(bp)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `choices are required for type "multichoice"`)
+}
+
+func TestValidateBlueprint_InvalidWhen(t *testing.T) {
+	t.Parallel()
+
+	bp := &config.Blueprint{
+		APIVersion: "v1",
+		Name:       "test",
+		Variables: []config.Variable{
+			{Name: "grpc_port", Type: "int", When: "{{ .use_grpc "},
+		},
+	}
+
+	err := config.ValidateBlueprint(bp)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid when expression")
+}
+
+func TestValidateBlueprint_Computed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		computed []config.Computed
+		wantErr  string
+	}{
+		{
+			name:     "valid",
+			computed: []config.Computed{{Name: "go_package", Value: "{{ .project_name | snakeCase }}"}},
+		},
+		{
+			name:     "missing name",
+			computed: []config.Computed{{Value: "x"}},
+			wantErr:  "computed[0]: name is required",
+		},
+		{
+			name:     "conflicts with variable",
+			computed: []config.Computed{{Name: "project_name", Value: "x"}},
+			wantErr:  "name conflicts with another variable",
+		},
+		{
+			name:     "invalid template",
+			computed: []config.Computed{{Name: "pkg", Value: "{{ .project_name "}},
+			wantErr:  "invalid template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bp := &config.Blueprint{
+				APIVersion: "v1",
+				Name:       "test",
+				Variables:  []config.Variable{{Name: "project_name", Type: "string"}},
+				Computed:   tt.computed,
+			}
+
+			err := config.ValidateBlueprint(bp)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateBlueprint_ValidationRules(t *testing.T) {
+	t.Parallel()
+
+	one, two := 1.0, 2.0
+	lenOne, lenTwo, negative := 1, 2, -1
+
+	tests := []struct {
+		name     string
+		variable config.Variable
+		wantErr  string
+	}{
+		{
+			name:     "valid rules",
+			variable: config.Variable{Name: "port", Type: "int", Min: &one, Max: &two, Assert: "{{ gt .port 0 }}", Message: "bad port"},
+		},
+		{
+			name:     "min on string",
+			variable: config.Variable{Name: "name", Type: "string", Min: &one},
+			wantErr:  "min and max only apply to int and float variables",
+		},
+		{
+			name:     "min above max",
+			variable: config.Variable{Name: "port", Type: "int", Min: &two, Max: &one},
+			wantErr:  "min 2 is greater than max 1",
+		},
+		{
+			name:     "length on bool",
+			variable: config.Variable{Name: "flag", Type: "bool", MinLength: &lenOne},
+			wantErr:  `do not apply to "bool" variables`,
+		},
+		{
+			name:     "negative length",
+			variable: config.Variable{Name: "name", Type: "string", MinLength: &negative},
+			wantErr:  "must not be negative",
+		},
+		{
+			name:     "min_length above max_length",
+			variable: config.Variable{Name: "name", Type: "string", MinLength: &lenTwo, MaxLength: &lenOne},
+			wantErr:  "min_length 2 is greater than max_length 1",
+		},
+		{
+			name:     "invalid assert",
+			variable: config.Variable{Name: "name", Type: "string", Assert: "{{ .name "},
+			wantErr:  "invalid assert expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bp := &config.Blueprint{
+				APIVersion: "v1",
+				Name:       "test",
+				Variables:  []config.Variable{tt.variable},
+			}
+
+			err := config.ValidateBlueprint(bp)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateBlueprint_CopyWithoutRender(t *testing.T) {
+	t.Parallel()
+
+	bp := &config.Blueprint{
+		APIVersion:        "v1",
+		Name:              "test",
+		CopyWithoutRender: []string{"*.svg", "charts/*"},
+	}
+	require.NoError(t, config.ValidateBlueprint(bp))
+
+	bp.CopyWithoutRender = []string{"[unclosed"}
+	err := config.ValidateBlueprint(bp)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "copy_without_render[0]: invalid pattern")
+}
+
+func TestValidateBlueprint_TemplateDelimiters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tc      config.TemplateConfig
+		wantErr string
+	}{
+		{
+			name: "valid",
+			tc: config.TemplateConfig{
+				Delimiters: []string{"[[", "]]"},
+				Overrides:  []config.DelimiterOverride{{Pattern: "charts/*", Delimiters: []string{"<%", "%>"}}},
+			},
+		},
+		{
+			name:    "single delimiter",
+			tc:      config.TemplateConfig{Delimiters: []string{"[["}},
+			wantErr: "template.delimiters: delimiters must be a [left, right] pair",
+		},
+		{
+			name:    "empty delimiter",
+			tc:      config.TemplateConfig{Delimiters: []string{"[[", ""}},
+			wantErr: "must be non-empty",
+		},
+		{
+			name:    "identical delimiters",
+			tc:      config.TemplateConfig{Delimiters: []string{"%%", "%%"}},
+			wantErr: "must differ",
+		},
+		{
+			name:    "override without delimiters",
+			tc:      config.TemplateConfig{Overrides: []config.DelimiterOverride{{Pattern: "*.yml"}}},
+			wantErr: "template.overrides[0]: delimiters must be a [left, right] pair",
+		},
+		{
+			name:    "override with bad pattern",
+			tc:      config.TemplateConfig{Overrides: []config.DelimiterOverride{{Pattern: "[", Delimiters: []string{"<%", "%>"}}}},
+			wantErr: "template.overrides[0]: invalid pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := config.ValidateBlueprint(&config.Blueprint{APIVersion: "v1", Name: "test", Template: tt.tc})
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateBlueprint_Generate(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "commands", Type: "list"},
+		{Name: "name", Type: "string"},
+	}
+
+	tests := []struct {
+		name    string
+		gen     config.Generator
+		wantErr string
+	}{
+		{
+			name: "valid",
+			gen:  config.Generator{Template: "_cmd/main.go.tmpl", Each: "commands", As: "command", Path: "cmd/{{ .command }}/main.go"},
+		},
+		{
+			name:    "missing template",
+			gen:     config.Generator{Each: "commands", Path: "cmd/{{ .item }}.go"},
+			wantErr: "generate[0]: template is required",
+		},
+		{
+			name:    "missing path",
+			gen:     config.Generator{Template: "cmd.go.tmpl", Each: "commands"},
+			wantErr: "generate[0]: path is required",
+		},
+		{
+			name:    "each not a list",
+			gen:     config.Generator{Template: "cmd.go.tmpl", Each: "name", Path: "cmd/{{ .item }}.go"},
+			wantErr: "each must name a list or multichoice variable",
+		},
+		{
+			name:    "invalid as",
+			gen:     config.Generator{Template: "cmd.go.tmpl", Each: "commands", As: "a-b", Path: "cmd/{{ .item }}.go"},
+			wantErr: "invalid as name",
+		},
+		{
+			name:    "invalid strategy",
+			gen:     config.Generator{Template: "cmd.go.tmpl", Each: "commands", Path: "cmd/{{ .item }}.go", Strategy: "append"},
+			wantErr: "invalid strategy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bp := &config.Blueprint{APIVersion: "v1", Name: "test", Variables: vars, Generate: []config.Generator{tt.gen}}
+
+			err := config.ValidateBlueprint(bp)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateBlueprint_Composition(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		extends  string
+		includes []string
+		wantErr  string
+	}{
+		{name: "valid", extends: "go/base", includes: []string{"ci/github", "docker/distroless"}},
+		{name: "absolute extends", extends: "/go/base", wantErr: `extends: invalid blueprint path "/go/base"`},
+		{name: "escaping include", includes: []string{"../ci"}, wantErr: `includes[0]: invalid blueprint path "../ci"`},
+		{name: "unclean include", includes: []string{"ci/github", "ci//github"}, wantErr: "includes[1]"},
+		{name: "empty include", includes: []string{""}, wantErr: "includes[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bp := &config.Blueprint{APIVersion: "v1", Name: "test", Extends: tt.extends, Includes: tt.includes}
+
+			err := config.ValidateBlueprint(bp)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,452 @@
+package create
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/donaldgifford/forge/internal/hooks"
+	"github.com/donaldgifford/forge/internal/lockfile"
+	forgesync "github.com/donaldgifford/forge/internal/sync"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// Conflict strategies for files Add would write over.
+const (
+	// ConflictSkip keeps the existing file.
+	ConflictSkip = "skip"
+
+	// ConflictOverwrite replaces the existing file.
+	ConflictOverwrite = "overwrite"
+
+	// ConflictMerge merges the blueprint's file into the existing one,
+	// inserting conflict markers where they differ.
+	ConflictMerge = "merge"
+)
+
+// AddAction is what Add did, or would do, with a file.
+type AddAction string
+
+// Add actions.
+const (
+	ActionCreated     AddAction = "created"
+	ActionUnchanged   AddAction = "unchanged"
+	ActionSkipped     AddAction = "skipped"
+	ActionOverwritten AddAction = "overwritten"
+	ActionMerged      AddAction = "merged"
+	ActionConflict    AddAction = "conflict"
+)
+
+// AddedFile reports the outcome of Add for one file of the blueprint.
+type AddedFile struct {
+	// Path is the file's path relative to the project directory.
+	Path   string
+	Action AddAction
+
+	// TrackedBy is set when the file was skipped because it is already
+	// tracked by another blueprint applied to the project.
+	TrackedBy string
+}
+
+// AddResult holds the output of an add operation.
+type AddResult struct {
+	ProjectDir string
+	Blueprint  string
+	Files      []AddedFile
+
+	// Hooks holds the outcome of each post-create hook in execution order.
+	Hooks []hooks.Result
+}
+
+// Add renders a blueprint into the existing project at opts.OutputDir
+// (default "."). Files the project does not have are created; files that
+// exist with different content are handled according to opts.OnConflict.
+// Nothing is written unless every file can be applied. The blueprint is then recorded in the project's lockfile next to the
+// blueprints already applied, so check and sync track its files too. A
+// project without a lockfile gets one for the added blueprint.
+//
+// Variables the project already has values for are not prompted again.
+// Files another applied blueprint tracks are left to that blueprint.
+// The blueprint's post-create hooks only run with opts.RunHooks.
+// With opts.DryRun, the result describes what would happen and nothing is
+// written.
+func Add(opts *Opts) (*AddResult, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	onConflict := cmp.Or(opts.OnConflict, ConflictSkip)
+	if !slices.Contains([]string{ConflictSkip, ConflictOverwrite, ConflictMerge}, onConflict) {
+		return nil, fmt.Errorf("invalid conflict strategy %q, must be one of: skip, overwrite, merge", onConflict)
+	}
+
+	addOpts := *opts
+	addOpts.OutputDir = cmp.Or(opts.OutputDir, ".")
+	addOpts.NoHooks = opts.NoHooks || !opts.RunHooks
+	projectDir := addOpts.OutputDir
+
+	if info, err := os.Stat(projectDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("project directory %s does not exist", projectDir)
+	}
+
+	existing, err := readProjectLock(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse a blueprint the project already has before prompting for its
+	// variables.
+	resolved, err := resolveRef(&addOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, applied := range existing {
+		if applied.Blueprint.Path == resolved.BlueprintPath {
+			return nil, fmt.Errorf("blueprint %s is already applied to %s — use forge sync to update it", applied.Blueprint.Path, projectDir)
+		}
+	}
+
+	sc, err := prepare(&addOpts, projectVars(existing), logger)
+	if err != nil {
+		return nil, err
+	}
+
+	files, lock, err := addFiles(ctx, &addOpts, sc, existing, onConflict, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AddResult{ProjectDir: projectDir, Blueprint: sc.bp.Name, Files: files}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if len(existing) > 0 {
+		project := existing[0]
+		project.Applied = append(project.Applied, *lock)
+		lock = project
+	}
+
+	if err := lockfile.Write(filepath.Join(projectDir, lockfile.FileName), lock); err != nil {
+		return nil, fmt.Errorf("writing lockfile: %w", err)
+	}
+
+	logger.Info("blueprint added", "dir", projectDir, "blueprint", sc.bp.Name)
+
+	result.Hooks = runPostCreateHooks(ctx, &addOpts, sc.hooks, projectDir, logger)
+
+	return result, nil
+}
+
+// readProjectLock reads the project's lockfile and returns the record of
+// every blueprint applied to the project (see lockfile.Blueprints). A
+// project without a lockfile has none.
+func readProjectLock(projectDir string) ([]*lockfile.Lockfile, error) {
+	lockPath := filepath.Join(projectDir, lockfile.FileName)
+
+	if _, err := os.Stat(lockPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	lock, err := lockfile.Read(lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading lockfile: %w", err)
+	}
+
+	return lock.Blueprints(), nil
+}
+
+// projectVars returns the variables of every applied blueprint, later
+// blueprints winning.
+func projectVars(applied []*lockfile.Lockfile) map[string]any {
+	vars := make(map[string]any)
+
+	for _, lock := range applied {
+		maps.Copy(vars, lock.TemplateVars())
+	}
+
+	return vars
+}
+
+// addFiles renders the blueprint into a staging directory and applies each
+// staged file to the project. It returns the outcome per file and the
+// lockfile record for the blueprint, without the files other blueprints
+// already track.
+func addFiles(
+	ctx context.Context,
+	opts *Opts,
+	sc *scaffold,
+	existing []*lockfile.Lockfile,
+	onConflict string,
+	logger *slog.Logger,
+) ([]AddedFile, *lockfile.Lockfile, error) {
+	staging, err := newStagingDir(sc.outputDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		if err := os.RemoveAll(staging); err != nil {
+			logger.Warn("failed to remove staging directory", "dir", staging, "err", err)
+		}
+	}()
+
+	if _, err := renderFiles(ctx, sc, staging); err != nil {
+		return nil, nil, err
+	}
+
+	// Hashes are those of the blueprint's content, so check reports files
+	// that were skipped or merged as locally modified.
+	lock := newLockfile(opts, sc, staging)
+
+	tracked := trackedFiles(existing)
+	untrack(lock, tracked)
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("add cancelled: %w", err)
+	}
+
+	// A dry run first checks that every file can be applied, so a project
+	// is never left with only some of them.
+	files, err := applyStaged(staging, sc.outputDir, onConflict, tracked, true)
+	if err != nil || opts.DryRun {
+		return files, lock, err
+	}
+
+	files, err = applyStaged(staging, sc.outputDir, onConflict, tracked, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, lock, nil
+}
+
+// trackedFiles maps the output path of every file tracked for the applied
+// blueprints to the blueprint tracking it.
+func trackedFiles(blueprints []*lockfile.Lockfile) map[string]string {
+	tracked := make(map[string]string)
+
+	for _, applied := range blueprints {
+		owner := applied.Blueprint.Path
+
+		for i := range applied.Defaults {
+			tracked[tmpl.StripTemplateExtension(applied.Defaults[i].Path)] = owner
+		}
+
+		for i := range applied.ManagedFiles {
+			tracked[applied.ManagedFiles[i].Path] = owner
+		}
+
+		for i := range applied.Generated {
+			tracked[applied.Generated[i].Path] = owner
+		}
+	}
+
+	return tracked
+}
+
+// untrack removes the entries for tracked files from lock.
+func untrack(lock *lockfile.Lockfile, tracked map[string]string) {
+	lock.Defaults = slices.DeleteFunc(lock.Defaults, func(d lockfile.DefaultEntry) bool {
+		return tracked[tmpl.StripTemplateExtension(d.Path)] != ""
+	})
+	lock.ManagedFiles = slices.DeleteFunc(lock.ManagedFiles, func(mf lockfile.ManagedFileEntry) bool {
+		return tracked[mf.Path] != ""
+	})
+	lock.Generated = slices.DeleteFunc(lock.Generated, func(g lockfile.GeneratedEntry) bool {
+		return tracked[g.Path] != ""
+	})
+}
+
+// applyStaged moves each file rendered into staging into projectDir,
+// leaving files in tracked alone. With dryRun, nothing is moved, but an
+// error is still returned for any file that could not be.
+func applyStaged(staging, projectDir, onConflict string, tracked map[string]string, dryRun bool) ([]AddedFile, error) {
+	var files []AddedFile
+
+	err := filepath.WalkDir(staging, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+
+		dest := filepath.Join(projectDir, rel)
+
+		if d.IsDir() {
+			if dryRun {
+				return checkDir(dest)
+			}
+
+			return os.MkdirAll(dest, 0o750)
+		}
+
+		file := AddedFile{Path: filepath.ToSlash(rel), Action: ActionSkipped, TrackedBy: tracked[filepath.ToSlash(rel)]}
+
+		if file.TrackedBy == "" {
+			file.Action, err = addFile(path, dest, onConflict, dryRun)
+			if err != nil {
+				return fmt.Errorf("adding %s: %w", file.Path, err)
+			}
+		}
+
+		files = append(files, file)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("adding files to %s: %w", projectDir, err)
+	}
+
+	return files, nil
+}
+
+// addFile moves the staged file src to dest, resolving a conflict with an
+// existing dest according to onConflict.
+func addFile(src, dest, onConflict string, dryRun bool) (AddAction, error) {
+	destInfo, err := os.Lstat(dest)
+	if os.IsNotExist(err) {
+		if !dryRun {
+			if err := os.Rename(src, dest); err != nil {
+				return "", err
+			}
+		}
+
+		return ActionCreated, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return "", err
+	}
+
+	same, err := sameContent(src, dest, srcInfo, destInfo)
+	if err != nil || same {
+		return ActionUnchanged, err
+	}
+
+	switch {
+	case onConflict == ConflictOverwrite:
+		if destInfo.IsDir() {
+			return "", fmt.Errorf("%s is a directory", dest)
+		}
+
+		if !dryRun {
+			if err := os.Rename(src, dest); err != nil {
+				return "", err
+			}
+		}
+
+		return ActionOverwritten, nil
+	case onConflict == ConflictMerge && srcInfo.Mode().IsRegular() && destInfo.Mode().IsRegular():
+		return mergeFile(src, dest, destInfo.Mode().Perm(), dryRun)
+	default:
+		return ActionSkipped, nil
+	}
+}
+
+// checkDir returns an error if dest exists and is not a directory, so a
+// staged directory cannot be created there.
+func checkDir(dest string) error {
+	info, err := os.Stat(dest)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("%s exists and is not a directory", dest)
+	}
+
+	return nil
+}
+
+// sameContent reports whether src and dest are the same kind of file with
+// the same content (or, for symlinks, target).
+func sameContent(src, dest string, srcInfo, destInfo os.FileInfo) (bool, error) {
+	if srcInfo.Mode().Type() != destInfo.Mode().Type() {
+		return false, nil
+	}
+
+	switch {
+	case srcInfo.Mode()&os.ModeSymlink != 0:
+		srcTarget, err := os.Readlink(src)
+		if err != nil {
+			return false, err
+		}
+
+		destTarget, err := os.Readlink(dest)
+		if err != nil {
+			return false, err
+		}
+
+		return srcTarget == destTarget, nil
+	case srcInfo.Mode().IsRegular():
+		srcContent, err := os.ReadFile(filepath.Clean(src))
+		if err != nil {
+			return false, err
+		}
+
+		destContent, err := os.ReadFile(filepath.Clean(dest))
+		if err != nil {
+			return false, err
+		}
+
+		return bytes.Equal(srcContent, destContent), nil
+	default:
+		return false, nil
+	}
+}
+
+// mergeFile merges the staged file src into dest. With no common ancestor,
+// lines only one of them has are kept and every region where they have
+// different lines becomes a conflict (see forgesync.TwoWayMerge).
+func mergeFile(src, dest string, mode os.FileMode, dryRun bool) (AddAction, error) {
+	remote, err := os.ReadFile(filepath.Clean(src))
+	if err != nil {
+		return "", err
+	}
+
+	local, err := os.ReadFile(filepath.Clean(dest))
+	if err != nil {
+		return "", err
+	}
+
+	merged := forgesync.TwoWayMerge(local, remote)
+
+	if !dryRun {
+		if err := os.WriteFile(dest, merged.Content, mode); err != nil {
+			return "", err
+		}
+	}
+
+	if merged.HasConflicts {
+		return ActionConflict, nil
+	}
+
+	return ActionMerged, nil
+}
@@ -0,0 +1,307 @@
+package create_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/config"
+	"github.com/donaldgifford/forge/internal/create"
+	"github.com/donaldgifford/forge/internal/lockfile"
+)
+
+// writeAddRegistry creates a registry with an "svc" blueprint and a
+// "ci/github" blueprint to add to projects created from it.
+func writeAddRegistry(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"registry.yaml": "apiVersion: v1\nname: test\nblueprints:\n" +
+			"  - name: svc\n    path: svc\n  - name: github\n    path: ci/github\n",
+		"_defaults/.editorconfig": "root = true\n",
+		"svc/blueprint.yaml": "apiVersion: v1\nname: svc\nvariables:\n" +
+			"  - name: project_name\n    type: string\n    required: true\n",
+		"svc/Makefile":       "build:\n\tgo build\n",
+		"svc/README.md.tmpl": "# {{ .project_name }}\n",
+		"ci/github/blueprint.yaml": "apiVersion: v1\nname: github\nvariables:\n" +
+			"  - name: project_name\n    type: string\n    required: true\n" +
+			"sync:\n  managed_files:\n    - path: .github/workflows/ci.yml\n      strategy: overwrite\n",
+		"ci/github/.github/workflows/ci.yml.tmpl": "name: {{ .project_name }}\n",
+		"ci/github/Makefile":                      "build:\n\tgo build\n\nci:\n\tmake test\n",
+	}
+
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	return dir
+}
+
+func createProject(t *testing.T, registryDir string) string {
+	t.Helper()
+
+	projectDir := filepath.Join(t.TempDir(), "demo")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "svc",
+		OutputDir:    projectDir,
+		RegistryDir:  registryDir,
+		Overrides:    map[string]string{"project_name": "demo"},
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	return projectDir
+}
+
+func TestAdd_AppliesBlueprintToProject(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeAddRegistry(t)
+	projectDir := createProject(t, registryDir)
+
+	// project_name is inherited from the lockfile rather than required again.
+	result, err := create.Add(&create.Opts{
+		BlueprintRef: "ci/github",
+		OutputDir:    projectDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "github", result.Blueprint)
+
+	actions := make(map[string]create.AddedFile)
+	for _, f := range result.Files {
+		actions[f.Path] = f
+	}
+
+	assert.Equal(t, create.ActionCreated, actions[".github/workflows/ci.yml"].Action)
+	assert.Equal(t, create.ActionSkipped, actions["Makefile"].Action)
+	assert.Equal(t, "svc", actions[".editorconfig"].TrackedBy)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, ".github", "workflows", "ci.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: demo\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(projectDir, "Makefile"))
+	require.NoError(t, err)
+	assert.Equal(t, "build:\n\tgo build\n", string(content), "conflicting files are skipped by default")
+
+	lock, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "svc", lock.Blueprint.Path)
+	require.Len(t, lock.Applied, 1)
+
+	applied := lock.Applied[0]
+	assert.Equal(t, "ci/github", applied.Blueprint.Path)
+	assert.Equal(t, "demo", applied.Variables["project_name"])
+	assert.Empty(t, applied.Defaults, ".editorconfig stays tracked by svc")
+	require.Len(t, applied.ManagedFiles, 1)
+	assert.NotEmpty(t, applied.ManagedFiles[0].Hash)
+
+	_, err = create.Add(&create.Opts{BlueprintRef: "ci/github", OutputDir: projectDir, RegistryDir: registryDir, UseDefaults: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already applied")
+}
+
+func TestAdd_AlreadyAppliedDoesNotPrompt(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeAddRegistry(t)
+	projectDir := createProject(t, registryDir)
+
+	_, err := create.Add(&create.Opts{BlueprintRef: "ci/github", OutputDir: projectDir, RegistryDir: registryDir, UseDefaults: true})
+	require.NoError(t, err)
+
+	// A variable the blueprint gained since it was applied has no value in
+	// the lockfile to inherit.
+	bpPath := filepath.Join(registryDir, "ci", "github", "blueprint.yaml")
+	bpYAML, err := os.ReadFile(bpPath)
+	require.NoError(t, err)
+	bpYAML = []byte(strings.Replace(string(bpYAML), "sync:", "  - name: runner\n    type: string\n    default: ubuntu-latest\nsync:", 1))
+	require.NoError(t, os.WriteFile(bpPath, bpYAML, 0o644))
+
+	prompted := 0
+	_, err = create.Add(&create.Opts{
+		BlueprintRef: "ci/github",
+		OutputDir:    projectDir,
+		RegistryDir:  registryDir,
+		PromptFn: func(*config.Variable, map[string]any) (string, error) {
+			prompted++
+			return "", nil
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already applied")
+	assert.Zero(t, prompted)
+}
+
+func TestAdd_ConflictStrategies(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		action create.AddAction
+		want   string
+	}{
+		create.ConflictOverwrite: {action: create.ActionOverwritten, want: "build:\n\tgo build\n\nci:\n\tmake test\n"},
+		create.ConflictMerge:     {action: create.ActionMerged, want: "build:\n\tgo build\n\nci:\n\tmake test\n"},
+	}
+
+	for onConflict, tt := range tests {
+		t.Run(onConflict, func(t *testing.T) {
+			t.Parallel()
+
+			registryDir := writeAddRegistry(t)
+			projectDir := createProject(t, registryDir)
+
+			result, err := create.Add(&create.Opts{
+				BlueprintRef: "ci/github",
+				OutputDir:    projectDir,
+				RegistryDir:  registryDir,
+				UseDefaults:  true,
+				OnConflict:   onConflict,
+			})
+			require.NoError(t, err)
+
+			for _, f := range result.Files {
+				if f.Path == "Makefile" {
+					assert.Equal(t, tt.action, f.Action)
+				}
+			}
+
+			content, err := os.ReadFile(filepath.Join(projectDir, "Makefile"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+}
+
+func TestAdd_DryRunWritesNothing(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeAddRegistry(t)
+	projectDir := createProject(t, registryDir)
+
+	before, err := os.ReadFile(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+
+	result, err := create.Add(&create.Opts{
+		BlueprintRef: "ci/github",
+		OutputDir:    projectDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		OnConflict:   create.ConflictOverwrite,
+		DryRun:       true,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Files)
+
+	assert.NoFileExists(t, filepath.Join(projectDir, ".github", "workflows", "ci.yml"))
+
+	after, err := os.ReadFile(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
+
+func TestAdd_FailureLeavesProjectUntouched(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeAddRegistry(t)
+	projectDir := createProject(t, registryDir)
+
+	// The staged Makefile cannot replace a directory. It is applied after
+	// .github, which must not be created either.
+	makefile := filepath.Join(projectDir, "Makefile")
+	require.NoError(t, os.Remove(makefile))
+	require.NoError(t, os.MkdirAll(filepath.Join(makefile, "sub"), 0o750))
+
+	before, err := os.ReadFile(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+
+	_, err = create.Add(&create.Opts{
+		BlueprintRef: "ci/github",
+		OutputDir:    projectDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		OnConflict:   create.ConflictOverwrite,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is a directory")
+
+	assert.NoDirExists(t, filepath.Join(projectDir, ".github"))
+	assert.DirExists(t, filepath.Join(makefile, "sub"))
+
+	after, err := os.ReadFile(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
+
+func TestAdd_HooksRunOnlyWhenRequested(t *testing.T) {
+	t.Parallel()
+
+	for _, runHooks := range []bool{false, true} {
+		t.Run(fmt.Sprintf("RunHooks=%t", runHooks), func(t *testing.T) {
+			t.Parallel()
+
+			registryDir := writeAddRegistry(t)
+			projectDir := createProject(t, registryDir)
+
+			bpPath := filepath.Join(registryDir, "ci", "github", "blueprint.yaml")
+			bp, err := os.ReadFile(bpPath)
+			require.NoError(t, err)
+			bp = append(bp, "hooks:\n  post_create:\n    - touch hooked.txt\n"...)
+			require.NoError(t, os.WriteFile(bpPath, bp, 0o644))
+
+			result, err := create.Add(&create.Opts{
+				BlueprintRef: "ci/github",
+				OutputDir:    projectDir,
+				RegistryDir:  registryDir,
+				UseDefaults:  true,
+				RunHooks:     runHooks,
+			})
+			require.NoError(t, err)
+
+			if runHooks {
+				require.Len(t, result.Hooks, 1)
+				assert.FileExists(t, filepath.Join(projectDir, "hooked.txt"))
+			} else {
+				assert.Empty(t, result.Hooks)
+				assert.NoFileExists(t, filepath.Join(projectDir, "hooked.txt"))
+			}
+		})
+	}
+}
+
+func TestAdd_CurrentDirectoryStagesOutsideProject(t *testing.T) {
+	registryDir := writeAddRegistry(t)
+	projectDir := createProject(t, registryDir)
+	t.Chdir(projectDir)
+
+	_, err := create.Add(&create.Opts{
+		BlueprintRef: "ci/github",
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(projectDir, ".github", "workflows", "ci.yml"))
+
+	staged, err := filepath.Glob(filepath.Join(projectDir, ".forge-create-*"))
+	require.NoError(t, err)
+	assert.Empty(t, staged)
+}
+
+func TestAdd_InvalidConflictStrategy(t *testing.T) {
+	t.Parallel()
+
+	_, err := create.Add(&create.Opts{BlueprintRef: "ci/github", OutputDir: t.TempDir(), OnConflict: "ask"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid conflict strategy "ask"`)
+}
@@ -0,0 +1,67 @@
+package create
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/donaldgifford/forge/internal/config"
+	"github.com/donaldgifford/forge/internal/lockfile"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// evaluateComputed renders each computed variable in declaration order.
+// Each expression sees the collected variables plus any computed variables
+// declared before it. Rendered values are trimmed of surrounding whitespace.
+func evaluateComputed(computed []config.Computed, vars map[string]any, opts tmpl.FuncOptions) (map[string]any, error) {
+	renderer := tmpl.NewRenderer().WithFuncOptions(opts)
+	ctx := maps.Clone(vars)
+	result := make(map[string]any, len(computed))
+
+	for _, c := range computed {
+		out, err := renderer.RenderString(c.Value, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating computed variable %q: %w", c.Name, err)
+		}
+
+		value := strings.TrimSpace(out)
+		ctx[c.Name] = value
+		result[c.Name] = value
+	}
+
+	return result, nil
+}
+
+// lockComputed splits the computed values into those recorded in the
+// lockfile and the expressions of those derived from secret variables,
+// directly or through another computed variable, whose values must never be
+// written. Derivation follows the variables each expression references; an
+// expression that cannot be analyzed is taken as derived from secrets.
+func lockComputed(bp *config.Blueprint, values map[string]any) (map[string]any, []lockfile.ComputedEntry) {
+	secret := make(map[string]bool)
+
+	for i := range bp.Variables {
+		if bp.Variables[i].Type == "secret" {
+			secret[bp.Variables[i].Name] = true
+		}
+	}
+
+	renderer := tmpl.NewRenderer()
+	locked := maps.Clone(values)
+
+	var derived []lockfile.ComputedEntry
+
+	for _, c := range bp.Computed {
+		analysis, err := renderer.Analyze(c.Name, c.Value)
+		if err == nil && !slices.ContainsFunc(analysis.Refs, func(ref tmpl.Reference) bool { return secret[ref.Name] }) {
+			continue
+		}
+
+		secret[c.Name] = true
+		delete(locked, c.Name)
+		derived = append(derived, lockfile.ComputedEntry{Name: c.Name, Value: c.Value})
+	}
+
+	return locked, derived
+}
@@ -14,19 +14,36 @@ import (
 // rendered against the variables. If the result is "true", files matching
 // the exclude glob patterns are removed.
 func EvaluateConditions(conditions []config.Condition, vars map[string]any, fileSet *defaults.FileSet) error {
+	_, err := evaluateConditions(conditions, vars, fileSet)
+
+	return err
+}
+
+// evaluateConditions is EvaluateConditions, additionally reporting each
+// removed file and the condition that removed it.
+func evaluateConditions(
+	conditions []config.Condition,
+	vars map[string]any,
+	fileSet *defaults.FileSet,
+) ([]ExcludedFile, error) {
 	if len(conditions) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	renderer := tmpl.NewRenderer()
 
+	var excluded []ExcludedFile
+
 	for i := range conditions {
-		if err := evaluateCondition(renderer, &conditions[i], vars, fileSet); err != nil {
-			return err
+		removed, err := evaluateCondition(renderer, &conditions[i], vars, fileSet)
+		if err != nil {
+			return nil, err
 		}
+
+		excluded = append(excluded, removed...)
 	}
 
-	return nil
+	return excluded, nil
 }
 
 func evaluateCondition(
@@ -34,25 +51,28 @@ func evaluateCondition(
 	cond *config.Condition,
 	vars map[string]any,
 	fileSet *defaults.FileSet,
-) error {
+) ([]ExcludedFile, error) {
 	result, err := renderer.RenderString(cond.When, vars)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Condition is active when the rendered result is "true".
 	if strings.TrimSpace(result) != "true" {
-		return nil
+		return nil, nil
 	}
 
+	var removed []ExcludedFile
+
 	// Remove files matching the exclude patterns.
 	for _, entry := range fileSet.Entries() {
 		if matchesAnyPattern(entry.RelPath, cond.Exclude) {
 			fileSet.Remove(entry.RelPath)
+			removed = append(removed, ExcludedFile{Path: entry.RelPath, When: cond.When})
 		}
 	}
 
-	return nil
+	return removed, nil
 }
 
 // matchesAnyPattern checks if a relative path matches any of the given glob patterns.
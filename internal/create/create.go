@@ -2,14 +2,21 @@
 package create
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/donaldgifford/forge/internal/compose"
 	"github.com/donaldgifford/forge/internal/config"
 	"github.com/donaldgifford/forge/internal/defaults"
+	"github.com/donaldgifford/forge/internal/hooks"
 	"github.com/donaldgifford/forge/internal/lockfile"
 	"github.com/donaldgifford/forge/internal/prompt"
 	"github.com/donaldgifford/forge/internal/registry"
@@ -18,24 +25,59 @@ import (
 
 // Opts holds the options for the create command.
 type Opts struct {
+	// Context cancels the create (e.g., on Ctrl-C). If nil, context.Background is used.
+	// A create cancelled before its files are moved into place leaves the output
+	// directory untouched.
+	Context context.Context
+
 	// BlueprintRef is the user-provided blueprint reference (e.g., "go/api", "go/api@v1.0.0").
 	BlueprintRef string
 
 	// OutputDir is the target directory. If empty, derived from project_name variable.
 	OutputDir string
 
-	// Overrides are --set key=value pairs from the CLI.
+	// Overrides are --set key=value pairs from the CLI. They take precedence
+	// over Values.
 	Overrides map[string]string
 
+	// Values are variable values loaded from --values files, already merged
+	// in order. Values may be structured (lists, maps) as decoded from YAML/JSON.
+	Values map[string]any
+
 	// UseDefaults skips interactive prompts and uses default values.
 	UseDefaults bool
 
 	// NoHooks skips post-create hook execution.
 	NoHooks bool
 
+	// RunHooks runs the post-create hooks of the blueprint Add applies,
+	// which skips them by default: hooks such as "git init" are written
+	// for new projects.
+	RunHooks bool
+
 	// ForceCreate allows overwriting a non-empty output directory.
 	ForceCreate bool
 
+	// OnConflict decides what Add does with a file the blueprint produces
+	// that already exists in the project with different content: one of
+	// ConflictSkip (the default), ConflictOverwrite or ConflictMerge.
+	OnConflict string
+
+	// Seed makes uuidv4 deterministic. If empty, a random seed is generated.
+	// The seed is recorded in the lockfile so sync renders the same UUIDs.
+	Seed string
+
+	// Now, if non-zero, is the time reported by the now template function.
+	Now time.Time
+
+	// Strict fails the create on any reference to an undefined variable in
+	// a template file, even if the blueprint does not set strict: true.
+	Strict bool
+
+	// DryRun runs the full pipeline without writing files or running hooks.
+	// The Result carries a Plan describing what would be produced.
+	DryRun bool
+
 	// DefaultRegistryURL is the default registry URL from global config.
 	DefaultRegistryURL string
 
@@ -48,6 +90,11 @@ type Opts struct {
 	// this is the go-getter URL. If empty, falls back to RegistryDir.
 	RegistryURL string
 
+	// Ref is the registry ref RegistryDir holds when BlueprintRef pins a
+	// version constraint (e.g., "go/api@^2.0"): the release the constraint
+	// resolved to. It is recorded in the lockfile with the constraint.
+	Ref string
+
 	// ForgeVersion is the current forge build version for lockfile recording.
 	ForgeVersion string
 
@@ -55,6 +102,12 @@ type Opts struct {
 	// If nil, prompting is skipped (variables must come from overrides or defaults).
 	PromptFn prompt.PromptFn
 
+	// Stdout receives post-create hook standard output. If nil, it is discarded.
+	Stdout io.Writer
+
+	// Stderr receives post-create hook standard error. If nil, it is discarded.
+	Stderr io.Writer
+
 	// Logger for debug output.
 	Logger *slog.Logger
 }
@@ -64,6 +117,13 @@ type Result struct {
 	OutputDir    string
 	FilesCreated int
 	Blueprint    string
+
+	// Hooks holds the outcome of each post-create hook in execution order.
+	// Empty when the blueprint has no hooks or hooks were skipped.
+	Hooks []hooks.Result
+
+	// Plan describes the files that would be produced. Set only for dry runs.
+	Plan *Plan
 }
 
 // Run executes the create workflow.
@@ -73,76 +133,342 @@ func Run(opts *Opts) (*Result, error) {
 		logger = slog.Default()
 	}
 
-	// 1-5. Resolve references and load config.
-	resolved, bp, err := resolveAndLoad(opts)
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// 1-8. Resolve, collect variables and compute the file set.
+	sc, err := prepare(opts, nil, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Guard: refuse to write into a non-empty directory without --force.
+	// A dry run writes nothing, so its plan reports the directory instead.
+	notEmpty := false
+
+	if !opts.ForceCreate {
+		notEmpty, err = outputDirNotEmpty(sc.outputDir)
+		if err != nil {
+			return nil, err
+		}
+
+		if notEmpty && !opts.DryRun {
+			return nil, fmt.Errorf("output directory %s is not empty — use --force to overwrite", sc.outputDir)
+		}
+	}
+
+	if opts.DryRun {
+		plan, err := buildPlan(sc)
+		if err != nil {
+			return nil, err
+		}
+
+		plan.OutputDirNotEmpty = notEmpty
+
+		return &Result{OutputDir: sc.outputDir, Blueprint: sc.bp.Name, Plan: plan}, nil
+	}
+
+	// 9-10. Render the project and move it into place.
+	filesCreated, err := writeProject(ctx, opts, sc, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Debug("loaded blueprint", "name", bp.Name, "version", bp.Version)
+	logger.Info("project created", "dir", sc.outputDir, "files", filesCreated)
+
+	// 11. Run post-create hooks.
+	hookResults := runPostCreateHooks(ctx, opts, sc.hooks, sc.outputDir, logger)
+
+	return &Result{
+		OutputDir:    sc.outputDir,
+		FilesCreated: filesCreated,
+		Blueprint:    sc.bp.Name,
+		Hooks:        hookResults,
+	}, nil
+}
+
+// scaffold holds everything a create has resolved before writing any files.
+type scaffold struct {
+	resolved  *registry.ResolvedBlueprint
+	bp        *config.Blueprint
+	sources   []string
+	collected *prompt.Collected
+	computed  map[string]any
+	vars      map[string]any
+	fileSet   *defaults.FileSet
+	excluded  []ExcludedFile
+	generated []generatedFile
+	hooks     []string
+	outputDir string
+
+	// funcOpts makes now and uuidv4 deterministic.
+	funcOpts tmpl.FuncOptions
+
+	// renderer renders file templates with the blueprint's partials.
+	renderer *tmpl.Renderer
+}
+
+// prepare runs the read-only part of the create workflow: resolving the
+// blueprint, collecting variables, resolving defaults and conditions, and
+// rendering hooks. Nothing is written. Declared variables without an
+// override take their value from inherited, if present there.
+func prepare(opts *Opts, inherited map[string]any, logger *slog.Logger) (*scaffold, error) {
+	// 1-5. Resolve references and load config, with any blueprints it
+	// extends or includes.
+	resolved, composed, err := resolveAndLoad(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	bp := composed.Blueprint
+	logger.Debug("loaded blueprint", "name", bp.Name, "version", bp.Version, "sources", composed.Sources)
+
+	sc := &scaffold{resolved: resolved, bp: bp, sources: composed.Sources}
+
+	sc.funcOpts, err = funcOptions(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	// 6. Collect variables.
-	vars, err := prompt.CollectVariables(bp.Variables, opts.Overrides, opts.UseDefaults, opts.PromptFn)
+	overrides := mergeOverrides(opts.Values, opts.Overrides)
+	inheritValues(overrides, bp.Variables, inherited)
+
+	sc.collected, err = prompt.Collect(bp.Variables, overrides, opts.UseDefaults, opts.PromptFn)
 	if err != nil {
 		return nil, fmt.Errorf("collecting variables: %w", err)
 	}
 
-	// 7. Resolve defaults inheritance.
-	fileSet, err := defaults.Resolve(opts.RegistryDir, resolved.BlueprintPath, bp.Defaults.Exclude)
+	if len(sc.collected.Skipped) > 0 {
+		logger.Debug("skipped variables", "names", sc.collected.Skipped)
+	}
+
+	// 6b. Evaluate computed variables.
+	sc.computed, err = evaluateComputed(bp.Computed, sc.collected.Values, sc.funcOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.vars = maps.Clone(sc.collected.Values)
+	maps.Copy(sc.vars, sc.computed)
+
+	// 7. Resolve files, conditions and partials.
+	if err := resolveFiles(opts, sc); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("resolved files", "count", sc.fileSet.Len())
+
+	sc.hooks, err = renderHooks(sc.renderer, bp.Hooks.PostCreate, sc.vars)
+	if err != nil {
+		return nil, err
+	}
+
+	// 8. Determine the output directory.
+	sc.outputDir = resolveOutputDir(opts.OutputDir, sc.vars, bp.Name)
+
+	return sc, nil
+}
+
+// resolveFiles resolves the blueprint's file set through defaults
+// inheritance and composition, removes files excluded by conditions, marks verbatim files,
+// builds the file renderer with the blueprint's partials, expands generate
+// rules and, in strict mode, checks the templates for undefined variables.
+func resolveFiles(opts *Opts, sc *scaffold) error {
+	bp := sc.bp
+
+	var err error
+
+	// 7a. Resolve defaults inheritance across every composed blueprint.
+	sc.fileSet, err = defaults.ResolveComposed(opts.RegistryDir, sc.sources, bp.Defaults.Exclude)
 	if err != nil {
-		return nil, fmt.Errorf("resolving defaults: %w", err)
+		return fmt.Errorf("resolving defaults: %w", err)
 	}
 
 	// 7b. Evaluate conditions to exclude files.
-	if err := EvaluateConditions(bp.Conditions, vars, fileSet); err != nil {
-		return nil, fmt.Errorf("evaluating conditions: %w", err)
+	sc.excluded, err = evaluateConditions(bp.Conditions, sc.vars, sc.fileSet)
+	if err != nil {
+		return fmt.Errorf("evaluating conditions: %w", err)
 	}
 
-	logger.Debug("resolved files", "count", fileSet.Len())
+	// 7c. Copy files matching copy_without_render verbatim.
+	ApplyCopyWithoutRender(bp.CopyWithoutRender, sc.fileSet)
 
-	// 8. Determine and create output directory.
-	outputDir := resolveOutputDir(opts.OutputDir, vars, bp.Name)
+	// 7d. Load the partials shared with every template.
+	partials, err := defaults.ResolvePartials(opts.RegistryDir, sc.sources...)
+	if err != nil {
+		return fmt.Errorf("resolving partials: %w", err)
+	}
 
-	// Guard: refuse to write into a non-empty directory without --force.
-	if !opts.ForceCreate {
-		if err := checkOutputDirEmpty(outputDir); err != nil {
-			return nil, err
+	strict := opts.Strict || bp.Strict
+	sc.renderer = tmpl.NewRenderer().WithFuncOptions(sc.funcOpts).WithPartials(partials).WithStrict(strict)
+
+	// 7e. Expand generate rules into one file per list item.
+	sc.generated, err = expandGenerators(sc)
+	if err != nil {
+		return err
+	}
+
+	// 7f. In strict mode, report every undefined variable up front.
+	if strict {
+		return checkTemplates(sc)
+	}
+
+	return nil
+}
+
+// funcOptions returns the deterministic template options for a create,
+// generating a random uuidv4 seed when none is given.
+func funcOptions(opts *Opts) (tmpl.FuncOptions, error) {
+	seed := opts.Seed
+	if seed == "" {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return tmpl.FuncOptions{}, fmt.Errorf("generating seed: %w", err)
 		}
+
+		seed = hex.EncodeToString(b)
 	}
 
-	if err := os.MkdirAll(outputDir, 0o750); err != nil {
-		return nil, fmt.Errorf("creating output directory %s: %w", outputDir, err)
+	return tmpl.FuncOptions{Now: opts.Now, Seed: seed}, nil
+}
+
+// writeProject renders files and the lockfile into a staging directory and
+// moves them into the output directory once everything has succeeded, so that
+// a failure or cancellation leaves the output directory untouched.
+func writeProject(ctx context.Context, opts *Opts, sc *scaffold, logger *slog.Logger) (int, error) {
+	staging, err := newStagingDir(sc.outputDir)
+	if err != nil {
+		return 0, err
 	}
 
+	defer func() {
+		if err := os.RemoveAll(staging); err != nil {
+			logger.Warn("failed to remove staging directory", "dir", staging, "err", err)
+		}
+	}()
+
 	// 9. Render and write files.
-	filesCreated, err := renderFiles(fileSet, vars, outputDir, bp.Rename)
+	filesCreated, err := renderFiles(ctx, sc, staging)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	// 10. Generate lockfile with content hashes.
-	lockPath := filepath.Join(outputDir, lockfile.FileName)
-	lock := buildLockfile(resolved, bp, vars, fileSet, opts.ForgeVersion, opts.RegistryURL)
-	computeFileHashes(outputDir, lock)
+	// 10. Generate lockfile with content hashes and modes.
+	lockPath := filepath.Join(staging, lockfile.FileName)
+	lock := newLockfile(opts, sc, staging)
 
 	if err := lockfile.Write(lockPath, lock); err != nil {
-		return nil, fmt.Errorf("writing lockfile: %w", err)
+		return 0, fmt.Errorf("writing lockfile: %w", err)
 	}
 
-	logger.Info("project created", "dir", outputDir, "files", filesCreated)
+	// 10b. Move the staged project into place.
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("create cancelled: %w", err)
+	}
 
-	return &Result{
-		OutputDir:    outputDir,
-		FilesCreated: filesCreated,
-		Blueprint:    bp.Name,
-	}, nil
+	if err := commitStaging(staging, sc.outputDir); err != nil {
+		return 0, err
+	}
+
+	return filesCreated, nil
+}
+
+// newLockfile returns the lockfile recording sc, with the hashes and modes
+// of the files rendered into dir.
+func newLockfile(opts *Opts, sc *scaffold, dir string) *lockfile.Lockfile {
+	lock := buildLockfile(sc.resolved, sc.bp, sc.vars, sc.fileSet, opts.ForgeVersion, opts.RegistryURL)
+	lock.Blueprint.Composed = sc.sources[:len(sc.sources)-1]
+	lock.SkippedVariables = sc.collected.Skipped
+	lock.Computed, lock.SecretComputed = lockComputed(sc.bp, sc.computed)
+	lock.Seed = sc.funcOpts.Seed
+	lock.Generated = generatedEntries(sc.generated, sc.bp)
+	computeFileHashes(dir, lock)
+
+	if registry.IsConstraint(sc.resolved.Ref) {
+		lock.Blueprint.Constraint, lock.Blueprint.Ref = sc.resolved.Ref, opts.Ref
+	}
+
+	// The registry commit is the base sync three-way merges against.
+	recordCommit(lock, registry.ResolveCommit(opts.RegistryDir, sc.resolved.BlueprintPath))
+
+	return lock
+}
+
+// recordCommit records commit as the registry commit of the blueprint and
+// the commit every tracked file was last synced at.
+func recordCommit(lock *lockfile.Lockfile, commit string) {
+	lock.Blueprint.Commit = commit
+
+	for i := range lock.Defaults {
+		lock.Defaults[i].SyncedCommit = commit
+	}
+
+	for i := range lock.ManagedFiles {
+		lock.ManagedFiles[i].SyncedCommit = commit
+	}
+
+	for i := range lock.Generated {
+		lock.Generated[i].SyncedCommit = commit
+	}
+}
+
+// mergeOverrides combines values-file entries with --set overrides.
+// --set entries win over values with the same name.
+func mergeOverrides(values map[string]any, set map[string]string) map[string]any {
+	merged := make(map[string]any, len(values)+len(set))
+	maps.Copy(merged, values)
+
+	for k, v := range set {
+		merged[k] = v
+	}
+
+	return merged
 }
 
-// resolveAndLoad resolves the blueprint reference, loads the registry index, and loads the blueprint config.
-func resolveAndLoad(opts *Opts) (*registry.ResolvedBlueprint, *config.Blueprint, error) {
+// inheritValues adds the inherited value of each declared variable that
+// has no override.
+func inheritValues(overrides map[string]any, vars []config.Variable, inherited map[string]any) {
+	for i := range vars {
+		name := vars[i].Name
+
+		if _, ok := overrides[name]; ok {
+			continue
+		}
+
+		if value, ok := inherited[name]; ok {
+			overrides[name] = value
+		}
+	}
+}
+
+// resolveAndLoad resolves the blueprint reference, loads the registry index, and loads the blueprint config
+// merged with the blueprints it extends or includes.
+func resolveAndLoad(opts *Opts) (*registry.ResolvedBlueprint, *compose.Result, error) {
+	resolved, err := resolveRef(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateRegistry(opts.RegistryDir, resolved); err != nil {
+		return nil, nil, err
+	}
+
+	composed, err := compose.Load(opts.RegistryDir, resolved.BlueprintPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading blueprint config: %w", err)
+	}
+
+	return resolved, composed, nil
+}
+
+// resolveRef resolves opts.BlueprintRef without reading the registry.
+func resolveRef(opts *Opts) (*registry.ResolvedBlueprint, error) {
 	registryDir := opts.RegistryDir
 	if registryDir == "" {
-		return nil, nil, fmt.Errorf(
+		return nil, fmt.Errorf(
 			"no registry directory provided — use --registry-dir or configure a default registry in ~/.config/forge/config.yaml",
 		)
 	}
@@ -157,22 +483,11 @@ func resolveAndLoad(opts *Opts) (*registry.ResolvedBlueprint, *config.Blueprint,
 		// only need the BlueprintPath.
 		resolved, err = registry.Resolve(opts.BlueprintRef, registryDir)
 		if err != nil {
-			return nil, nil, fmt.Errorf("resolving blueprint: %w", err)
+			return nil, fmt.Errorf("resolving blueprint: %w", err)
 		}
 	}
 
-	if err := validateRegistry(registryDir, resolved); err != nil {
-		return nil, nil, err
-	}
-
-	bpPath := filepath.Join(registryDir, resolved.BlueprintPath, "blueprint.yaml")
-
-	bp, err := config.LoadBlueprint(bpPath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("loading blueprint config: %w", err)
-	}
-
-	return resolved, bp, nil
+	return resolved, nil
 }
 
 // validateRegistry loads the registry index and checks the blueprint exists.
@@ -192,23 +507,19 @@ func validateRegistry(registryDir string, resolved *registry.ResolvedBlueprint)
 	return nil
 }
 
-// checkOutputDirEmpty returns an error if the directory exists and is non-empty.
-func checkOutputDirEmpty(dir string) error {
+// outputDirNotEmpty reports whether the directory exists and is non-empty.
+func outputDirNotEmpty(dir string) (bool, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		// Directory doesn't exist — that's fine, it will be created.
 		if os.IsNotExist(err) {
-			return nil
+			return false, nil
 		}
 
-		return fmt.Errorf("checking output directory: %w", err)
+		return false, fmt.Errorf("checking output directory: %w", err)
 	}
 
-	if len(entries) > 0 {
-		return fmt.Errorf("output directory %s is not empty — use --force to overwrite", dir)
-	}
-
-	return nil
+	return len(entries) > 0, nil
 }
 
 // resolveOutputDir determines the output directory from explicit option, project_name variable, or blueprint name.
@@ -224,66 +535,60 @@ func resolveOutputDir(explicit string, vars map[string]any, bpName string) strin
 	return bpName
 }
 
-// renderFiles renders all files from the FileSet to the output directory.
-func renderFiles(fileSet *defaults.FileSet, vars map[string]any, outputDir string, rename map[string]string) (int, error) {
-	renderer := tmpl.NewRenderer()
+// renderFiles renders all files from the FileSet, and the generated files,
+// to the output directory. It stops with an error as soon as ctx is cancelled.
+func renderFiles(ctx context.Context, sc *scaffold, outputDir string) (int, error) {
 	filesCreated := 0
+	bp := sc.bp
 
-	for _, entry := range fileSet.Entries() {
-		if err := writeFile(renderer, entry, vars, outputDir, rename); err != nil {
+	for _, entry := range sc.fileSet.Entries() {
+		if err := ctx.Err(); err != nil {
+			return 0, fmt.Errorf("create cancelled: %w", err)
+		}
+
+		if err := writeFile(FileRenderer(sc.renderer, &bp.Template, entry.RelPath), entry, sc.vars, outputDir, bp); err != nil {
 			return 0, fmt.Errorf("writing file %s: %w", entry.RelPath, err)
 		}
 
 		filesCreated++
 	}
 
+	for i := range sc.generated {
+		if err := ctx.Err(); err != nil {
+			return 0, fmt.Errorf("create cancelled: %w", err)
+		}
+
+		f := &sc.generated[i]
+		if err := writeGenerated(FileRenderer(sc.renderer, &bp.Template, f.entry.RelPath), f, outputDir); err != nil {
+			return 0, fmt.Errorf("writing generated file %s: %w", f.path, err)
+		}
+
+		filesCreated++
+	}
+
 	return filesCreated, nil
 }
 
-// writeFile renders a single file and writes it to the output directory.
-func writeFile(
+// outputPath returns the path, relative to the output directory, that a file
+// entry is written to: path templates are rendered, rename rules applied and
+// the .tmpl extension stripped.
+func outputPath(
 	renderer *tmpl.Renderer,
 	entry *defaults.FileEntry,
 	vars map[string]any,
-	outputDir string,
 	rename map[string]string,
-) error {
+) (string, error) {
 	// Render path templates (e.g., {{project_name}}/cmd/main.go).
 	renderedPath, err := renderer.RenderPath(entry.RelPath, vars)
 	if err != nil {
-		return fmt.Errorf("rendering path %q: %w", entry.RelPath, err)
+		return "", fmt.Errorf("rendering path %q: %w", entry.RelPath, err)
 	}
 
 	// Apply rename rules.
 	renderedPath = applyRename(renderedPath, rename, vars)
 
 	// Strip .tmpl extension.
-	renderedPath = tmpl.StripTemplateExtension(renderedPath)
-
-	destPath := filepath.Join(outputDir, renderedPath)
-
-	// Ensure parent directory exists.
-	if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
-		return fmt.Errorf("creating directory for %s: %w", destPath, err)
-	}
-
-	if entry.IsTemplate {
-		// Render template content.
-		content, err := renderer.RenderFile(entry.AbsPath, vars)
-		if err != nil {
-			return fmt.Errorf("rendering template %s: %w", entry.AbsPath, err)
-		}
-
-		return os.WriteFile(destPath, content, 0o644)
-	}
-
-	// Copy verbatim.
-	content, err := os.ReadFile(entry.AbsPath)
-	if err != nil {
-		return fmt.Errorf("reading source %s: %w", entry.AbsPath, err)
-	}
-
-	return os.WriteFile(destPath, content, 0o644)
+	return tmpl.StripTemplateExtension(renderedPath), nil
 }
 
 // applyRename applies rename rules to a rendered path.
@@ -324,7 +629,7 @@ func applyRename(path string, rename map[string]string, vars map[string]any) str
 	return path
 }
 
-// computeFileHashes reads the written output files and populates SHA256 hashes
+// computeFileHashes reads the written output files and populates SHA256 hashes and modes
 // in the lockfile entries. Errors are logged but don't fail the operation since
 // hashes are used for drift detection only.
 func computeFileHashes(outputDir string, lock *lockfile.Lockfile) {
@@ -336,6 +641,7 @@ func computeFileHashes(outputDir string, lock *lockfile.Lockfile) {
 
 		if err == nil {
 			d.Hash = lockfile.ContentHash(content)
+			d.Mode = lockfile.FileMode(filepath.Join(outputDir, renderedPath))
 		}
 	}
 
@@ -345,10 +651,40 @@ func computeFileHashes(outputDir string, lock *lockfile.Lockfile) {
 
 		if err == nil {
 			mf.Hash = lockfile.ContentHash(content)
+			mf.Mode = lockfile.FileMode(filepath.Join(outputDir, mf.Path))
+		}
+	}
+
+	for i := range lock.Generated {
+		g := &lock.Generated[i]
+		content, err := os.ReadFile(filepath.Clean(filepath.Join(outputDir, g.Path)))
+
+		if err == nil {
+			g.Hash = lockfile.ContentHash(content)
+			g.Mode = lockfile.FileMode(filepath.Join(outputDir, g.Path))
 		}
 	}
 }
 
+// lockVariables returns the variables to record in the lockfile.
+// Secret variables are never written to disk, and computed variables are
+// recorded separately.
+func lockVariables(bp *config.Blueprint, vars map[string]any) map[string]any {
+	locked := maps.Clone(vars)
+
+	for i := range bp.Variables {
+		if bp.Variables[i].Type == "secret" {
+			delete(locked, bp.Variables[i].Name)
+		}
+	}
+
+	for _, c := range bp.Computed {
+		delete(locked, c.Name)
+	}
+
+	return locked
+}
+
 // buildLockfile creates a Lockfile from the create operation results.
 func buildLockfile(
 	resolved *registry.ResolvedBlueprint,
@@ -377,16 +713,19 @@ func buildLockfile(
 		CreatedAt:    now,
 		LastSynced:   now,
 		ForgeVersion: forgeVersion,
-		Variables:    vars,
+		Variables:    lockVariables(bp, vars),
 	}
 
-	// Record default file entries.
+	// Record default file entries. Directories and preserved symlinks are
+	// not synced, so only entries written as regular files are tracked.
 	for _, entry := range fileSet.Entries() {
-		if entry.SourceLayer != defaults.LayerBlueprint {
+		if entry.SourceLayer != defaults.LayerBlueprint && writesRegularFile(entry, bp) {
 			lock.Defaults = append(lock.Defaults, lockfile.DefaultEntry{
-				Path:     entry.RelPath,
-				Source:   entry.SourceLayer.String(),
-				Strategy: "overwrite",
+				Path:       entry.RelPath,
+				Source:     entry.SourceLayer.String(),
+				Strategy:   "overwrite",
+				Verbatim:   isVerbatim(entry),
+				Delimiters: delimitersFor(&bp.Template, entry.RelPath),
 			})
 		}
 	}
@@ -395,10 +734,24 @@ func buildLockfile(
 	for i := range bp.Sync.ManagedFiles {
 		mf := &bp.Sync.ManagedFiles[i]
 		lock.ManagedFiles = append(lock.ManagedFiles, lockfile.ManagedFileEntry{
-			Path:     mf.Path,
-			Strategy: mf.Strategy,
+			Path:       mf.Path,
+			Strategy:   mf.Strategy,
+			Blueprint:  managedOrigin(mf, fileSet),
+			Verbatim:   tmpl.IsTemplate(mf.Path) && matchesFilePattern(mf.Path, bp.CopyWithoutRender),
+			Delimiters: delimitersFor(&bp.Template, mf.Path),
 		})
 	}
 
 	return lock
 }
+
+// managedOrigin returns the composed blueprint a managed file is synced
+// from: the blueprint providing the file, or else the one declaring it.
+// It is empty for the project's own blueprint.
+func managedOrigin(mf *config.ManagedFile, fileSet *defaults.FileSet) string {
+	if entry := fileSet.Get(mf.Path); entry != nil {
+		return entry.Blueprint
+	}
+
+	return mf.Blueprint
+}
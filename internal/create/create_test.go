@@ -1,9 +1,11 @@
 package create_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -236,3 +238,1020 @@ func TestRun_InvalidBlueprint(t *testing.T) {
 	_, err := create.Run(&opts)
 	require.Error(t, err)
 }
+
+// writeTestRegistry creates a minimal registry in a temp directory containing
+// a single "test/bp" blueprint with the given blueprint.yaml content and files
+// (keyed by path relative to the blueprint directory). Returns the registry root.
+func writeTestRegistry(t *testing.T, blueprintYAML string, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	bpDir := filepath.Join(dir, "test", "bp")
+	require.NoError(t, os.MkdirAll(bpDir, 0o750))
+
+	registryYAML := "apiVersion: v1\nname: test\nblueprints:\n  - name: test/bp\n    path: test/bp\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.yaml"), []byte(registryYAML), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(bpDir, "blueprint.yaml"), []byte(blueprintYAML), 0o644))
+
+	for rel, content := range files {
+		path := filepath.Join(bpDir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	return dir
+}
+
+func TestRun_ValuesMergedWithSetPrecedence(t *testing.T) {
+	t.Parallel()
+
+	outputDir := filepath.Join(t.TempDir(), "my-api")
+
+	opts := create.Opts{
+		BlueprintRef: "go/api",
+		OutputDir:    outputDir,
+		RegistryDir:  testRegistryDir,
+		UseDefaults:  true,
+		NoHooks:      true,
+		Values: map[string]any{
+			"project_name": "from-values",
+			"go_module":    "github.com/example/from-values",
+			"use_grpc":     false,
+		},
+		Overrides: map[string]string{
+			"project_name": "from-set",
+		},
+	}
+
+	_, err := create.Run(&opts)
+	require.NoError(t, err)
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+
+	assert.Equal(t, "from-set", lock.Variables["project_name"])
+	assert.Equal(t, "github.com/example/from-values", lock.Variables["go_module"])
+	assert.Equal(t, false, lock.Variables["use_grpc"])
+}
+
+func TestRun_UnknownValueKey(t *testing.T) {
+	t.Parallel()
+
+	opts := create.Opts{
+		BlueprintRef: "go/api",
+		OutputDir:    filepath.Join(t.TempDir(), "my-api"),
+		RegistryDir:  testRegistryDir,
+		UseDefaults:  true,
+		Values: map[string]any{
+			"project_name": "my-api",
+			"use_grcp":     true,
+		},
+	}
+
+	_, err := create.Run(&opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "use_grcp")
+}
+
+func TestRun_CollectionAndSecretVariables(t *testing.T) {
+	t.Parallel()
+
+	bp := `apiVersion: v1
+name: typed-bp
+variables:
+  - name: services
+    type: list
+    default: "api,worker"
+  - name: labels
+    type: map
+    default: "team=core"
+  - name: token
+    type: secret
+`
+	files := map[string]string{
+		"services.txt.tmpl": "{{ range .services }}svc={{ . }}\n{{ end }}{{ range $k, $v := .labels }}{{ $k }}={{ $v }}\n{{ end }}",
+		"token.txt.tmpl":    "{{ .token }}",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		Overrides:    map[string]string{"token": "s3cret"},
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "services.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "svc=api\nsvc=worker\nteam=core\n", string(content))
+
+	token, err := os.ReadFile(filepath.Join(outputDir, "token.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(token))
+
+	lockData, err := os.ReadFile(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(lockData), "s3cret")
+	assert.NotContains(t, string(lockData), "token")
+}
+
+func TestRun_SkippedVariablesRecorded(t *testing.T) {
+	t.Parallel()
+
+	bp := `apiVersion: v1
+name: when-bp
+variables:
+  - name: use_grpc
+    type: bool
+    default: "false"
+  - name: grpc_port
+    type: int
+    default: "9090"
+    when: "{{ .use_grpc }}"
+`
+	files := map[string]string{
+		"port.txt.tmpl": "{{ .grpc_port }}",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "port.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "9090", string(content))
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"grpc_port"}, lock.SkippedVariables)
+	assert.Equal(t, 9090, lock.Variables["grpc_port"])
+}
+
+func TestRun_ComputedVariables(t *testing.T) {
+	t.Parallel()
+
+	bp := `apiVersion: v1
+name: computed-bp
+variables:
+  - name: go_module
+    type: string
+    default: github.com/example/my-api
+computed:
+  - name: go_package
+    value: '{{ .go_module | trimPrefix "github.com/example/" | snakeCase }}'
+  - name: cmd_dir
+    value: 'cmd/{{ .go_package }}'
+  - name: dockerless
+    value: '{{ eq .go_package "other" }}'
+conditions:
+  - when: '{{ .dockerless }}'
+    exclude:
+      - Dockerfile
+`
+	files := map[string]string{
+		"{{go_package}}/main.go.tmpl": "package {{ .go_package }}\n",
+		"Dockerfile":                  "FROM scratch\n",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "my_api", "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package my_api\n", string(content))
+	assert.FileExists(t, filepath.Join(outputDir, "Dockerfile"))
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "my_api", lock.Computed["go_package"])
+	assert.Equal(t, "cmd/my_api", lock.Computed["cmd_dir"])
+	assert.NotContains(t, lock.Variables, "go_package")
+}
+
+func TestRun_ForgeFunctionsInVariableExpressions(t *testing.T) {
+	t.Parallel()
+
+	bp := `apiVersion: v1
+name: funcs-bp
+variables:
+  - name: project_name
+    type: string
+    default: my-api
+  - name: package_name
+    type: string
+    default: '{{ .project_name | snakeCase }}'
+    assert: '{{ .package_name | regexMatch "^[a-z_]+$" }}'
+  - name: image
+    type: string
+    when: '{{ .project_name | hasPrefix "my-" }}'
+    default: '{{ .project_name | upper }}'
+`
+	files := map[string]string{
+		"pkg.txt.tmpl": "{{ .package_name }} {{ .image }}\n",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "pkg.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "my_api MY-API\n", string(content))
+}
+
+func TestRun_ComputedFromSecretsNotLocked(t *testing.T) {
+	t.Parallel()
+
+	bp := `apiVersion: v1
+name: secret-computed-bp
+variables:
+  - name: db_password
+    type: secret
+computed:
+  - name: dsn
+    value: 'postgres://app:{{ .db_password }}@db/app'
+  - name: dsn_env
+    value: 'DSN={{ .dsn }}'
+  - name: db_host
+    value: db
+`
+	files := map[string]string{
+		".env.tmpl": "{{ .dsn_env }}\n",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		Overrides:    map[string]string{"db_password": "hunter2"},
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "DSN=postgres://app:hunter2@db/app\n", string(content))
+
+	lockData, err := os.ReadFile(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(lockData), "hunter2")
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"db_host": "db"}, lock.Computed)
+	assert.Equal(t, []lockfile.ComputedEntry{
+		{Name: "dsn", Value: "postgres://app:{{ .db_password }}@db/app"},
+		{Name: "dsn_env", Value: "DSN={{ .dsn }}"},
+	}, lock.SecretComputed)
+}
+
+func TestRun_DryRunWritesNothing(t *testing.T) {
+	t.Parallel()
+
+	bp := `apiVersion: v1
+name: plan-bp
+variables:
+  - name: project_name
+    type: string
+    default: demo
+  - name: use_docker
+    type: bool
+    default: "false"
+conditions:
+  - when: '{{ not .use_docker }}'
+    exclude:
+      - Dockerfile
+hooks:
+  post_create:
+    - "echo {{ .project_name }}"
+`
+	files := map[string]string{
+		"{{project_name}}/main.go.tmpl": "package {{ .project_name }}\n",
+		"Dockerfile":                    "FROM scratch\n",
+		"README.md":                     "readme\n",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	result, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		DryRun:       true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Plan)
+
+	assert.NoDirExists(t, outputDir)
+	assert.Equal(t, []create.PlannedFile{
+		{Path: "README.md", Source: "README.md", Layer: "blueprint", Kind: "file"},
+		{Path: "demo/main.go", Source: "{{project_name}}/main.go.tmpl", Layer: "blueprint", Template: true, Kind: "file"},
+	}, result.Plan.Files)
+	assert.Equal(t, []create.ExcludedFile{
+		{Path: "Dockerfile", When: "{{ not .use_docker }}"},
+	}, result.Plan.Excluded)
+	assert.Equal(t, []string{"echo demo"}, result.Plan.Hooks)
+	assert.Empty(t, result.Hooks)
+}
+
+func TestRun_DryRunIntoNonEmptyDirectory(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, "apiVersion: v1\nname: plan-bp\n", map[string]string{"README.md": "readme\n"})
+	outputDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "existing.txt"), []byte("keep\n"), 0o644))
+
+	opts := create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		DryRun:       true,
+	}
+
+	result, err := create.Run(&opts)
+	require.NoError(t, err, "a dry run writes nothing, so a non-empty directory is not an error")
+	require.NotNil(t, result.Plan)
+	assert.True(t, result.Plan.OutputDirNotEmpty)
+	assert.NoFileExists(t, filepath.Join(outputDir, "README.md"))
+
+	opts.DryRun = false
+	_, err = create.Run(&opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not empty")
+}
+
+func TestRun_DryRunReportsTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	bp := "apiVersion: v1\nname: broken-bp\n"
+	files := map[string]string{
+		"main.go.tmpl": "{{ .missing | nosuchfunc }}",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    filepath.Join(t.TempDir(), "out"),
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		DryRun:       true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "main.go.tmpl")
+}
+
+func TestRun_RenderFailureLeavesTargetUntouched(t *testing.T) {
+	t.Parallel()
+
+	bp := "apiVersion: v1\nname: broken-bp\n"
+	files := map[string]string{
+		"a.txt":       "new a\n",
+		"z/main.tmpl": "{{ .missing | nosuchfunc }}",
+		"README.md":   "new readme\n",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+	parent := t.TempDir()
+	outputDir := filepath.Join(parent, "out")
+	require.NoError(t, os.MkdirAll(outputDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "a.txt"), []byte("old a\n"), 0o644))
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		ForceCreate:  true,
+	})
+	require.Error(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "old a\n", string(content))
+	assert.NoFileExists(t, filepath.Join(outputDir, "README.md"))
+	assert.NoFileExists(t, filepath.Join(outputDir, lockfile.FileName))
+
+	entries, err := os.ReadDir(parent)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "staging directory should be removed")
+}
+
+func TestRun_ForceReplacesFilesAfterRendering(t *testing.T) {
+	t.Parallel()
+
+	bp := "apiVersion: v1\nname: force-bp\n"
+	files := map[string]string{
+		"a.txt":     "new a\n",
+		"sub/b.txt": "new b\n",
+	}
+
+	registryDir := writeTestRegistry(t, bp, files)
+	outputDir := filepath.Join(t.TempDir(), "out")
+	require.NoError(t, os.MkdirAll(filepath.Join(outputDir, "sub"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "a.txt"), []byte("old a\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "keep.txt"), []byte("keep\n"), 0o644))
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		ForceCreate:  true,
+	})
+	require.NoError(t, err)
+
+	for rel, want := range map[string]string{"a.txt": "new a\n", "sub/b.txt": "new b\n", "keep.txt": "keep\n"} {
+		content, err := os.ReadFile(filepath.Join(outputDir, rel))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content), rel)
+	}
+
+	assert.FileExists(t, filepath.Join(outputDir, lockfile.FileName))
+}
+
+func TestRun_CancelledContextWritesNothing(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	parent := t.TempDir()
+	outputDir := filepath.Join(parent, "my-api")
+
+	_, err := create.Run(&create.Opts{
+		Context:      ctx,
+		BlueprintRef: "go/api",
+		OutputDir:    outputDir,
+		RegistryDir:  testRegistryDir,
+		UseDefaults:  true,
+		Overrides:    map[string]string{"project_name": "my-api"},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	entries, err := os.ReadDir(parent)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRun_PreservesModesAndEmptyDirs(t *testing.T) {
+	t.Parallel()
+
+	outputDir := filepath.Join(t.TempDir(), "my-api")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "go/api",
+		OutputDir:    outputDir,
+		RegistryDir:  testRegistryDir,
+		UseDefaults:  true,
+		NoHooks:      true,
+		Overrides:    map[string]string{"project_name": "my-api"},
+	})
+	require.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(outputDir, "scripts", "lint.sh"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0o100, "lint.sh should be executable")
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+
+	for _, d := range lock.Defaults {
+		if d.Path == "scripts/lint.sh" {
+			assert.Equal(t, lockfile.FormatMode(info.Mode()), d.Mode)
+		}
+	}
+}
+
+func TestRun_SymlinksAndEmptyDirs(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, "apiVersion: v1\nname: link-bp\npreserve_symlinks: true\n", map[string]string{
+		"docs/README.md": "readme\n",
+	})
+	bpDir := filepath.Join(registryDir, "test", "bp")
+	require.NoError(t, os.Symlink("docs/README.md", filepath.Join(bpDir, "README.md")))
+	require.NoError(t, os.MkdirAll(filepath.Join(bpDir, "logs"), 0o750))
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	target, err := os.Readlink(filepath.Join(outputDir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "docs/README.md", target)
+	assert.DirExists(t, filepath.Join(outputDir, "logs"))
+}
+
+func TestRun_CopiedSymlinkToTemplateIsRendered(t *testing.T) {
+	t.Parallel()
+
+	bp := "apiVersion: v1\nname: link-bp\nvariables:\n  - name: project_name\n    type: string\n    default: demo\n"
+	registryDir := writeTestRegistry(t, bp, map[string]string{
+		"shared/name.txt.tmpl": "name={{ .project_name }}\n",
+	})
+	bpDir := filepath.Join(registryDir, "test", "bp")
+	require.NoError(t, os.Symlink("shared/name.txt.tmpl", filepath.Join(bpDir, "foo.txt.tmpl")))
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "foo.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "name=demo\n", string(content))
+}
+
+func TestRun_RejectsEscapingSymlink(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, "apiVersion: v1\nname: link-bp\npreserve_symlinks: true\n", nil)
+	bpDir := filepath.Join(registryDir, "test", "bp")
+	require.NoError(t, os.Symlink("../../etc/passwd", filepath.Join(bpDir, "passwd")))
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outside the project")
+	assert.NoDirExists(t, outputDir)
+}
+
+func TestRun_CopyWithoutRender(t *testing.T) {
+	t.Parallel()
+
+	bpYAML := "apiVersion: v1\nname: verbatim-bp\nvariables:\n  - name: name\n    type: string\n    default: demo\n" +
+		"copy_without_render:\n  - \"*.svg\"\n  - charts/*\n"
+	registryDir := writeTestRegistry(t, bpYAML, map[string]string{
+		"README.md.tmpl":                    "# {{ .name }}\n",
+		"assets/icon.svg.tmpl":              "<svg>{{ .raw }}</svg>\n",
+		"charts/templates/deploy.yaml.tmpl": "name: {{ .Values.name }}\n",
+		"logo.png.tmpl":                     "PNG\x00{{ .name }}",
+	})
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	for path, want := range map[string]string{
+		"README.md":                    "# demo\n",
+		"assets/icon.svg":              "<svg>{{ .raw }}</svg>\n",
+		"charts/templates/deploy.yaml": "name: {{ .Values.name }}\n",
+		"logo.png":                     "PNG\x00{{ .name }}",
+	} {
+		content, err := os.ReadFile(filepath.Join(outputDir, path))
+		require.NoError(t, err, path)
+		assert.Equal(t, want, string(content), path)
+	}
+}
+
+func TestRun_CustomDelimiters(t *testing.T) {
+	t.Parallel()
+
+	bpYAML := "apiVersion: v1\nname: delims-bp\nvariables:\n  - name: name\n    type: string\n    default: demo\n" +
+		"template:\n  delimiters: [\"[[\", \"]]\"]\n  overrides:\n    - pattern: \"*.sh.tmpl\"\n      delimiters: [\"<%\", \"%>\"]\n"
+	registryDir := writeTestRegistry(t, bpYAML, map[string]string{
+		"[[name]]/release.yml.tmpl": "name: [[ .name ]]\nref: ${{ github.ref }}\n",
+		"build.sh.tmpl":             "echo <% .name %> [[ .name ]]\n",
+	})
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "demo", "release.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: demo\nref: ${{ github.ref }}\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "build.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, "echo demo [[ .name ]]\n", string(content))
+}
+
+func TestRun_Partials(t *testing.T) {
+	t.Parallel()
+
+	bpYAML := "apiVersion: v1\nname: partials-bp\nvariables:\n  - name: name\n    type: string\n    default: demo\n"
+	registryDir := writeTestRegistry(t, bpYAML, map[string]string{
+		"main.go.tmpl":          "{{ template \"header\" . }}\npackage {{ .name }}\n",
+		"_partials/header.tmpl": "// {{ .name }} (blueprint)",
+		"README.md.tmpl":        "{{ include \"footer\" . | upper }}\n",
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "_partials"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "_partials", "header.tmpl"), []byte("// registry"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "_partials", "footer.tmpl"), []byte("made by {{ .name }}"), 0o644))
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "// demo (blueprint)\npackage demo\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "MADE BY DEMO\n", string(content))
+
+	assert.NoDirExists(t, filepath.Join(outputDir, "_partials"))
+}
+
+func TestRun_SeedMakesUUIDsReproducible(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, "apiVersion: v1\nname: seed-bp\n", map[string]string{
+		"id.txt.tmpl": "{{ uuidv4 }} {{ now \"2006\" }}\n",
+	})
+
+	render := func(seed string) (string, *lockfile.Lockfile) {
+		outputDir := filepath.Join(t.TempDir(), "out")
+
+		_, err := create.Run(&create.Opts{
+			BlueprintRef: "test/bp",
+			OutputDir:    outputDir,
+			RegistryDir:  registryDir,
+			UseDefaults:  true,
+			Seed:         seed,
+			Now:          time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(outputDir, "id.txt"))
+		require.NoError(t, err)
+
+		lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+		require.NoError(t, err)
+
+		return string(content), lock
+	}
+
+	first, lock := render("fixed")
+	second, _ := render("fixed")
+	assert.Equal(t, first, second)
+	assert.Contains(t, first, " 2020\n")
+	assert.Equal(t, "fixed", lock.Seed)
+
+	_, lock = render("")
+	assert.NotEmpty(t, lock.Seed, "a random seed should be generated and recorded")
+}
+
+func TestRun_SeededUUIDsDifferPerFile(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, "apiVersion: v1\nname: seed-bp\n", map[string]string{
+		"a/config.yaml.tmpl": "id={{ uuidv4 }}\n",
+		"b/config.yaml.tmpl": "id={{ uuidv4 }}\n",
+	})
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		Seed:         "fixed",
+	})
+	require.NoError(t, err)
+
+	a, err := os.ReadFile(filepath.Join(outputDir, "a", "config.yaml"))
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(outputDir, "b", "config.yaml"))
+	require.NoError(t, err)
+	assert.NotEqual(t, string(a), string(b))
+}
+
+func TestRun_StrictReportsUndefinedVariables(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"main.go.tmpl":    "package {{ .projcet_name }}\n",
+		"cmd/run.sh.tmpl": "# {{ .description | default \"none\" }}\n{{ .typo }}\n",
+	}
+	bpYAML := "apiVersion: v1\nname: strict-bp\nvariables:\n  - name: project_name\n    type: string\n    default: demo\n"
+
+	t.Run("flag", func(t *testing.T) {
+		t.Parallel()
+
+		registryDir := writeTestRegistry(t, bpYAML, files)
+		outputDir := filepath.Join(t.TempDir(), "out")
+
+		_, err := create.Run(&create.Opts{
+			BlueprintRef: "test/bp",
+			OutputDir:    outputDir,
+			RegistryDir:  registryDir,
+			UseDefaults:  true,
+			Strict:       true,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 undefined variable reference(s)")
+		assert.Contains(t, err.Error(), "main.go.tmpl:1:12: .projcet_name")
+		assert.Contains(t, err.Error(), filepath.Join("cmd", "run.sh.tmpl")+":2:4: .typo")
+		assert.NoDirExists(t, outputDir)
+	})
+
+	t.Run("blueprint option", func(t *testing.T) {
+		t.Parallel()
+
+		registryDir := writeTestRegistry(t, bpYAML+"strict: true\n", files)
+
+		_, err := create.Run(&create.Opts{
+			BlueprintRef: "test/bp",
+			OutputDir:    filepath.Join(t.TempDir(), "out"),
+			RegistryDir:  registryDir,
+			UseDefaults:  true,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "strict mode")
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		t.Parallel()
+
+		registryDir := writeTestRegistry(t, bpYAML, files)
+
+		_, err := create.Run(&create.Opts{
+			BlueprintRef: "test/bp",
+			OutputDir:    filepath.Join(t.TempDir(), "out"),
+			RegistryDir:  registryDir,
+			UseDefaults:  true,
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestRun_StrictAcceptsTestRegistry(t *testing.T) {
+	t.Parallel()
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "go/api",
+		OutputDir:    filepath.Join(t.TempDir(), "my-api"),
+		RegistryDir:  testRegistryDir,
+		UseDefaults:  true,
+		NoHooks:      true,
+		Strict:       true,
+		Overrides:    map[string]string{"project_name": "my-api"},
+	})
+	require.NoError(t, err)
+}
+
+func TestRun_GenerateFansOutOverList(t *testing.T) {
+	t.Parallel()
+
+	bpYAML := `apiVersion: v1
+name: gen-bp
+variables:
+  - name: project_name
+    type: string
+    default: demo
+  - name: commands
+    type: list
+    default: "serve,migrate"
+generate:
+  - template: _cmd/main.go.tmpl
+    each: commands
+    as: command
+    path: "cmd/{{ .command }}/main.go"
+`
+	registryDir := writeTestRegistry(t, bpYAML, map[string]string{
+		"_cmd/main.go.tmpl": "// {{ .project_name }} {{ .command }}\npackage main\n",
+		"README.md.tmpl":    "# {{ .project_name }}\n",
+	})
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	result, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.FilesCreated)
+
+	for _, cmd := range []string{"serve", "migrate"} {
+		content, err := os.ReadFile(filepath.Join(outputDir, "cmd", cmd, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "// demo "+cmd+"\npackage main\n", string(content))
+	}
+
+	assert.NoFileExists(t, filepath.Join(outputDir, "_cmd", "main.go"), "generator templates are not rendered on their own")
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+	require.Len(t, lock.Generated, 2)
+	assert.Equal(t, "cmd/serve/main.go", lock.Generated[0].Path)
+	assert.Equal(t, "_cmd/main.go.tmpl", lock.Generated[0].Template)
+	assert.Equal(t, "command", lock.Generated[0].As)
+	assert.Equal(t, "serve", lock.Generated[0].Item)
+	assert.Equal(t, "overwrite", lock.Generated[0].Strategy)
+	assert.NotEmpty(t, lock.Generated[0].Hash)
+}
+
+func TestRun_GenerateSeededUUIDsDifferPerItem(t *testing.T) {
+	t.Parallel()
+
+	bpYAML := "apiVersion: v1\nname: gen-bp\nvariables:\n  - name: commands\n    type: list\n    default: \"a,b\"\n" +
+		"generate:\n  - template: _cmd/id.txt.tmpl\n    each: commands\n    path: \"cmd/{{ .item }}/id.txt\"\n"
+	registryDir := writeTestRegistry(t, bpYAML, map[string]string{"_cmd/id.txt.tmpl": "id={{ uuidv4 }}\n"})
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		Seed:         "fixed",
+	})
+	require.NoError(t, err)
+
+	a, err := os.ReadFile(filepath.Join(outputDir, "cmd", "a", "id.txt"))
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(outputDir, "cmd", "b", "id.txt"))
+	require.NoError(t, err)
+	assert.NotEqual(t, string(a), string(b))
+}
+
+func TestRun_GenerateRejectsDuplicateOutputs(t *testing.T) {
+	t.Parallel()
+
+	bpYAML := "apiVersion: v1\nname: gen-bp\nvariables:\n  - name: commands\n    type: list\n    default: \"a,b\"\n" +
+		"generate:\n  - template: cmd.go.tmpl\n    each: commands\n    path: cmd/main.go\n"
+	registryDir := writeTestRegistry(t, bpYAML, map[string]string{"cmd.go.tmpl": "package main\n"})
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    filepath.Join(t.TempDir(), "out"),
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output cmd/main.go is also generated")
+}
+
+func TestRun_ComposedBlueprint(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	files := map[string]string{
+		"registry.yaml": "apiVersion: v1\nname: test\nblueprints:\n  - name: api\n    path: go/api\n",
+		"go/base/blueprint.yaml": "name: base\nvariables:\n  - name: project_name\n    type: string\n    default: demo\n" +
+			"sync:\n  managed_files:\n    - path: Makefile\n      strategy: overwrite\n",
+		"go/base/Makefile.tmpl":    "build: {{ .project_name }}\n",
+		"go/base/main.go":          "package base\n",
+		"ci/github/blueprint.yaml": "name: github\nvariables:\n  - name: ci_os\n    type: string\n    default: ubuntu-latest\n",
+		"ci/github/ci.yml.tmpl":    "runs-on: {{ .ci_os }}\n",
+		"go/api/blueprint.yaml":    "apiVersion: v1\nname: api\nextends: go/base\nincludes: [ci/github]\n",
+		"go/api/main.go":           "package api\n",
+	}
+
+	for rel, content := range files {
+		path := filepath.Join(registryDir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	result, err := create.Run(&create.Opts{
+		BlueprintRef: "go/api",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.FilesCreated)
+
+	for path, want := range map[string]string{
+		"Makefile": "build: demo\n",
+		"main.go":  "package api\n",
+		"ci.yml":   "runs-on: ubuntu-latest\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(outputDir, path))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content), path)
+	}
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go/base", "ci/github"}, lock.Blueprint.Composed)
+	assert.Empty(t, lock.Defaults, "files of composed blueprints are not inherited defaults")
+	require.Len(t, lock.ManagedFiles, 1)
+	assert.Equal(t, "Makefile", lock.ManagedFiles[0].Path)
+	assert.Equal(t, "go/base", lock.ManagedFiles[0].Blueprint)
+}
+
+func TestRun_LockfileRecordsRegistryCommit(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, "apiVersion: v1\nname: bp\nsync:\n  managed_files:\n    - path: Makefile\n      strategy: overwrite\n",
+		map[string]string{"Makefile": "all:\n"})
+
+	registryYAML := "apiVersion: v1\nname: test\nblueprints:\n  - name: test/bp\n    path: test/bp\n    latest_commit: abc123\n"
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "registry.yaml"), []byte(registryYAML), 0o644))
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc123", lock.Blueprint.Commit)
+	require.Len(t, lock.ManagedFiles, 1)
+	assert.Equal(t, "abc123", lock.ManagedFiles[0].SyncedCommit)
+}
+
+func TestRun_LockfileRecordsVersionConstraint(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, "apiVersion: v1\nname: bp\n", map[string]string{"README.md": "hello\n"})
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp@^1.0",
+		Ref:          "test/bp/v1.2.0",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+
+	assert.Equal(t, "^1.0", lock.Blueprint.Constraint)
+	assert.Equal(t, "test/bp/v1.2.0", lock.Blueprint.Ref)
+}
@@ -0,0 +1,30 @@
+package create
+
+import (
+	"github.com/donaldgifford/forge/internal/config"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// delimitersFor returns the template delimiters configured for a source
+// path: the first matching override, else the blueprint-wide delimiters.
+// A nil result means the default {{ }} delimiters.
+func delimitersFor(tc *config.TemplateConfig, relPath string) []string {
+	for _, o := range tc.Overrides {
+		if matchesFilePattern(relPath, []string{o.Pattern}) {
+			return o.Delimiters
+		}
+	}
+
+	return tc.Delimiters
+}
+
+// FileRenderer returns the renderer for a source path, honouring the
+// blueprint's template delimiters.
+func FileRenderer(renderer *tmpl.Renderer, tc *config.TemplateConfig, relPath string) *tmpl.Renderer {
+	delims := delimitersFor(tc, relPath)
+	if len(delims) != 2 {
+		return renderer
+	}
+
+	return renderer.WithDelimiters(delims[0], delims[1])
+}
@@ -0,0 +1,122 @@
+package create
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/donaldgifford/forge/internal/config"
+	"github.com/donaldgifford/forge/internal/defaults"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// defaultFileMode is used when a source file's mode is unknown.
+const defaultFileMode = 0o644
+
+// writeFile renders a single entry and writes it to the output directory.
+// Files keep their source permission bits, empty directories are created,
+// and symlinks are recreated when the blueprint opts in with
+// preserve_symlinks (otherwise the link target's content is copied).
+func writeFile(
+	renderer *tmpl.Renderer,
+	entry *defaults.FileEntry,
+	vars map[string]any,
+	outputDir string,
+	bp *config.Blueprint,
+) error {
+	renderedPath, err := outputPath(renderer, entry, vars, bp.Rename)
+	if err != nil {
+		return err
+	}
+
+	destPath := filepath.Join(outputDir, renderedPath)
+
+	// Ensure parent directory exists.
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", destPath, err)
+	}
+
+	switch {
+	case entry.Kind == defaults.KindDir:
+		return os.MkdirAll(destPath, entry.Mode|0o700)
+	case entry.Kind == defaults.KindSymlink && bp.PreserveSymlinks:
+		if err := checkSymlink(renderedPath, entry.LinkTarget); err != nil {
+			return err
+		}
+
+		return os.Symlink(entry.LinkTarget, destPath)
+	}
+
+	content, err := fileContent(renderer, entry, vars)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(destPath, content, sourceMode(entry))
+}
+
+// fileContent returns the output content for an entry: the rendered template,
+// or the source bytes copied verbatim. Seeded UUIDs are keyed on the entry's
+// relative path, which the lockfile records for sync and check.
+func fileContent(renderer *tmpl.Renderer, entry *defaults.FileEntry, vars map[string]any) ([]byte, error) {
+	if entry.IsTemplate {
+		content, err := renderer.WithSeedKey(entry.RelPath).RenderFile(entry.AbsPath, vars)
+		if err != nil {
+			return nil, fmt.Errorf("rendering template %s: %w", entry.AbsPath, err)
+		}
+
+		return content, nil
+	}
+
+	content, err := os.ReadFile(entry.AbsPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading source %s: %w", entry.AbsPath, err)
+	}
+
+	return content, nil
+}
+
+// sourceMode returns the permission bits to write an entry with. Symlinks
+// that are copied take the mode of the file they point to.
+func sourceMode(entry *defaults.FileEntry) os.FileMode {
+	if entry.Kind == defaults.KindSymlink {
+		if mode := defaults.SourceMode(entry.AbsPath); mode != 0 {
+			return mode
+		}
+	}
+
+	if entry.Mode == 0 {
+		return defaultFileMode
+	}
+
+	return entry.Mode
+}
+
+// checkSymlink rejects symlink targets that are absolute or that resolve
+// outside the project, relative to the link's own output path.
+func checkSymlink(linkPath, target string) error {
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("symlink %s -> %s: absolute targets are not allowed", linkPath, target)
+	}
+
+	resolved := filepath.Join(filepath.Dir(linkPath), target)
+	if resolved == ".." || strings.HasPrefix(resolved, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("symlink %s -> %s: target is outside the project", linkPath, target)
+	}
+
+	return nil
+}
+
+// writesRegularFile reports whether an entry is written as a regular file,
+// i.e. it is a file, or a symlink whose target content is copied.
+func writesRegularFile(entry *defaults.FileEntry, bp *config.Blueprint) bool {
+	switch entry.Kind {
+	case defaults.KindFile:
+		return true
+	case defaults.KindSymlink:
+		return !bp.PreserveSymlinks
+	default:
+		return false
+	}
+}
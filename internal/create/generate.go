@@ -0,0 +1,187 @@
+package create
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/donaldgifford/forge/internal/config"
+	"github.com/donaldgifford/forge/internal/defaults"
+	"github.com/donaldgifford/forge/internal/lockfile"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// defaultGenerateAs is the variable a generator's item is available as when
+// the generator does not name one.
+const defaultGenerateAs = "item"
+
+// generatedFile is one output of a generate rule: its template rendered with
+// one item of a list variable in scope.
+type generatedFile struct {
+	entry *defaults.FileEntry
+
+	// path is the rendered output path relative to the output directory.
+	path string
+
+	// as and item are the variable the item is available as, and its value.
+	as   string
+	item any
+
+	// vars are the template variables plus the item.
+	vars map[string]any
+
+	strategy string
+}
+
+// expandGenerators takes each generator's template out of the file set and
+// expands it into one generatedFile per item of its list variable. A
+// generator whose template was removed by a condition produces nothing.
+func expandGenerators(sc *scaffold) ([]generatedFile, error) {
+	var generated []generatedFile
+
+	seen := make(map[string]string)
+
+	for i := range sc.bp.Generate {
+		g := &sc.bp.Generate[i]
+
+		entry := sc.fileSet.Get(g.Template)
+		if entry == nil {
+			if wasExcluded(sc.excluded, g.Template) {
+				continue
+			}
+
+			return nil, fmt.Errorf("generate[%d]: template %q not found", i, g.Template)
+		}
+
+		if entry.Kind != defaults.KindFile {
+			return nil, fmt.Errorf("generate[%d]: template %q is not a regular file", i, g.Template)
+		}
+
+		sc.fileSet.Remove(g.Template)
+
+		files, err := expandGenerator(sc, g, entry)
+		if err != nil {
+			return nil, fmt.Errorf("generate[%d] (%s): %w", i, g.Template, err)
+		}
+
+		for _, f := range files {
+			if other, ok := seen[f.path]; ok {
+				return nil, fmt.Errorf("generate[%d] (%s): output %s is also generated from %s", i, g.Template, f.path, other)
+			}
+
+			seen[f.path] = g.Template
+		}
+
+		generated = append(generated, files...)
+	}
+
+	return generated, nil
+}
+
+// expandGenerator renders the output path of g for each item.
+func expandGenerator(sc *scaffold, g *config.Generator, entry *defaults.FileEntry) ([]generatedFile, error) {
+	items, err := listItems(sc.vars[g.Each])
+	if err != nil {
+		return nil, fmt.Errorf("variable %q: %w", g.Each, err)
+	}
+
+	as := g.As
+	if as == "" {
+		as = defaultGenerateAs
+	}
+
+	strategy := g.Strategy
+	if strategy == "" {
+		strategy = "overwrite"
+	}
+
+	renderer := FileRenderer(sc.renderer, &sc.bp.Template, g.Template)
+	files := make([]generatedFile, 0, len(items))
+
+	for _, item := range items {
+		vars := maps.Clone(sc.vars)
+		vars[as] = item
+
+		path, err := renderer.RenderPath(g.Path, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		if !filepath.IsLocal(path) {
+			return nil, fmt.Errorf("output path %q for item %v is not inside the project", path, item)
+		}
+
+		files = append(files, generatedFile{
+			entry:    entry,
+			path:     filepath.ToSlash(filepath.Clean(path)),
+			as:       as,
+			item:     item,
+			vars:     vars,
+			strategy: strategy,
+		})
+	}
+
+	return files, nil
+}
+
+// listItems returns the items of a list or multichoice value.
+func listItems(value any) ([]any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		return v, nil
+	case []string:
+		items := make([]any, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+
+		return items, nil
+	default:
+		return nil, fmt.Errorf("expected a list, got %T", value)
+	}
+}
+
+// wasExcluded reports whether a condition removed relPath.
+func wasExcluded(excluded []ExcludedFile, relPath string) bool {
+	return slices.ContainsFunc(excluded, func(e ExcludedFile) bool { return e.Path == relPath })
+}
+
+// writeGenerated renders a generated file into the output directory.
+func writeGenerated(renderer *tmpl.Renderer, f *generatedFile, outputDir string) error {
+	destPath := filepath.Join(outputDir, f.path)
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", destPath, err)
+	}
+
+	content, err := renderer.WithSeedKey(f.path).RenderFile(f.entry.AbsPath, f.vars)
+	if err != nil {
+		return fmt.Errorf("rendering template %s: %w", f.entry.AbsPath, err)
+	}
+
+	return os.WriteFile(destPath, content, sourceMode(f.entry))
+}
+
+// generatedEntries returns the lockfile entries for generated files.
+func generatedEntries(generated []generatedFile, bp *config.Blueprint) []lockfile.GeneratedEntry {
+	entries := make([]lockfile.GeneratedEntry, 0, len(generated))
+
+	for i := range generated {
+		f := &generated[i]
+		entries = append(entries, lockfile.GeneratedEntry{
+			Path:       f.path,
+			Template:   f.entry.RelPath,
+			Blueprint:  f.entry.Blueprint,
+			As:         f.as,
+			Item:       f.item,
+			Strategy:   f.strategy,
+			Delimiters: delimitersFor(&bp.Template, f.entry.RelPath),
+		})
+	}
+
+	return entries
+}
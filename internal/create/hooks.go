@@ -0,0 +1,57 @@
+package create
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/donaldgifford/forge/internal/hooks"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// renderHooks renders each hook command as a template so that hooks can
+// reference variables (e.g., "echo {{ .project_name }}"). Values are
+// shell-escaped (see tmpl.Renderer.RenderShell), so a value cannot inject
+// commands. Rendering happens before any files are written so a broken hook
+// fails the create early. Undefined variables are empty unless renderer is
+// strict, as in file templates.
+func renderHooks(renderer *tmpl.Renderer, cmds []string, vars map[string]any) ([]string, error) {
+	if len(cmds) == 0 {
+		return nil, nil
+	}
+
+	rendered := make([]string, 0, len(cmds))
+
+	for _, cmd := range cmds {
+		out, err := renderer.RenderShell(cmd, vars)
+		if err != nil {
+			return nil, fmt.Errorf("rendering post_create hook %q: %w", cmd, err)
+		}
+
+		rendered = append(rendered, out)
+	}
+
+	return rendered, nil
+}
+
+// runPostCreateHooks executes the rendered post-create hooks in the output
+// directory unless hooks are disabled. Hook failures are reported in the
+// returned results rather than failing the create — the files are already
+// written at this point.
+func runPostCreateHooks(ctx context.Context, opts *Opts, cmds []string, outputDir string, logger *slog.Logger) []hooks.Result {
+	if opts.NoHooks {
+		if len(cmds) > 0 {
+			logger.Debug("skipping post-create hooks", "count", len(cmds))
+		}
+
+		return nil
+	}
+
+	return hooks.Run(ctx, &hooks.Opts{
+		Hooks:   cmds,
+		WorkDir: outputDir,
+		Stdout:  opts.Stdout,
+		Stderr:  opts.Stderr,
+		Logger:  logger,
+	})
+}
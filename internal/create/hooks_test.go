@@ -0,0 +1,155 @@
+package create_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/create"
+)
+
+const hooksBlueprint = `apiVersion: v1
+name: hooks-bp
+variables:
+  - name: project_name
+    type: string
+    default: demo
+hooks:
+  post_create:
+    - "echo {{ .project_name }} > hook.txt"
+    - "echo streamed-output"
+    - "exit 2"
+`
+
+func TestRun_PostCreateHooks(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, hooksBlueprint, map[string]string{"README.md": "readme\n"})
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	var stdout bytes.Buffer
+
+	result, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		Stdout:       &stdout,
+		Stderr:       &bytes.Buffer{},
+	})
+	require.NoError(t, err, "a failing hook must not fail the create")
+
+	require.Len(t, result.Hooks, 3)
+	assert.Equal(t, "echo demo > hook.txt", result.Hooks[0].Command)
+	require.NoError(t, result.Hooks[0].Err)
+	require.NoError(t, result.Hooks[1].Err)
+	require.Error(t, result.Hooks[2].Err)
+
+	// Hooks run in the output directory with rendered commands.
+	content, err := os.ReadFile(filepath.Join(outputDir, "hook.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "demo\n", string(content))
+
+	assert.Contains(t, stdout.String(), "streamed-output")
+}
+
+func TestRun_NoHooksSkipsPostCreate(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, hooksBlueprint, map[string]string{"README.md": "readme\n"})
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	result, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		NoHooks:      true,
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Hooks)
+	assert.NoFileExists(t, filepath.Join(outputDir, "hook.txt"))
+}
+
+func TestRun_HookValuesAreShellEscaped(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeTestRegistry(t, hooksBlueprint, map[string]string{"README.md": "readme\n"})
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	result, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		Overrides:    map[string]string{"project_name": "x; touch injected"},
+		Stdout:       &bytes.Buffer{},
+		Stderr:       &bytes.Buffer{},
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, result.Hooks)
+	assert.Equal(t, "echo 'x; touch injected' > hook.txt", result.Hooks[0].Command)
+	assert.NoFileExists(t, filepath.Join(outputDir, "injected"))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "hook.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "x; touch injected\n", string(content))
+}
+
+func TestRun_HookWithUndefinedVariable(t *testing.T) {
+	t.Parallel()
+
+	bp := "apiVersion: v1\nname: optional-hooks\nhooks:\n  post_create:\n    - \"echo [{{ .description }}] > hook.txt\"\n"
+	registryDir := writeTestRegistry(t, bp, map[string]string{"README.md": "readme\n"})
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	result, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Hooks, 1)
+	require.NoError(t, result.Hooks[0].Err)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "hook.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", string(content))
+
+	// Strict mode reports the undefined variable instead.
+	_, err = create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    filepath.Join(t.TempDir(), "strict"),
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+		Strict:       true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "post_create hook")
+}
+
+func TestRun_InvalidHookTemplate(t *testing.T) {
+	t.Parallel()
+
+	bp := "apiVersion: v1\nname: bad-hooks\nhooks:\n  post_create:\n    - \"echo {{ .missing\"\n"
+	registryDir := writeTestRegistry(t, bp, map[string]string{"README.md": "readme\n"})
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	_, err := create.Run(&create.Opts{
+		BlueprintRef: "test/bp",
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		UseDefaults:  true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "post_create hook")
+	assert.NoDirExists(t, outputDir, "no files should be written when a hook cannot be rendered")
+}
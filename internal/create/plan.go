@@ -0,0 +1,248 @@
+package create
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/donaldgifford/forge/internal/defaults"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// Plan describes what a create would produce, as computed by a dry run.
+type Plan struct {
+	// OutputDir is the directory the project would be created in.
+	OutputDir string `json:"output_dir"`
+
+	// Files are the files that would be written, sorted by output path.
+	Files []PlannedFile `json:"files"`
+
+	// Excluded are the files removed by blueprint conditions.
+	Excluded []ExcludedFile `json:"excluded,omitempty"`
+
+	// Hooks are the rendered post-create hook commands that would run.
+	Hooks []string `json:"hooks,omitempty"`
+
+	// OutputDirNotEmpty is true if OutputDir exists and is not empty, so
+	// the create would fail without --force.
+	OutputDirNotEmpty bool `json:"output_dir_not_empty,omitempty"`
+}
+
+// PlannedFile is a single file in a Plan.
+type PlannedFile struct {
+	// Path is the output path relative to the output directory, after path
+	// rendering, rename rules and .tmpl stripping.
+	Path string `json:"path"`
+
+	// Source is the file's path relative to its source layer.
+	Source string `json:"source"`
+
+	// Layer is the defaults.SourceLayer the file comes from.
+	Layer string `json:"layer"`
+
+	// Template is true if the file content is rendered as a template.
+	Template bool `json:"template"`
+
+	// Binary is true if the file content looks binary and is copied verbatim.
+	Binary bool `json:"binary,omitempty"`
+
+	// Kind is "file", "dir" (an empty directory) or "symlink".
+	Kind string `json:"kind"`
+
+	// Target is the link target of a symlink.
+	Target string `json:"target,omitempty"`
+}
+
+// ExcludedFile is a file removed by a blueprint condition.
+type ExcludedFile struct {
+	// Path is the file's path relative to its source layer.
+	Path string `json:"path"`
+
+	// When is the condition expression that excluded the file.
+	When string `json:"when"`
+}
+
+// buildPlan computes output paths for every entry, renders every template in
+// memory and checks symlinks, so that a dry run fails on the same errors a
+// real create would.
+func buildPlan(sc *scaffold) (*Plan, error) {
+	plan := &Plan{
+		OutputDir: sc.outputDir,
+		Excluded:  sc.excluded,
+		Hooks:     sc.hooks,
+	}
+
+	for _, entry := range sc.fileSet.Entries() {
+		planned, err := planFile(FileRenderer(sc.renderer, &sc.bp.Template, entry.RelPath), entry, sc)
+		if err != nil {
+			return nil, fmt.Errorf("planning file %s: %w", entry.RelPath, err)
+		}
+
+		plan.Files = append(plan.Files, planned)
+	}
+
+	for i := range sc.generated {
+		f := &sc.generated[i]
+
+		renderer := FileRenderer(sc.renderer, &sc.bp.Template, f.entry.RelPath)
+		if _, err := renderer.WithSeedKey(f.path).RenderFile(f.entry.AbsPath, f.vars); err != nil {
+			return nil, fmt.Errorf("planning generated file %s: rendering template %s: %w", f.path, f.entry.AbsPath, err)
+		}
+
+		plan.Files = append(plan.Files, PlannedFile{
+			Path:     f.path,
+			Source:   f.entry.RelPath,
+			Layer:    f.entry.SourceLayer.String(),
+			Template: true,
+			Kind:     defaults.KindFile.String(),
+		})
+	}
+
+	sort.Slice(plan.Files, func(i, j int) bool {
+		return plan.Files[i].Path < plan.Files[j].Path
+	})
+
+	return plan, nil
+}
+
+func planFile(renderer *tmpl.Renderer, entry *defaults.FileEntry, sc *scaffold) (PlannedFile, error) {
+	path, err := outputPath(renderer, entry, sc.vars, sc.bp.Rename)
+	if err != nil {
+		return PlannedFile{}, err
+	}
+
+	planned := PlannedFile{
+		Path:     path,
+		Source:   entry.RelPath,
+		Layer:    entry.SourceLayer.String(),
+		Template: entry.IsTemplate,
+		Binary:   entry.IsBinary,
+		Kind:     defaults.KindFile.String(),
+	}
+
+	switch {
+	case entry.Kind == defaults.KindDir:
+		planned.Kind = entry.Kind.String()
+	case entry.Kind == defaults.KindSymlink && sc.bp.PreserveSymlinks:
+		if err := checkSymlink(path, entry.LinkTarget); err != nil {
+			return PlannedFile{}, err
+		}
+
+		planned.Kind = entry.Kind.String()
+		planned.Target = entry.LinkTarget
+		planned.Template, planned.Binary = false, false
+	case entry.IsTemplate:
+		if _, err := renderer.RenderFile(entry.AbsPath, sc.vars); err != nil {
+			return PlannedFile{}, fmt.Errorf("rendering template %s: %w", entry.AbsPath, err)
+		}
+	}
+
+	return planned, nil
+}
+
+// RenderPlan writes a plan to w in the given format ("text" or "json").
+// The text format is a tree of output paths annotated with each file's
+// source layer and whether it is a template.
+func RenderPlan(w io.Writer, format string, plan *Plan) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(plan)
+	default:
+		return renderPlanText(w, plan)
+	}
+}
+
+func renderPlanText(w io.Writer, plan *Plan) error {
+	if _, err := fmt.Fprintf(w, "%s/\n", strings.TrimSuffix(plan.OutputDir, "/")); err != nil {
+		return err
+	}
+
+	if err := renderTree(w, plan.Files); err != nil {
+		return err
+	}
+
+	if len(plan.Excluded) > 0 {
+		if _, err := fmt.Fprintln(w, "\nExcluded by conditions:"); err != nil {
+			return err
+		}
+
+		for _, e := range plan.Excluded {
+			if _, err := fmt.Fprintf(w, "  %s (when %s)\n", e.Path, e.When); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(plan.Hooks) > 0 {
+		if _, err := fmt.Fprintln(w, "\nPost-create hooks (not run):"); err != nil {
+			return err
+		}
+
+		for _, h := range plan.Hooks {
+			if _, err := fmt.Fprintf(w, "  %s\n", h); err != nil {
+				return err
+			}
+		}
+	}
+
+	if plan.OutputDirNotEmpty {
+		if _, err := fmt.Fprintf(w, "\nOutput directory %s is not empty — use --force to overwrite.\n", plan.OutputDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderTree prints sorted file paths as an indented tree, one directory
+// level per indent, annotating each file with its layer and template flag.
+func renderTree(w io.Writer, files []PlannedFile) error {
+	var printed []string // directory components printed for the previous file
+
+	for _, f := range files {
+		parts := strings.Split(f.Path, "/")
+		dirs := parts[:len(parts)-1]
+
+		// Skip directory lines shared with the previous file.
+		common := 0
+		for common < len(dirs) && common < len(printed) && dirs[common] == printed[common] {
+			common++
+		}
+
+		for depth := common; depth < len(dirs); depth++ {
+			if _, err := fmt.Fprintf(w, "%s%s/\n", indent(depth+1), dirs[depth]); err != nil {
+				return err
+			}
+		}
+
+		printed = dirs
+
+		annotation := f.Layer
+
+		switch {
+		case f.Template:
+			annotation += ", template"
+		case f.Binary:
+			annotation += ", binary"
+		case f.Kind == "dir":
+			annotation += ", empty dir"
+		case f.Kind == "symlink":
+			annotation += ", symlink -> " + f.Target
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%s  (%s)\n", indent(len(dirs)+1), parts[len(parts)-1], annotation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
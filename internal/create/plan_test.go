@@ -0,0 +1,75 @@
+package create_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/create"
+)
+
+func samplePlan() *create.Plan {
+	return &create.Plan{
+		OutputDir: "my-api",
+		Files: []create.PlannedFile{
+			{Path: ".editorconfig", Source: ".editorconfig", Layer: "registry-default"},
+			{Path: "cmd/api/main.go", Source: "cmd/api/main.go.tmpl", Layer: "blueprint", Template: true},
+			{Path: "cmd/worker/main.go", Source: "cmd/worker/main.go", Layer: "blueprint"},
+			{Path: "go.mod", Source: "go.mod.tmpl", Layer: "blueprint", Template: true},
+		},
+		Excluded: []create.ExcludedFile{{Path: "Dockerfile", When: `{{ eq .use_docker "false" }}`}},
+		Hooks:    []string{"go mod tidy"},
+	}
+}
+
+func TestRenderPlan_Text(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, create.RenderPlan(&buf, "text", samplePlan()))
+
+	want := `my-api/
+  .editorconfig  (registry-default)
+  cmd/
+    api/
+      main.go  (blueprint, template)
+    worker/
+      main.go  (blueprint)
+  go.mod  (blueprint, template)
+
+Excluded by conditions:
+  Dockerfile (when {{ eq .use_docker "false" }})
+
+Post-create hooks (not run):
+  go mod tidy
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestRenderPlan_JSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, create.RenderPlan(&buf, "json", samplePlan()))
+
+	var got create.Plan
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, samplePlan(), &got)
+}
+
+func TestRenderPlan_TextNonEmptyOutputDir(t *testing.T) {
+	t.Parallel()
+
+	plan := &create.Plan{
+		OutputDir:         "my-api",
+		Files:             []create.PlannedFile{{Path: "go.mod", Source: "go.mod", Layer: "blueprint"}},
+		OutputDirNotEmpty: true,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, create.RenderPlan(&buf, "text", plan))
+	assert.Contains(t, buf.String(), "\nOutput directory my-api is not empty — use --force to overwrite.\n")
+}
@@ -0,0 +1,77 @@
+package create
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// stagingPattern names the temporary directory a project is rendered into
+// before it is moved into place.
+const stagingPattern = ".forge-create-*"
+
+// newStagingDir creates an empty staging directory next to outputDir, so the
+// final move is a rename on the same filesystem. outputDir is made absolute
+// first: the parent of "." is "." itself, the directory being written to.
+func newStagingDir(outputDir string) (string, error) {
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving output directory %s: %w", outputDir, err)
+	}
+
+	parent := filepath.Dir(absOutputDir)
+
+	if err := os.MkdirAll(parent, 0o750); err != nil {
+		return "", fmt.Errorf("creating parent directory %s: %w", parent, err)
+	}
+
+	staging, err := os.MkdirTemp(parent, stagingPattern)
+	if err != nil {
+		return "", fmt.Errorf("creating staging directory: %w", err)
+	}
+
+	return staging, nil
+}
+
+// commitStaging moves the rendered project from staging into outputDir.
+// When outputDir does not exist the staging directory is renamed in a single
+// step. Otherwise (an empty directory, or --force) each staged file is renamed
+// over its target; no file is touched until every file has rendered.
+func commitStaging(staging, outputDir string) error {
+	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+		if err := os.Chmod(staging, 0o750); err != nil {
+			return fmt.Errorf("setting permissions on %s: %w", staging, err)
+		}
+
+		if err := os.Rename(staging, outputDir); err != nil {
+			return fmt.Errorf("moving project into %s: %w", outputDir, err)
+		}
+
+		return nil
+	}
+
+	err := filepath.WalkDir(staging, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+
+		dest := filepath.Join(outputDir, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(dest, 0o750)
+		}
+
+		return os.Rename(path, dest)
+	})
+	if err != nil {
+		return fmt.Errorf("moving project into %s: %w", outputDir, err)
+	}
+
+	return os.RemoveAll(staging)
+}
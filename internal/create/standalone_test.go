@@ -0,0 +1,143 @@
+package create_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/check"
+	"github.com/donaldgifford/forge/internal/create"
+	"github.com/donaldgifford/forge/internal/getter"
+	"github.com/donaldgifford/forge/internal/lockfile"
+	"github.com/donaldgifford/forge/internal/registry"
+	forgesync "github.com/donaldgifford/forge/internal/sync"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test",
+		"GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=test",
+		"GIT_COMMITTER_EMAIL=test@test.com",
+	)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v failed: %s", args, out)
+}
+
+// writeStandaloneRepo creates a standalone blueprint repository and a bare
+// clone of it to serve as the remote. It returns both.
+func writeStandaloneRepo(t *testing.T) (work, bare string) {
+	t.Helper()
+
+	root := t.TempDir()
+	work = filepath.Join(root, "work")
+	bare = filepath.Join(root, "blueprint.git")
+
+	files := map[string]string{
+		"blueprint.yaml": `apiVersion: v1
+name: solo
+variables:
+  - name: project_name
+    type: string
+    default: demo
+sync:
+  managed_files:
+    - path: Makefile
+      strategy: overwrite
+`,
+		"Makefile":                "all:\n\techo v1\n",
+		"README.md.tmpl":          "# {{ .project_name }}\n",
+		"_defaults/.editorconfig": "root = true\n",
+	}
+
+	for rel, content := range files {
+		path := filepath.Join(work, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	runGit(t, work, "init")
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "-m", "v1")
+	runGit(t, root, "clone", "--bare", work, bare)
+
+	return work, bare
+}
+
+// fetchStandalone fetches the standalone blueprint ref as forge create does.
+func fetchStandalone(t *testing.T, ref string) (dir, registryURL string) {
+	t.Helper()
+
+	resolved, err := registry.Resolve(ref, "")
+	require.NoError(t, err)
+	require.True(t, resolved.Standalone)
+
+	dir = t.TempDir()
+	require.NoError(t, getter.New(nil).Fetch(context.Background(), resolved.GetterURL(), dir, getter.FetchOpts{}))
+
+	return dir, resolved.GetterURL()
+}
+
+func TestRun_StandaloneBlueprintLifecycle(t *testing.T) {
+	t.Parallel()
+
+	work, bare := writeStandaloneRepo(t)
+	ref := "file://" + bare
+
+	registryDir, registryURL := fetchStandalone(t, ref)
+	outputDir := filepath.Join(t.TempDir(), "out")
+
+	result, err := create.Run(&create.Opts{
+		BlueprintRef: ref,
+		OutputDir:    outputDir,
+		RegistryDir:  registryDir,
+		RegistryURL:  registryURL,
+		UseDefaults:  true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "solo", result.Blueprint)
+
+	readme, err := os.ReadFile(filepath.Join(outputDir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# demo\n", string(readme))
+	assert.FileExists(t, filepath.Join(outputDir, ".editorconfig"))
+	assert.NoDirExists(t, filepath.Join(outputDir, ".git"))
+	assert.NoFileExists(t, filepath.Join(outputDir, "blueprint.yaml"))
+
+	lock, err := lockfile.Read(filepath.Join(outputDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "git::"+ref, lock.Blueprint.RegistryURL)
+	assert.Empty(t, lock.Blueprint.Path)
+	assert.NotEmpty(t, lock.Blueprint.Commit)
+
+	checked, err := check.Run(&check.Opts{ProjectDir: outputDir, RegistryDir: registryDir, Writer: io.Discard})
+	require.NoError(t, err)
+	require.Len(t, checked.ManagedUpdates, 1)
+	assert.Equal(t, check.StatusUpToDate, checked.ManagedUpdates[0].Status)
+
+	// Publish a new version of the blueprint and sync to it.
+	require.NoError(t, os.WriteFile(filepath.Join(work, "Makefile"), []byte("all:\n\techo v2\n"), 0o644))
+	runGit(t, work, "commit", "-am", "v2")
+	runGit(t, work, "push", bare, "HEAD")
+
+	registryDir, _ = fetchStandalone(t, ref)
+
+	synced, err := forgesync.Run(&forgesync.Opts{ProjectDir: outputDir, RegistryDir: registryDir})
+	require.NoError(t, err)
+	assert.Len(t, synced.Updated, 1)
+
+	makefile, err := os.ReadFile(filepath.Join(outputDir, "Makefile"))
+	require.NoError(t, err)
+	assert.Equal(t, "all:\n\techo v2\n", string(makefile))
+}
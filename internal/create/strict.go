@@ -0,0 +1,67 @@
+package create
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/donaldgifford/forge/internal/defaults"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// checkTemplates checks every template file for undefined variables before
+// anything is rendered, so strict mode reports all of them at once rather
+// than stopping at the first file.
+func checkTemplates(sc *scaffold) error {
+	var refs []tmpl.UndefinedRef
+
+	check := func(entry *defaults.FileEntry, vars map[string]any) error {
+		content, err := os.ReadFile(entry.AbsPath)
+		if err != nil {
+			return fmt.Errorf("reading template %s: %w", entry.AbsPath, err)
+		}
+
+		renderer := FileRenderer(sc.renderer, &sc.bp.Template, entry.RelPath)
+
+		var undef *tmpl.UndefinedError
+
+		err = renderer.Check(entry.RelPath, string(content), vars)
+		switch {
+		case errors.As(err, &undef):
+			refs = append(refs, undef.Refs...)
+		case err != nil:
+			return fmt.Errorf("checking template %s: %w", entry.RelPath, err)
+		}
+
+		return nil
+	}
+
+	for _, entry := range sc.fileSet.Entries() {
+		if !entry.IsTemplate || !writesRegularFile(entry, sc.bp) {
+			continue
+		}
+
+		if err := check(entry, sc.vars); err != nil {
+			return err
+		}
+	}
+
+	// A generator's template is checked once; the item is defined for every
+	// output.
+	for i := range sc.generated {
+		f := &sc.generated[i]
+		if i > 0 && sc.generated[i-1].entry == f.entry {
+			continue
+		}
+
+		if err := check(f.entry, f.vars); err != nil {
+			return err
+		}
+	}
+
+	if len(refs) > 0 {
+		return fmt.Errorf("strict mode: %w", &tmpl.UndefinedError{Refs: refs})
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package create
+
+import (
+	"path"
+	"strings"
+
+	"github.com/donaldgifford/forge/internal/defaults"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// ApplyCopyWithoutRender marks entries matching any copy_without_render
+// pattern as non-templates so they are copied verbatim.
+func ApplyCopyWithoutRender(patterns []string, fileSet *defaults.FileSet) {
+	if len(patterns) == 0 {
+		return
+	}
+
+	for _, entry := range fileSet.Entries() {
+		if entry.IsTemplate && matchesFilePattern(entry.RelPath, patterns) {
+			entry.IsTemplate = false
+		}
+	}
+}
+
+// matchesFilePattern reports whether relPath matches a copy_without_render or
+// template override pattern. Patterns are matched against the path with and without its .tmpl
+// extension, and a pattern without a slash also matches the base name in any
+// directory (e.g., "*.svg").
+func matchesFilePattern(relPath string, patterns []string) bool {
+	for _, candidate := range []string{relPath, tmpl.StripTemplateExtension(relPath)} {
+		if matchesAnyPattern(candidate, patterns) {
+			return true
+		}
+
+		for _, pattern := range patterns {
+			if !strings.Contains(pattern, "/") {
+				if matched, err := path.Match(pattern, path.Base(candidate)); err == nil && matched {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// isVerbatim reports whether an entry with a .tmpl name is copied without
+// rendering, either because it matched copy_without_render or because its
+// content is binary. Sync and check need this to reproduce the output.
+func isVerbatim(entry *defaults.FileEntry) bool {
+	return tmpl.IsTemplate(entry.RelPath) && !entry.IsTemplate
+}
@@ -0,0 +1,108 @@
+package defaults
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// partialsDirName holds template snippets shared by every template below it.
+const partialsDirName = "_partials"
+
+// ResolvePartials collects the template partials available to a blueprint
+// and returns their contents keyed by name. A partial's name is its path
+// inside _partials/ without the .tmpl extension, so
+// _partials/ci/go-job.tmpl is available as "ci/go-job".
+//
+// Partials are layered like Resolve (last wins):
+//  1. /<registryRoot>/_partials/
+//  2. /<registryRoot>/<category>/_partials/ for each path segment
+//  3. /<registryRoot>/<blueprintPath>/_partials/
+//
+// For a composed blueprint, pass every contributing blueprint path, lowest
+// precedence first: each layer is then walked for all of them in order, so
+// a blueprint's own partials beat any category partial.
+//
+// An empty registryRoot has no partials.
+func ResolvePartials(registryRoot string, blueprintPaths ...string) (map[string]string, error) {
+	files, err := PartialFiles(registryRoot, blueprintPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	partials := make(map[string]string, len(files))
+
+	for name, path := range files {
+		content, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, fmt.Errorf("reading partial %s: %w", path, err)
+		}
+
+		partials[name] = string(content)
+	}
+
+	return partials, nil
+}
+
+// PartialFiles is like ResolvePartials but returns the path of the file
+// that wins for each partial name instead of its content.
+func PartialFiles(registryRoot string, blueprintPaths ...string) (map[string]string, error) {
+	files := make(map[string]string)
+	if registryRoot == "" {
+		return files, nil
+	}
+
+	dirs := []string{filepath.Join(registryRoot, partialsDirName)}
+
+	for _, blueprintPath := range blueprintPaths {
+		segments := strings.Split(blueprintPath, "/")
+		for i := range len(segments) - 1 {
+			dir := filepath.Join(registryRoot, filepath.Join(segments[:i+1]...), partialsDirName)
+			if !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	for _, blueprintPath := range blueprintPaths {
+		dirs = append(dirs, filepath.Join(registryRoot, blueprintPath, partialsDirName))
+	}
+
+	for _, dir := range dirs {
+		if err := collectPartials(dir, files); err != nil {
+			return nil, fmt.Errorf("collecting partials at %s: %w", dir, err)
+		}
+	}
+
+	return files, nil
+}
+
+// collectPartials records the path of every file under dir in files,
+// replacing any partial of the same name from an earlier layer.
+func collectPartials(dir string, files map[string]string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("computing relative path for %s: %w", path, err)
+		}
+
+		name := strings.TrimSuffix(filepath.ToSlash(relPath), ".tmpl")
+		files[name] = path
+
+		return nil
+	})
+}
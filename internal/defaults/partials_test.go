@@ -0,0 +1,79 @@
+package defaults_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/defaults"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestResolvePartials_Layering(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "_partials", "license-header.tmpl"), "// root license")
+	writeFile(t, filepath.Join(root, "_partials", "ci", "job.tmpl"), "root job")
+	writeFile(t, filepath.Join(root, "go", "_partials", "ci", "job.tmpl"), "go job")
+	writeFile(t, filepath.Join(root, "go", "api", "_partials", "banner.txt"), "api banner")
+
+	partials, err := defaults.ResolvePartials(root, "go/api")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"license-header": "// root license",
+		"ci/job":         "go job",
+		"banner.txt":     "api banner",
+	}, partials)
+}
+
+func TestResolvePartials_NoneDefined(t *testing.T) {
+	t.Parallel()
+
+	partials, err := defaults.ResolvePartials(t.TempDir(), "go/api")
+	require.NoError(t, err)
+	assert.Empty(t, partials)
+}
+
+func TestResolve_SkipsPartials(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "test", "bp", "_partials", "header.tmpl"), "header")
+	writeFile(t, filepath.Join(root, "test", "bp", "main.go.tmpl"), "{{ include \"header\" . }}")
+
+	fs, err := defaults.Resolve(root, "test/bp", nil)
+	require.NoError(t, err)
+
+	assert.NotNil(t, fs.Get("main.go.tmpl"))
+	assert.Nil(t, fs.Get(filepath.Join("_partials", "header.tmpl")))
+	assert.Equal(t, 1, fs.Len())
+}
+
+func TestResolvePartials_Composed(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "_partials", "job.tmpl"), "root job")
+	writeFile(t, filepath.Join(root, "go", "_partials", "job.tmpl"), "go job")
+	writeFile(t, filepath.Join(root, "go", "base", "_partials", "header.tmpl"), "base header")
+	writeFile(t, filepath.Join(root, "ci", "github", "_partials", "header.tmpl"), "ci header")
+
+	partials, err := defaults.ResolvePartials(root, "go/base", "ci/github")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"job":    "go job",
+		"header": "ci header",
+	}, partials)
+}
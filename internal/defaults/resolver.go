@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
 )
 
 // SourceLayer identifies where a file originates in the inheritance chain.
@@ -36,8 +38,41 @@ func (s SourceLayer) String() string {
 	}
 }
 
+// EntryKind identifies what a FileEntry produces in the output.
+type EntryKind int
+
+const (
+	// KindFile is a regular file.
+	KindFile EntryKind = iota
+
+	// KindDir is a directory that is created even though it holds no files.
+	KindDir
+
+	// KindSymlink is a symbolic link.
+	KindSymlink
+)
+
+// String returns a human-readable name for the entry kind.
+func (k EntryKind) String() string {
+	switch k {
+	case KindFile:
+		return "file"
+	case KindDir:
+		return "dir"
+	case KindSymlink:
+		return "symlink"
+	default:
+		return "unknown"
+	}
+}
+
 const defaultsDirName = "_defaults"
 
+// KeepFileName marks a directory that should be created in the output even
+// when it has no other files. The marker itself is not copied. It lets
+// registries stored in git (which cannot track empty directories) ship them.
+const KeepFileName = ".forgekeep"
+
 // FileEntry represents a single file in the resolved file set.
 type FileEntry struct {
 	// AbsPath is the absolute path to the source file on disk.
@@ -49,8 +84,28 @@ type FileEntry struct {
 	// SourceLayer tracks where this file came from in the inheritance chain.
 	SourceLayer SourceLayer
 
-	// IsTemplate is true if the file ends with .tmpl.
+	// IsTemplate is true if the file ends with .tmpl and is not binary.
+	// For a symlink it describes the file the link points to, and applies
+	// when the link is copied rather than preserved.
 	IsTemplate bool
+
+	// IsBinary is true if the file content looks binary. Binary files are
+	// always copied verbatim.
+	IsBinary bool
+
+	// Kind is what the entry produces in the output.
+	Kind EntryKind
+
+	// Mode holds the permission bits of the source file or directory.
+	Mode os.FileMode
+
+	// LinkTarget is the target of a KindSymlink entry, as stored in the link.
+	LinkTarget string
+
+	// Blueprint is the path of the extended or included blueprint a
+	// LayerBlueprint entry comes from. It is empty for the files of the
+	// blueprint being resolved and for defaults.
+	Blueprint string
 }
 
 // FileSet is an ordered collection of files from the resolved inheritance chain.
@@ -144,8 +199,49 @@ func Resolve(registryRoot, blueprintPath string, exclusions []string) (*FileSet,
 	return fs, nil
 }
 
-// collectFiles walks a directory and adds all regular files to the FileSet.
-// The _defaults directory name is skipped when collecting blueprint files.
+// ResolveComposed resolves the file set of a blueprint composed from
+// several (see the compose package). blueprintPaths lists every contributing
+// blueprint, lowest precedence first and ending with the blueprint itself.
+//
+// Each blueprint is resolved as by Resolve and the results are merged:
+// a file from any blueprint directory beats an inherited default, a
+// category default beats a registry default, and within a layer the later
+// blueprint wins. Files listed in exclusions are removed from the result.
+func ResolveComposed(registryRoot string, blueprintPaths, exclusions []string) (*FileSet, error) {
+	merged := NewFileSet()
+	last := len(blueprintPaths) - 1
+
+	for i, blueprintPath := range blueprintPaths {
+		fs, err := Resolve(registryRoot, blueprintPath, nil)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", blueprintPath, err)
+		}
+
+		for _, entry := range fs.Entries() {
+			if existing := merged.Get(entry.RelPath); existing != nil && existing.SourceLayer > entry.SourceLayer {
+				continue
+			}
+
+			if entry.SourceLayer == LayerBlueprint && i != last {
+				entry.Blueprint = blueprintPath
+			}
+
+			merged.Add(entry)
+		}
+	}
+
+	for _, excl := range exclusions {
+		merged.Remove(excl)
+	}
+
+	return merged, nil
+}
+
+// collectFiles walks a directory and adds its regular files, symlinks and
+// empty directories to the FileSet. A directory holding a .forgekeep marker
+// is added as a directory entry; the marker itself is not collected.
+// The _defaults directory name is skipped when collecting blueprint files,
+// and _partials directories and git metadata are skipped everywhere.
 // The blueprint.yaml file is also skipped as it's metadata, not output content.
 func collectFiles(dir string, fs *FileSet, layer SourceLayer) error {
 	info, err := os.Stat(dir)
@@ -172,12 +268,26 @@ func collectFiles(dir string, fs *FileSet, layer SourceLayer) error {
 			return filepath.SkipDir
 		}
 
-		if info.IsDir() {
+		// Partials are only available to templates, never output.
+		if info.IsDir() && info.Name() == partialsDirName {
+			return filepath.SkipDir
+		}
+
+		// A standalone blueprint is the root of its git repository.
+		if info.Name() == ".git" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if path == dir {
 			return nil
 		}
 
 		// Skip blueprint.yaml — it's config, not output content.
-		if info.Name() == "blueprint.yaml" {
+		if !info.IsDir() && info.Name() == "blueprint.yaml" {
 			return nil
 		}
 
@@ -186,13 +296,83 @@ func collectFiles(dir string, fs *FileSet, layer SourceLayer) error {
 			return fmt.Errorf("computing relative path for %s: %w", path, err)
 		}
 
-		fs.Add(&FileEntry{
+		return addEntry(fs, path, relPath, info, layer)
+	})
+}
+
+// addEntry adds the FileEntry for a walked path. Non-empty directories and
+// special files (sockets, devices) produce no entry of their own.
+func addEntry(set *FileSet, path, relPath string, info os.FileInfo, layer SourceLayer) error {
+	mode := info.Mode()
+
+	switch {
+	case mode.IsDir():
+		children, err := os.ReadDir(path)
+		if err != nil {
+			return fmt.Errorf("reading directory %s: %w", path, err)
+		}
+
+		if len(children) == 0 {
+			set.Add(&FileEntry{AbsPath: path, RelPath: relPath, SourceLayer: layer, Kind: KindDir, Mode: mode.Perm()})
+		}
+
+	case info.Name() == KeepFileName:
+		relDir := filepath.Dir(relPath)
+		if relDir == "." {
+			return nil
+		}
+
+		dirInfo, err := os.Stat(filepath.Dir(path))
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", filepath.Dir(path), err)
+		}
+
+		set.Add(&FileEntry{
+			AbsPath:     filepath.Dir(path),
+			RelPath:     relDir,
+			SourceLayer: layer,
+			Kind:        KindDir,
+			Mode:        dirInfo.Mode().Perm(),
+		})
+
+	case mode&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return fmt.Errorf("reading symlink %s: %w", path, err)
+		}
+
+		entry := &FileEntry{AbsPath: path, RelPath: relPath, SourceLayer: layer, Kind: KindSymlink, LinkTarget: target}
+
+		// A link that is not preserved is copied as the file it points to,
+		// and rendered like it.
+		if targetInfo, err := os.Stat(path); err == nil && targetInfo.Mode().IsRegular() {
+			binary, err := tmpl.IsBinaryFile(path)
+			if err != nil {
+				return err
+			}
+
+			entry.IsTemplate = tmpl.IsTemplate(path) && !binary
+			entry.IsBinary = binary
+		}
+
+		set.Add(entry)
+
+	case mode.IsRegular():
+		binary, err := tmpl.IsBinaryFile(path)
+		if err != nil {
+			return err
+		}
+
+		set.Add(&FileEntry{
 			AbsPath:     path,
 			RelPath:     relPath,
 			SourceLayer: layer,
-			IsTemplate:  strings.HasSuffix(path, ".tmpl"),
+			IsTemplate:  tmpl.IsTemplate(path) && !binary,
+			IsBinary:    binary,
+			Kind:        KindFile,
+			Mode:        mode.Perm(),
 		})
+	}
 
-		return nil
-	})
+	return nil
 }
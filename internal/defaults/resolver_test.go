@@ -1,6 +1,7 @@
 package defaults_test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -168,3 +169,87 @@ func TestSourceLayer_String(t *testing.T) {
 	assert.Equal(t, "category-default", defaults.LayerCategoryDefault.String())
 	assert.Equal(t, "blueprint", defaults.LayerBlueprint.String())
 }
+
+func TestResolve_CarriesFileModes(t *testing.T) {
+	t.Parallel()
+
+	fs, err := defaults.Resolve(testRegistryRoot, "go/api", nil)
+	require.NoError(t, err)
+
+	entry := fs.Get("scripts/lint.sh")
+	require.NotNil(t, entry)
+	assert.Equal(t, defaults.KindFile, entry.Kind)
+	assert.NotZero(t, entry.Mode&0o100, "lint.sh should keep its executable bit")
+}
+
+func TestResolve_DirectoriesAndSymlinks(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	bpDir := filepath.Join(root, "test", "bp")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(bpDir, "logs"), 0o750))
+	require.NoError(t, os.MkdirAll(filepath.Join(bpDir, "tmp", "cache"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(bpDir, "tmp", "cache", defaults.KeepFileName), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(bpDir, "README.md"), []byte("readme"), 0o644))
+	require.NoError(t, os.Symlink("README.md", filepath.Join(bpDir, "README")))
+	require.NoError(t, os.WriteFile(filepath.Join(bpDir, "name.txt.tmpl"), []byte("{{ .name }}"), 0o644))
+	require.NoError(t, os.Symlink("name.txt.tmpl", filepath.Join(bpDir, "alias.txt.tmpl")))
+
+	fs, err := defaults.Resolve(root, "test/bp", nil)
+	require.NoError(t, err)
+
+	logs := fs.Get("logs")
+	require.NotNil(t, logs, "empty directory should be collected")
+	assert.Equal(t, defaults.KindDir, logs.Kind)
+
+	cache := fs.Get(filepath.Join("tmp", "cache"))
+	require.NotNil(t, cache, "directory with a keep marker should be collected")
+	assert.Equal(t, defaults.KindDir, cache.Kind)
+	assert.Nil(t, fs.Get(filepath.Join("tmp", "cache", defaults.KeepFileName)))
+
+	link := fs.Get("README")
+	require.NotNil(t, link)
+	assert.Equal(t, defaults.KindSymlink, link.Kind)
+	assert.Equal(t, "README.md", link.LinkTarget)
+	assert.False(t, link.IsTemplate)
+
+	// A link to a template is rendered like it when copied.
+	alias := fs.Get("alias.txt.tmpl")
+	require.NotNil(t, alias)
+	assert.Equal(t, defaults.KindSymlink, alias.Kind)
+	assert.True(t, alias.IsTemplate)
+}
+
+func TestResolveComposed_Precedence(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "_defaults", ".golangci.yml"), "root")
+	writeFile(t, filepath.Join(root, "go", "_defaults", ".golangci.yml"), "go")
+	writeFile(t, filepath.Join(root, "go", "base", "Makefile"), "base make")
+	writeFile(t, filepath.Join(root, "go", "base", "main.go"), "base main")
+	writeFile(t, filepath.Join(root, "ci", "github", ".github", "ci.yml"), "ci")
+	writeFile(t, filepath.Join(root, "ci", "github", "Makefile"), "ci make")
+	writeFile(t, filepath.Join(root, "go", "api", "main.go"), "api main")
+
+	fs, err := defaults.ResolveComposed(root, []string{"go/base", "ci/github", "go/api"}, []string{".github/ci.yml"})
+	require.NoError(t, err)
+
+	// The root default resolved again for ci/github does not beat go/_defaults.
+	golangci := fs.Get(".golangci.yml")
+	require.NotNil(t, golangci)
+	assert.Equal(t, defaults.LayerCategoryDefault, golangci.SourceLayer)
+
+	makefile := fs.Get("Makefile")
+	require.NotNil(t, makefile)
+	assert.Equal(t, filepath.Join(root, "ci", "github", "Makefile"), makefile.AbsPath)
+	assert.Equal(t, "ci/github", makefile.Blueprint)
+
+	main := fs.Get("main.go")
+	require.NotNil(t, main)
+	assert.Equal(t, filepath.Join(root, "go", "api", "main.go"), main.AbsPath)
+	assert.Empty(t, main.Blueprint)
+
+	assert.Nil(t, fs.Get(".github/ci.yml"))
+}
@@ -0,0 +1,45 @@
+package defaults
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FindSource looks for the registry source of relPath, a path a lockfile
+// records, at the root of the registry at registryDir and then in its root
+// _defaults/ directory. It returns the source's path, or "" if there is none.
+func FindSource(registryDir, relPath string) string {
+	for _, candidate := range []string{
+		filepath.Join(registryDir, relPath),
+		filepath.Join(registryDir, "_defaults", relPath),
+	} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// FindBlueprintFile looks for relPath in the directory of the blueprint at
+// blueprintPath in the registry at registryDir. It returns the file's path,
+// or "" if there is none.
+func FindBlueprintFile(registryDir, blueprintPath, relPath string) string {
+	candidate := filepath.Join(registryDir, blueprintPath, relPath)
+	if _, err := os.Stat(candidate); err == nil {
+		return candidate
+	}
+
+	return ""
+}
+
+// SourceMode returns the permission bits of the source file at path,
+// following symlinks, or zero if it cannot be read.
+func SourceMode(path string) os.FileMode {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+
+	return info.Mode().Perm()
+}
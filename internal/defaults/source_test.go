@@ -0,0 +1,44 @@
+package defaults_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/defaults"
+)
+
+func TestFindSource(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, filepath.Join(testRegistryRoot, "registry.yaml"), defaults.FindSource(testRegistryRoot, "registry.yaml"))
+	assert.Equal(t, filepath.Join(testRegistryRoot, "_defaults", ".editorconfig"), defaults.FindSource(testRegistryRoot, ".editorconfig"))
+	assert.Empty(t, defaults.FindSource(testRegistryRoot, "missing.txt"))
+}
+
+func TestFindBlueprintFile(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		filepath.Join(testRegistryRoot, "go", "api", "blueprint.yaml"),
+		defaults.FindBlueprintFile(testRegistryRoot, "go/api", "blueprint.yaml"),
+	)
+	assert.Empty(t, defaults.FindBlueprintFile(testRegistryRoot, "go/api", ".editorconfig"))
+}
+
+func TestSourceMode(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "run.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0o600))
+	require.NoError(t, os.Chmod(script, 0o755))
+	require.NoError(t, os.Symlink("run.sh", filepath.Join(dir, "alias.sh")))
+
+	assert.Equal(t, os.FileMode(0o755), defaults.SourceMode(script))
+	assert.Equal(t, os.FileMode(0o755), defaults.SourceMode(filepath.Join(dir, "alias.sh")), "symlinks take their target's mode")
+	assert.Zero(t, defaults.SourceMode(filepath.Join(dir, "missing")))
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 
 	getter "github.com/hashicorp/go-getter/v2"
 )
@@ -47,6 +48,12 @@ func (g *Getter) Fetch(ctx context.Context, src, dest string, opts FetchOpts) er
 	fullSrc := appendQueryParams(src, opts)
 	g.logger.Debug("fetching source", "src", fullSrc, "dest", dest)
 
+	// go-getter updates an existing destination in place, which fails for
+	// git and local sources, so an empty one is fetched into afresh.
+	if err := removeEmptyDir(dest); err != nil {
+		return fmt.Errorf("preparing %s: %w", dest, err)
+	}
+
 	req := &getter.Request{
 		Src:             fullSrc,
 		Dst:             dest,
@@ -63,6 +70,16 @@ func (g *Getter) Fetch(ctx context.Context, src, dest string, opts FetchOpts) er
 	return nil
 }
 
+// removeEmptyDir removes dir if it is an empty directory.
+func removeEmptyDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) > 0 {
+		return nil
+	}
+
+	return os.Remove(dir)
+}
+
 // FetchFile downloads a single file from src to dest.
 func (g *Getter) FetchFile(ctx context.Context, src, dest string, opts FetchOpts) error {
 	fullSrc := appendQueryParams(src, opts)
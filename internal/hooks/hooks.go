@@ -23,10 +23,17 @@ type Opts struct {
 	Logger *slog.Logger
 }
 
-// RunPostCreate executes post-create hooks in order. If a hook fails,
-// a warning is logged but execution continues — the project files are
-// already written so aborting would not help.
-func RunPostCreate(ctx context.Context, opts *Opts) []error {
+// Result records the outcome of a single hook.
+type Result struct {
+	// Command is the shell command that was executed.
+	Command string
+	// Err is non-nil if the hook exited with an error.
+	Err error
+}
+
+// Run executes hooks in order and returns one Result per hook. A failing hook
+// does not stop later hooks from running.
+func Run(ctx context.Context, opts *Opts) []Result {
 	if len(opts.Hooks) == 0 {
 		return nil
 	}
@@ -36,14 +43,31 @@ func RunPostCreate(ctx context.Context, opts *Opts) []error {
 		logger = slog.Default()
 	}
 
-	var errs []error
+	results := make([]Result, 0, len(opts.Hooks))
 
 	for _, hook := range opts.Hooks {
-		logger.Debug("running post-create hook", "cmd", hook)
+		logger.Debug("running hook", "cmd", hook)
+
+		err := runHook(ctx, hook, opts)
+		if err != nil {
+			logger.Warn("hook failed", "cmd", hook, "err", err)
+		}
+
+		results = append(results, Result{Command: hook, Err: err})
+	}
+
+	return results
+}
+
+// RunPostCreate executes post-create hooks in order. If a hook fails,
+// a warning is logged but execution continues — the project files are
+// already written so aborting would not help.
+func RunPostCreate(ctx context.Context, opts *Opts) []error {
+	var errs []error
 
-		if err := runHook(ctx, hook, opts); err != nil {
-			logger.Warn("post-create hook failed", "cmd", hook, "err", err)
-			errs = append(errs, fmt.Errorf("hook %q: %w", hook, err))
+	for _, r := range Run(ctx, opts) {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("hook %q: %w", r.Command, r.Err))
 		}
 	}
 
@@ -97,3 +97,26 @@ func TestRunPostCreate_MultipleHooks(t *testing.T) {
 	assert.Contains(t, stdout.String(), "second")
 	assert.Contains(t, stdout.String(), "third")
 }
+
+func TestRun_ReportsPerHookResults(t *testing.T) {
+	t.Parallel()
+
+	opts := &hooks.Opts{
+		Hooks:   []string{"true", "exit 3", "echo done"},
+		WorkDir: t.TempDir(),
+		Stdout:  &bytes.Buffer{},
+		Stderr:  &bytes.Buffer{},
+	}
+
+	results := hooks.Run(t.Context(), opts)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "true", results[0].Command)
+	require.NoError(t, results[0].Err)
+
+	assert.Equal(t, "exit 3", results[1].Command)
+	require.Error(t, results[1].Err)
+
+	assert.Equal(t, "echo done", results[2].Command)
+	assert.NoError(t, results[2].Err)
+}
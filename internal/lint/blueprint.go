@@ -0,0 +1,399 @@
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/donaldgifford/forge/internal/compose"
+	"github.com/donaldgifford/forge/internal/config"
+	"github.com/donaldgifford/forge/internal/create"
+	"github.com/donaldgifford/forge/internal/defaults"
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// parseErrorPattern extracts the line and message from a text/template
+// syntax error ("template: name:line: message").
+var parseErrorPattern = regexp.MustCompile(`template: .*?:(\d+): (.*)$`)
+
+// linter collects the issues of a single blueprint.
+type linter struct {
+	root   string
+	bpPath string
+
+	// bp is the blueprint merged with those it extends or includes, and
+	// sources are their paths; own is blueprint.yaml alone, whose
+	// expressions are linted.
+	bp      *config.Blueprint
+	sources []string
+	own     *config.Blueprint
+
+	// doc is blueprint.yaml as a node tree, for locating expressions.
+	doc yaml.Node
+
+	declared map[string]bool
+	used     map[string]bool
+	issues   []Issue
+}
+
+// source is where a template being linted comes from.
+type source struct {
+	// file is the file holding the template, relative to the registry root.
+	file string
+
+	// line is the line of blueprint.yaml the expression starts on; zero for
+	// template files, whose positions are used as-is.
+	line int
+
+	// path is true for path templates, which have no useful position.
+	path bool
+}
+
+// lintBlueprint lints the blueprint at bpPath. Problems with the blueprint
+// are returned as issues; the error is for failures to read the registry.
+func lintBlueprint(root, bpPath string) ([]Issue, error) {
+	bpFile := filepath.Join(root, bpPath, "blueprint.yaml")
+
+	l := &linter{
+		root:     root,
+		bpPath:   bpPath,
+		declared: map[string]bool{},
+		used:     map[string]bool{},
+	}
+
+	composed, err := compose.Load(root, bpPath)
+	if err != nil {
+		l.add(source{file: l.rel(bpFile)}, 0, 0, SeverityError, RuleParseError, err.Error())
+
+		return l.issues, nil
+	}
+
+	l.own, err = config.ReadBlueprint(bpFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Clean(bpFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", bpFile, err)
+	}
+
+	if err := yaml.Unmarshal(data, &l.doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", bpFile, err)
+	}
+
+	bp := composed.Blueprint
+	l.bp, l.sources = bp, composed.Sources
+
+	for i := range bp.Variables {
+		l.declared[bp.Variables[i].Name] = true
+	}
+
+	for i := range bp.Computed {
+		l.declared[bp.Computed[i].Name] = true
+	}
+
+	// Generated files see their item as a variable too.
+	for i := range bp.Generate {
+		l.declared[cmp.Or(bp.Generate[i].As, "item")] = true
+	}
+
+	partials, err := l.lintPartials()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.lintFiles(partials); err != nil {
+		return nil, err
+	}
+
+	l.lintExpressions()
+	l.reportUnused()
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Col < b.Col
+	})
+
+	return l.issues, nil
+}
+
+// lintPartials lints each partial available to the blueprint on its own,
+// as if invoked with the root context, and returns the ones that parse.
+func (l *linter) lintPartials() (map[string]string, error) {
+	files, err := defaults.PartialFiles(l.root, l.sources...)
+	if err != nil {
+		return nil, fmt.Errorf("resolving partials: %w", err)
+	}
+
+	renderer := tmpl.NewRenderer()
+	if d := l.bp.Template.Delimiters; len(d) == 2 {
+		renderer = renderer.WithDelimiters(d[0], d[1])
+	}
+
+	partials := make(map[string]string, len(files))
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		content, err := os.ReadFile(filepath.Clean(files[name]))
+		if err != nil {
+			return nil, fmt.Errorf("reading partial %s: %w", files[name], err)
+		}
+
+		if l.lint(renderer, source{file: l.rel(files[name])}, name, string(content)) {
+			partials[name] = string(content)
+		}
+	}
+
+	return partials, nil
+}
+
+// lintFiles lints the path and, for templates, the content of every file
+// the blueprint produces, conditions aside.
+func (l *linter) lintFiles(partials map[string]string) error {
+	fileSet, err := defaults.ResolveComposed(l.root, l.sources, l.bp.Defaults.Exclude)
+	if err != nil {
+		return fmt.Errorf("resolving files: %w", err)
+	}
+
+	create.ApplyCopyWithoutRender(l.bp.CopyWithoutRender, fileSet)
+
+	base := tmpl.NewRenderer().WithPartials(partials)
+
+	for _, entry := range fileSet.Entries() {
+		renderer := create.FileRenderer(base, &l.bp.Template, entry.RelPath)
+		src := source{file: l.rel(entry.AbsPath)}
+
+		l.lintPath(renderer, source{file: src.file, path: true}, entry.RelPath)
+
+		if !entry.IsTemplate || entry.Kind != defaults.KindFile {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Clean(entry.AbsPath))
+		if err != nil {
+			return fmt.Errorf("reading template %s: %w", entry.AbsPath, err)
+		}
+
+		l.lint(renderer, src, entry.RelPath, string(content))
+	}
+
+	return nil
+}
+
+// lintExpressions lints the template expressions in blueprint.yaml. Those
+// of extended or included blueprints are linted with them.
+func (l *linter) lintExpressions() {
+	renderer := tmpl.NewRenderer()
+	bp := l.own
+
+	for i := range bp.Variables {
+		v := &bp.Variables[i]
+		l.lintField(renderer, v.Default, "variables", i, "default")
+		l.lintField(renderer, v.When, "variables", i, "when")
+		l.lintField(renderer, v.Assert, "variables", i, "assert")
+	}
+
+	for i := range bp.Computed {
+		l.lintField(renderer, bp.Computed[i].Value, "computed", i, "value")
+	}
+
+	for i := range bp.Conditions {
+		l.lintField(renderer, bp.Conditions[i].When, "conditions", i, "when")
+	}
+
+	for i := range bp.Generate {
+		l.lintField(renderer, bp.Generate[i].Path, "generate", i, "path")
+	}
+
+	for i, cmd := range bp.Hooks.PostCreate {
+		l.lintField(renderer, cmd, "hooks", "post_create", i)
+	}
+
+	for _, pattern := range slices.Sorted(maps.Keys(bp.Rename)) {
+		key, value := mappingPair(nodeAt(&l.doc, "rename"), pattern)
+		l.lintPath(renderer, l.yamlSource(key), pattern)
+		l.lint(renderer, l.yamlSource(value), "blueprint.yaml", bp.Rename[pattern])
+	}
+}
+
+// lintField lints the blueprint.yaml expression text found at path.
+func (l *linter) lintField(renderer *tmpl.Renderer, text string, path ...any) {
+	if text == "" {
+		return
+	}
+
+	l.lint(renderer, l.yamlSource(nodeAt(&l.doc, path...)), "blueprint.yaml", text)
+}
+
+// lint analyzes text as the template name and records its issues. It
+// reports whether text parsed.
+func (l *linter) lint(renderer *tmpl.Renderer, src source, name, text string) bool {
+	analysis, err := renderer.Analyze(name, text)
+
+	return l.record(src, name, analysis, err)
+}
+
+func (l *linter) lintPath(renderer *tmpl.Renderer, src source, path string) {
+	analysis, err := renderer.AnalyzePath(path)
+	l.record(src, path, analysis, err)
+}
+
+// record adds the issues of an analysis of the template name. References
+// made from partials it invokes only count as uses; partials are linted
+// on their own.
+func (l *linter) record(src source, name string, analysis *tmpl.Analysis, err error) bool {
+	if err != nil {
+		line, msg := 0, err.Error()
+		if m := parseErrorPattern.FindStringSubmatch(msg); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+
+		l.add(src, line, 0, SeverityError, RuleParseError, msg)
+
+		return false
+	}
+
+	for _, ref := range analysis.Refs {
+		l.used[ref.Name] = true
+
+		if ref.Template == name && !l.declared[ref.Name] {
+			l.add(src, ref.Line, ref.Col, SeverityError, RuleUndefinedVariable,
+				fmt.Sprintf("%s refers to undeclared variable %q", ref.Ref, ref.Name))
+		}
+	}
+
+	for _, fn := range analysis.UnknownFuncs {
+		if fn.Template == name {
+			l.add(src, fn.Line, fn.Col, SeverityError, RuleUnknownFunction, fmt.Sprintf("unknown function %q", fn.Name))
+		}
+	}
+
+	return true
+}
+
+// reportUnused warns about variables declared in blueprint.yaml that no
+// template refers to.
+func (l *linter) reportUnused() {
+	bp := l.own
+	for i := range bp.Variables {
+		if name := bp.Variables[i].Name; !l.used[name] {
+			l.add(l.yamlSource(nodeAt(&l.doc, "variables", i, "name")), 0, 0, SeverityWarning, RuleUnusedVariable,
+				fmt.Sprintf("variable %q is declared but never used", name))
+		}
+	}
+
+	for i := range bp.Computed {
+		if name := bp.Computed[i].Name; !l.used[name] {
+			l.add(l.yamlSource(nodeAt(&l.doc, "computed", i, "name")), 0, 0, SeverityWarning, RuleUnusedVariable,
+				fmt.Sprintf("computed variable %q is declared but never used", name))
+		}
+	}
+}
+
+// add records an issue at line and col of the template from src.
+func (l *linter) add(src source, line, col int, severity Severity, rule Rule, msg string) {
+	switch {
+	case src.path:
+		line, col = 0, 0
+	case src.line > 0:
+		// Columns inside a YAML scalar do not map back to the file.
+		line, col = src.line+max(line, 1)-1, 0
+	}
+
+	l.issues = append(l.issues, Issue{
+		Blueprint: l.bpPath,
+		File:      src.file,
+		Line:      line,
+		Col:       col,
+		Severity:  severity,
+		Rule:      rule,
+		Message:   msg,
+	})
+}
+
+// yamlSource returns the source for an expression held by node in
+// blueprint.yaml.
+func (l *linter) yamlSource(node *yaml.Node) source {
+	src := source{file: l.rel(filepath.Join(l.root, l.bpPath, "blueprint.yaml"))}
+
+	if node != nil {
+		src.line = node.Line
+
+		// Block scalars start on the line after their indicator.
+		if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			src.line++
+		}
+	}
+
+	return src
+}
+
+// rel returns path relative to the registry root, with forward slashes.
+func (l *linter) rel(path string) string {
+	rel, err := filepath.Rel(l.root, path)
+	if err != nil {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// nodeAt returns the node at path in doc, where each path element is a
+// mapping key (string) or a sequence index (int), or nil if there is none.
+func nodeAt(doc *yaml.Node, path ...any) *yaml.Node {
+	n := doc
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+
+	for _, elem := range path {
+		switch key := elem.(type) {
+		case string:
+			_, n = mappingPair(n, key)
+		case int:
+			if n.Kind != yaml.SequenceNode || key >= len(n.Content) {
+				return nil
+			}
+
+			n = n.Content[key]
+		}
+
+		if n == nil {
+			return nil
+		}
+	}
+
+	return n
+}
+
+// mappingPair returns the key and value nodes for key in mapping node n.
+func mappingPair(n *yaml.Node, key string) (keyNode, valueNode *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,148 @@
+// Package lint statically checks blueprint templates against the variables
+// the blueprint declares, without rendering anything.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/donaldgifford/forge/internal/config"
+)
+
+// Opts configures the lint operation.
+type Opts struct {
+	// RegistryDir is the registry root directory.
+	RegistryDir string
+	// Blueprints are the blueprint paths to lint, relative to RegistryDir
+	// (e.g., "go/api"). When empty, every blueprint in registry.yaml is linted.
+	Blueprints []string
+	// OutputFormat is "text" or "json".
+	OutputFormat string
+	// Writer is the output destination.
+	Writer io.Writer
+}
+
+// Severity is how serious an Issue is. Only errors fail a lint.
+type Severity string
+
+// Issue severities.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rule identifies the check that reported an Issue.
+type Rule string
+
+// Lint rules.
+const (
+	RuleParseError        Rule = "parse-error"
+	RuleUndefinedVariable Rule = "undefined-variable"
+	RuleUnusedVariable    Rule = "unused-variable"
+	RuleUnknownFunction   Rule = "unknown-function"
+)
+
+// Issue is a single problem found in a blueprint.
+type Issue struct {
+	// Blueprint is the path of the blueprint being linted.
+	Blueprint string `json:"blueprint"`
+	// File is the file holding the problem, relative to the registry root.
+	File string `json:"file"`
+	// Line and Col locate the problem in File, both 1-based. They are zero
+	// when unknown (e.g., for path templates).
+	Line     int      `json:"line,omitempty"`
+	Col      int      `json:"col,omitempty"`
+	Severity Severity `json:"severity"`
+	Rule     Rule     `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+// Result holds the lint findings.
+type Result struct {
+	Blueprints []string `json:"blueprints"`
+	Issues     []Issue  `json:"issues"`
+}
+
+// Errors returns the number of error-severity issues.
+func (r *Result) Errors() int {
+	n := 0
+
+	for i := range r.Issues {
+		if r.Issues[i].Severity == SeverityError {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Run lints the selected blueprints and writes the findings to opts.Writer.
+func Run(opts *Opts) (*Result, error) {
+	paths := opts.Blueprints
+
+	if len(paths) == 0 {
+		reg, err := config.LoadRegistry(filepath.Join(opts.RegistryDir, "registry.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("loading registry: %w", err)
+		}
+
+		for i := range reg.Blueprints {
+			paths = append(paths, reg.Blueprints[i].Path)
+		}
+	}
+
+	result := &Result{Blueprints: paths, Issues: []Issue{}}
+
+	for _, bpPath := range paths {
+		issues, err := lintBlueprint(opts.RegistryDir, bpPath)
+		if err != nil {
+			return nil, fmt.Errorf("linting blueprint %s: %w", bpPath, err)
+		}
+
+		result.Issues = append(result.Issues, issues...)
+	}
+
+	return result, renderResult(opts.Writer, opts.OutputFormat, result)
+}
+
+func renderResult(w io.Writer, format string, result *Result) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(result)
+	default:
+		return renderText(w, result)
+	}
+}
+
+// renderText prints one "file:line:col: severity: message (rule)" line per
+// issue, followed by a summary.
+func renderText(w io.Writer, result *Result) error {
+	for i := range result.Issues {
+		issue := &result.Issues[i]
+
+		location := issue.File
+		if issue.Line > 0 {
+			location += fmt.Sprintf(":%d", issue.Line)
+		}
+
+		if issue.Col > 0 {
+			location += fmt.Sprintf(":%d", issue.Col)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s: %s (%s)\n", location, issue.Severity, issue.Message, issue.Rule); err != nil {
+			return err
+		}
+	}
+
+	errs := result.Errors()
+
+	_, err := fmt.Fprintf(w, "%d error(s), %d warning(s) in %d blueprint(s)\n",
+		errs, len(result.Issues)-errs, len(result.Blueprints))
+
+	return err
+}
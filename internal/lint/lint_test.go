@@ -0,0 +1,160 @@
+package lint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/lint"
+)
+
+const lintBlueprintYAML = `apiVersion: v1
+name: api
+description: test
+version: "1.0.0"
+variables:
+  - name: project_name
+    type: string
+  - name: use_docker
+    type: bool
+    default: "{{ .project_nmae }}"
+  - name: unused
+    type: string
+conditions:
+  - when: "{{ not .use_docker }}"
+    exclude: ["Dockerfile"]
+rename:
+  "{{project_name}}/": "."
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func setupRegistry(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	writeFile(t, filepath.Join(root, "registry.yaml"),
+		"apiVersion: v1\nname: test\nblueprints:\n  - name: api\n    path: go/api\n")
+	writeFile(t, filepath.Join(root, "go", "api", "blueprint.yaml"), lintBlueprintYAML)
+	writeFile(t, filepath.Join(root, "go", "api", "{{project_name}}", "main.go.tmpl"),
+		"package {{ .project_name | snakecase }}\n\n// {{ .author }}\n{{ include \"header\" . }}\n")
+	writeFile(t, filepath.Join(root, "go", "api", "{{project_name}}", "broken.txt.tmpl"), "{{ if .use_docker }}\n")
+	writeFile(t, filepath.Join(root, "_partials", "header.tmpl"), "{{ .licence }}")
+
+	return root
+}
+
+func TestRun_ReportsIssues(t *testing.T) {
+	t.Parallel()
+
+	root := setupRegistry(t)
+
+	var buf bytes.Buffer
+
+	result, err := lint.Run(&lint.Opts{RegistryDir: root, OutputFormat: "json", Writer: &buf})
+	require.NoError(t, err)
+
+	type found struct {
+		File string
+		Line int
+		Rule lint.Rule
+	}
+
+	var got []found
+	for _, issue := range result.Issues {
+		assert.Equal(t, "go/api", issue.Blueprint)
+		got = append(got, found{File: issue.File, Line: issue.Line, Rule: issue.Rule})
+	}
+
+	assert.ElementsMatch(t, []found{
+		{File: "_partials/header.tmpl", Line: 1, Rule: lint.RuleUndefinedVariable},
+		{File: "go/api/blueprint.yaml", Line: 10, Rule: lint.RuleUndefinedVariable},
+		{File: "go/api/blueprint.yaml", Line: 11, Rule: lint.RuleUnusedVariable},
+		{File: "go/api/{{project_name}}/broken.txt.tmpl", Line: 2, Rule: lint.RuleParseError},
+		{File: "go/api/{{project_name}}/main.go.tmpl", Line: 1, Rule: lint.RuleUnknownFunction},
+		{File: "go/api/{{project_name}}/main.go.tmpl", Line: 3, Rule: lint.RuleUndefinedVariable},
+	}, got)
+	assert.Equal(t, 5, result.Errors())
+
+	var decoded lint.Result
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Len(t, decoded.Issues, 6)
+}
+
+func TestRun_TextOutput(t *testing.T) {
+	t.Parallel()
+
+	root := setupRegistry(t)
+
+	var buf bytes.Buffer
+
+	_, err := lint.Run(&lint.Opts{RegistryDir: root, Blueprints: []string{"go/api"}, OutputFormat: "text", Writer: &buf})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, `go/api/{{project_name}}/main.go.tmpl:1:28: error: unknown function "snakecase" (unknown-function)`)
+	assert.Contains(t, out, `go/api/{{project_name}}/main.go.tmpl:3:7: error: .author refers to undeclared variable "author"`)
+	assert.Contains(t, out, `go/api/blueprint.yaml:11: warning: variable "unused" is declared but never used (unused-variable)`)
+	assert.Contains(t, out, "5 error(s), 1 warning(s) in 1 blueprint(s)")
+}
+
+func TestRun_CleanBlueprint(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "api", "blueprint.yaml"),
+		"apiVersion: v1\nname: api\nversion: \"1.0.0\"\nvariables:\n  - name: project_name\n    type: string\n"+
+			"template:\n  delimiters: [\"[[\", \"]]\"]\n")
+	writeFile(t, filepath.Join(root, "api", "[[project_name]]", "ci.yml.tmpl"),
+		"name: [[ .project_name | kebabCase ]]\nrun: ${{ matrix.os }}\n")
+
+	var buf bytes.Buffer
+
+	result, err := lint.Run(&lint.Opts{RegistryDir: root, Blueprints: []string{"api"}, OutputFormat: "text", Writer: &buf})
+	require.NoError(t, err)
+	assert.Empty(t, result.Issues)
+	assert.Equal(t, "0 error(s), 0 warning(s) in 1 blueprint(s)\n", buf.String())
+}
+
+func TestRun_InvalidBlueprint(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "api", "blueprint.yaml"), "apiVersion: v1\nversion: \"1.0.0\"\n")
+
+	var buf bytes.Buffer
+
+	result, err := lint.Run(&lint.Opts{RegistryDir: root, Blueprints: []string{"api"}, OutputFormat: "text", Writer: &buf})
+	require.NoError(t, err)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, lint.RuleParseError, result.Issues[0].Rule)
+	assert.Equal(t, "api/blueprint.yaml", result.Issues[0].File)
+}
+
+func TestRun_ComposedBlueprint(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go", "base", "blueprint.yaml"),
+		"name: base\nvariables:\n  - name: project_name\n    type: string\n")
+	writeFile(t, filepath.Join(root, "go", "api", "blueprint.yaml"),
+		"apiVersion: v1\nname: api\nextends: go/base\n")
+	writeFile(t, filepath.Join(root, "go", "api", "README.md.tmpl"), "# {{ .project_name }}\n")
+
+	var buf bytes.Buffer
+
+	result, err := lint.Run(&lint.Opts{RegistryDir: root, Blueprints: []string{"go/api"}, OutputFormat: "text", Writer: &buf})
+	require.NoError(t, err)
+	assert.Empty(t, result.Issues)
+}
@@ -5,10 +5,15 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
 )
 
 const (
@@ -21,13 +26,28 @@ const (
 
 // Lockfile tracks the provenance, variables, and sync state of a scaffolded project.
 type Lockfile struct {
-	Blueprint    BlueprintRef       `yaml:"blueprint"`
-	CreatedAt    time.Time          `yaml:"created_at"`
-	LastSynced   time.Time          `yaml:"last_synced"`
-	ForgeVersion string             `yaml:"forge_version"`
-	Variables    map[string]any     `yaml:"variables"`
+	Blueprint    BlueprintRef   `yaml:"blueprint"`
+	CreatedAt    time.Time      `yaml:"created_at"`
+	LastSynced   time.Time      `yaml:"last_synced"`
+	ForgeVersion string         `yaml:"forge_version"`
+	Variables    map[string]any `yaml:"variables"`
+	// SkippedVariables lists variables whose when condition was false at create time.
+	SkippedVariables []string `yaml:"skipped_variables,omitempty"`
+	// Computed holds the computed variable values rendered at create time.
+	Computed map[string]any `yaml:"computed,omitempty"`
+	// SecretComputed holds, in declaration order, the expressions of the
+	// computed variables derived from secret variables, whose values are
+	// never recorded. TemplateVars re-derives them.
+	SecretComputed []ComputedEntry `yaml:"secret_computed,omitempty"`
+	// Seed is the uuidv4 seed templates were rendered with.
+	Seed         string             `yaml:"seed,omitempty"`
 	Defaults     []DefaultEntry     `yaml:"defaults,omitempty"`
 	ManagedFiles []ManagedFileEntry `yaml:"managed_files,omitempty"`
+	// Generated tracks the files rendered by generate rules, one per item.
+	Generated []GeneratedEntry `yaml:"generated,omitempty"`
+	// Applied records the blueprints added to the project after it was
+	// created (forge add), each with its own variables and tracked files.
+	Applied []Lockfile `yaml:"applied,omitempty"`
 }
 
 // BlueprintRef identifies the source blueprint.
@@ -37,6 +57,18 @@ type BlueprintRef struct {
 	Path        string `yaml:"path"`
 	Ref         string `yaml:"ref,omitempty"`
 	Commit      string `yaml:"commit,omitempty"`
+	// Constraint is the version range the blueprint was pinned to (e.g.
+	// "^2.0"); Ref is then the release within it the project is on.
+	Constraint string `yaml:"constraint,omitempty"`
+	// Composed lists the blueprints Path extends or includes, transitively
+	// and lowest precedence first.
+	Composed []string `yaml:"composed,omitempty"`
+}
+
+// ComputedEntry records a computed variable by its template expression.
+type ComputedEntry struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
 }
 
 // DefaultEntry tracks an inherited default file.
@@ -45,15 +77,124 @@ type DefaultEntry struct {
 	Source       string `yaml:"source"`
 	Strategy     string `yaml:"strategy"`
 	Hash         string `yaml:"hash,omitempty"`
+	Mode         string `yaml:"mode,omitempty"`
 	SyncedCommit string `yaml:"synced_commit,omitempty"`
+	// Verbatim is set when a .tmpl source is copied without rendering.
+	Verbatim bool `yaml:"verbatim,omitempty"`
+	// Delimiters holds custom [left, right] template delimiters for the file.
+	Delimiters []string `yaml:"delimiters,omitempty"`
 }
 
 // ManagedFileEntry tracks a file managed for ongoing sync.
 type ManagedFileEntry struct {
-	Path         string `yaml:"path"`
-	Strategy     string `yaml:"strategy"`
+	Path     string `yaml:"path"`
+	Strategy string `yaml:"strategy"`
+	// Blueprint is the composed blueprint the file comes from, when it is
+	// not the project's own blueprint.
+	Blueprint    string `yaml:"blueprint,omitempty"`
 	Hash         string `yaml:"hash,omitempty"`
+	Mode         string `yaml:"mode,omitempty"`
 	SyncedCommit string `yaml:"synced_commit,omitempty"`
+	// Verbatim is set when a .tmpl source is copied without rendering.
+	Verbatim bool `yaml:"verbatim,omitempty"`
+	// Delimiters holds custom [left, right] template delimiters for the file.
+	Delimiters []string `yaml:"delimiters,omitempty"`
+}
+
+// GeneratedEntry tracks a file rendered by a generate rule for one item of
+// a list variable.
+type GeneratedEntry struct {
+	// Path is the output path of the file.
+	Path string `yaml:"path"`
+	// Template is the source template's path relative to the blueprint.
+	Template string `yaml:"template"`
+	// Blueprint is the composed blueprint the template comes from, when it
+	// is not the project's own blueprint.
+	Blueprint string `yaml:"blueprint,omitempty"`
+	// As and Item are the variable name and value the item was rendered with.
+	As           string   `yaml:"as"`
+	Item         any      `yaml:"item"`
+	Strategy     string   `yaml:"strategy"`
+	Hash         string   `yaml:"hash,omitempty"`
+	Mode         string   `yaml:"mode,omitempty"`
+	SyncedCommit string   `yaml:"synced_commit,omitempty"`
+	Delimiters   []string `yaml:"delimiters,omitempty"`
+}
+
+// Vars returns the template context the entry was rendered with: the
+// lockfile's template variables plus the item.
+func (g *GeneratedEntry) Vars(lock *Lockfile) map[string]any {
+	vars := maps.Clone(lock.TemplateVars())
+	if vars == nil {
+		vars = make(map[string]any, 1)
+	}
+
+	vars[g.As] = g.Item
+
+	return vars
+}
+
+// Blueprints returns the record of every blueprint applied to the project:
+// the lockfile itself, for the blueprint the project was created from,
+// followed by each added blueprint.
+func (l *Lockfile) Blueprints() []*Lockfile {
+	all := make([]*Lockfile, 0, len(l.Applied)+1)
+	all = append(all, l)
+
+	for i := range l.Applied {
+		all = append(all, &l.Applied[i])
+	}
+
+	return all
+}
+
+// Sources returns the path of every blueprint the project was created
+// from, lowest precedence first and ending with the project's own.
+func (l *Lockfile) Sources() []string {
+	return append(slices.Clone(l.Blueprint.Composed), l.Blueprint.Path)
+}
+
+// TemplateVars returns the template context recorded in the lockfile:
+// the collected variables plus computed variables, with those derived from
+// secrets re-derived (see deriveSecretComputed).
+func (l *Lockfile) TemplateVars() map[string]any {
+	if len(l.Computed) == 0 && len(l.SecretComputed) == 0 {
+		return l.Variables
+	}
+
+	vars := make(map[string]any, len(l.Variables)+len(l.Computed)+len(l.SecretComputed))
+	maps.Copy(vars, l.Variables)
+	maps.Copy(vars, l.Computed)
+	l.deriveSecretComputed(vars)
+
+	return vars
+}
+
+// deriveSecretComputed renders the expressions of SecretComputed into vars,
+// in order. The secret variables they derive from are not recorded and are
+// taken as empty; an expression that still fails to render leaves its
+// variable undefined.
+func (l *Lockfile) deriveSecretComputed(vars map[string]any) {
+	renderer := tmpl.NewRenderer().WithFuncOptions(tmpl.FuncOptions{Seed: l.Seed})
+
+	for _, c := range l.SecretComputed {
+		ctx := maps.Clone(vars)
+
+		if analysis, err := renderer.Analyze("inline", c.Value); err == nil {
+			for _, ref := range analysis.Refs {
+				if _, ok := ctx[ref.Name]; !ok {
+					ctx[ref.Name] = ""
+				}
+			}
+		}
+
+		out, err := renderer.RenderString(c.Value, ctx)
+		if err != nil {
+			continue
+		}
+
+		vars[c.Name] = strings.TrimSpace(out)
+	}
 }
 
 // ContentHash computes the SHA256 hash of content in the format "sha256:<hex>".
@@ -62,6 +203,23 @@ func ContentHash(content []byte) string {
 	return "sha256:" + hex.EncodeToString(h[:])
 }
 
+// FormatMode formats the permission bits of a file mode as recorded in the
+// lockfile, e.g. "0755".
+func FormatMode(mode os.FileMode) string {
+	return fmt.Sprintf("%04o", mode.Perm())
+}
+
+// FileMode returns the permission bits of the file at path in lockfile format.
+// It returns an empty string if the file cannot be read.
+func FileMode(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	return FormatMode(info.Mode())
+}
+
 // Write marshals the lockfile to YAML and writes it to the given path.
 func Write(path string, lock *Lockfile) error {
 	data, err := yaml.Marshal(lock)
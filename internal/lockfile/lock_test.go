@@ -114,3 +114,38 @@ func TestWrite_EmptyLockfile(t *testing.T) {
 	assert.Empty(t, loaded.Defaults)
 	assert.Empty(t, loaded.ManagedFiles)
 }
+
+func TestTemplateVars_IncludesComputed(t *testing.T) {
+	t.Parallel()
+
+	lock := &lockfile.Lockfile{
+		Variables: map[string]any{"project_name": "my-api"},
+		Computed:  map[string]any{"image": "ghcr.io/example/my-api"},
+	}
+
+	assert.Equal(t, map[string]any{
+		"project_name": "my-api",
+		"image":        "ghcr.io/example/my-api",
+	}, lock.TemplateVars())
+	assert.NotContains(t, lock.Variables, "image")
+}
+
+func TestTemplateVars_RederivesSecretComputed(t *testing.T) {
+	t.Parallel()
+
+	lock := &lockfile.Lockfile{
+		Variables: map[string]any{"db_user": "app"},
+		Computed:  map[string]any{"db_host": "db"},
+		SecretComputed: []lockfile.ComputedEntry{
+			{Name: "dsn", Value: "postgres://{{ .db_user }}:{{ .db_password }}@{{ .db_host }}/app"},
+			{Name: "dsn_env", Value: "DSN={{ .dsn }}"},
+			{Name: "broken", Value: "{{ .dsn | nosuchfunc }}"},
+		},
+	}
+
+	vars := lock.TemplateVars()
+	assert.Equal(t, "postgres://app:@db/app", vars["dsn"])
+	assert.Equal(t, "DSN=postgres://app:@db/app", vars["dsn_env"])
+	assert.NotContains(t, vars, "broken")
+	assert.NotContains(t, vars, "db_password")
+}
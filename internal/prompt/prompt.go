@@ -3,57 +3,148 @@ package prompt
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/donaldgifford/forge/internal/config"
+	tmpl "github.com/donaldgifford/forge/internal/template"
 )
 
 // PromptFn is a callback for interactive variable input.
 type PromptFn func(v *config.Variable, current map[string]any) (string, error)
 
+// Collected holds the outcome of variable collection.
+type Collected struct {
+	// Values maps every declared variable name to its resolved value.
+	Values map[string]any
+
+	// Skipped lists variables whose when condition was false, in declaration
+	// order. Skipped variables are not prompted and hold their default (or zero) value.
+	Skipped []string
+}
+
 // CollectVariables resolves all blueprint variables using overrides, defaults, and
+// optional interactive prompting. It is Collect without the skipped-variable report.
+func CollectVariables(
+	vars []config.Variable,
+	overrides map[string]any,
+	useDefaults bool,
+	promptFn PromptFn,
+) (map[string]any, error) {
+	collected, err := Collect(vars, overrides, useDefaults, promptFn)
+	if err != nil {
+		return nil, err
+	}
+
+	return collected.Values, nil
+}
+
+// Collect resolves all blueprint variables using overrides, defaults, and
 // optional interactive prompting. Variables are processed in declaration order so
-// that later defaults can reference earlier variable values.
+// that later defaults and when conditions can reference earlier variable values.
+//
+// Override values are either strings (from --set) or decoded values from a values
+// file; both are validated and coerced to the variable's type. Overrides for
+// variables the blueprint does not declare are reported as an error.
+//
+// A variable whose when condition does not render to "true" is skipped: it is
+// never prompted, any override is ignored, and it takes its default value (or
+// the type's zero value).
 //
 // If useDefaults is true, all variables use their default values without prompting.
 // The promptFn callback is called for variables that need interactive input; pass nil
 // to skip interactive prompting (useful in tests or CI with --defaults).
-func CollectVariables(
+func Collect(
 	vars []config.Variable,
-	overrides map[string]string,
+	overrides map[string]any,
 	useDefaults bool,
 	promptFn PromptFn,
-) (map[string]any, error) {
-	result := make(map[string]any, len(vars))
+) (*Collected, error) {
+	if err := checkUnknownOverrides(vars, overrides); err != nil {
+		return nil, err
+	}
+
+	collected := &Collected{Values: make(map[string]any, len(vars))}
 
 	for i := range vars {
 		v := &vars[i]
 
-		val, err := resolveVariable(v, overrides, result, useDefaults, promptFn)
+		active, err := evaluateExpr(v.When, collected.Values)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating when for %q: %w", v.Name, err)
+		}
+
+		if !active {
+			val, err := resolveSkipped(v, collected.Values)
+			if err != nil {
+				return nil, err
+			}
+
+			collected.Values[v.Name] = val
+			collected.Skipped = append(collected.Skipped, v.Name)
+
+			continue
+		}
+
+		val, err := resolveVariable(v, overrides, collected.Values, useDefaults, promptFn)
 		if err != nil {
 			return nil, err
 		}
 
-		result[v.Name] = val
+		collected.Values[v.Name] = val
+	}
+
+	return collected, nil
+}
+
+// evaluateExpr renders a when or assert expression against the given values
+// and reports whether it rendered to "true". An empty expression is always true.
+func evaluateExpr(expr string, current map[string]any) (bool, error) {
+	if expr == "" {
+		return true, nil
+	}
+
+	rendered, err := renderDefault(expr, current)
+	if err != nil {
+		return false, err
 	}
 
-	return result, nil
+	return strings.TrimSpace(rendered) == "true", nil
+}
+
+// resolveSkipped returns the value for a variable whose when condition is false:
+// its rendered default, or the zero value for its type. Required is not enforced.
+func resolveSkipped(v *config.Variable, current map[string]any) (any, error) {
+	defaultVal, err := renderDefault(v.Default, current)
+	if err != nil {
+		return nil, fmt.Errorf("rendering default for %q: %w", v.Name, err)
+	}
+
+	if defaultVal == "" {
+		return zeroValue(v.Type), nil
+	}
+
+	val, err := coerceValue(defaultVal, v.Type)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default for %q: %w", v.Name, err)
+	}
+
+	return val, nil
 }
 
 // resolveVariable resolves a single variable value through the override → default → prompt chain.
 func resolveVariable(
 	v *config.Variable,
-	overrides map[string]string,
+	overrides map[string]any,
 	current map[string]any,
 	useDefaults bool,
 	promptFn PromptFn,
 ) (any, error) {
 	// Check for CLI override first.
 	if raw, ok := overrides[v.Name]; ok {
-		return resolveFromOverride(raw, v)
+		return resolveFromOverride(raw, v, current)
 	}
 
 	// Render the default value as a template (it can reference earlier variables).
@@ -64,29 +155,59 @@ func resolveVariable(
 
 	// If using defaults mode or no prompt function, use the default.
 	if useDefaults || promptFn == nil {
-		return resolveFromDefault(defaultVal, v)
+		return resolveFromDefault(defaultVal, v, current)
 	}
 
 	// Interactive prompt.
 	return resolveFromPrompt(v, current, defaultVal, promptFn)
 }
 
-// resolveFromOverride validates and coerces an override value.
-func resolveFromOverride(raw string, v *config.Variable) (any, error) {
-	if err := validateValue(raw, v); err != nil {
-		return nil, fmt.Errorf("override for %q failed validation: %w", v.Name, err)
+// checkUnknownOverrides returns an error naming any override keys that do not
+// correspond to a declared variable.
+func checkUnknownOverrides(vars []config.Variable, overrides map[string]any) error {
+	if len(overrides) == 0 {
+		return nil
 	}
 
-	val, err := coerceValue(raw, v.Type)
+	declared := make(map[string]bool, len(vars))
+	for i := range vars {
+		declared[vars[i].Name] = true
+	}
+
+	var unknown []string
+
+	for name := range overrides {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	return fmt.Errorf("unknown variable(s) %s: not declared by the blueprint", strings.Join(unknown, ", "))
+}
+
+// resolveFromOverride coerces and validates an override value.
+func resolveFromOverride(value any, v *config.Variable, current map[string]any) (any, error) {
+	val, err := coerceOverride(value, v.Type)
 	if err != nil {
 		return nil, fmt.Errorf("invalid override for %q: %w", v.Name, err)
 	}
 
+	if err := validateValue(val, v, current); err != nil {
+		return nil, fmt.Errorf("override for %q failed validation: %w", v.Name, err)
+	}
+
 	return val, nil
 }
 
-// resolveFromDefault uses the rendered default value, checking required constraints.
-func resolveFromDefault(defaultVal string, v *config.Variable) (any, error) {
+// resolveFromDefault uses the rendered default value, checking required and
+// validation constraints.
+func resolveFromDefault(defaultVal string, v *config.Variable, current map[string]any) (any, error) {
 	if defaultVal == "" && v.Required {
 		return nil, fmt.Errorf("variable %q is required but has no default value", v.Name)
 	}
@@ -100,6 +221,10 @@ func resolveFromDefault(defaultVal string, v *config.Variable) (any, error) {
 		return nil, fmt.Errorf("invalid default for %q: %w", v.Name, err)
 	}
 
+	if err := validateValue(val, v, current); err != nil {
+		return nil, fmt.Errorf("default for %q failed validation: %w", v.Name, err)
+	}
+
 	return val, nil
 }
 
@@ -123,8 +248,8 @@ func resolveFromPrompt(
 		return nil, fmt.Errorf("variable %q is required", v.Name)
 	}
 
-	if err := validateValue(raw, v); err != nil {
-		return nil, fmt.Errorf("variable %q failed validation: %w", v.Name, err)
+	if raw == "" {
+		return zeroValue(v.Type), nil
 	}
 
 	val, err := coerceValue(raw, v.Type)
@@ -132,16 +257,21 @@ func resolveFromPrompt(
 		return nil, fmt.Errorf("invalid value for %q: %w", v.Name, err)
 	}
 
+	if err := validateValue(val, v, current); err != nil {
+		return nil, fmt.Errorf("variable %q failed validation: %w", v.Name, err)
+	}
+
 	return val, nil
 }
 
 // renderDefault renders a default value template with the current variable values.
+// It has the functions of file templates, as lint assumes.
 func renderDefault(defaultTmpl string, current map[string]any) (string, error) {
 	if defaultTmpl == "" || !strings.Contains(defaultTmpl, "{{") {
 		return defaultTmpl, nil
 	}
 
-	tmpl, err := template.New("default").Option("missingkey=zero").Parse(defaultTmpl)
+	tmpl, err := template.New("default").Funcs(tmpl.FuncMap()).Option("missingkey=zero").Parse(defaultTmpl)
 	if err != nil {
 		return "", fmt.Errorf("parsing default template %q: %w", defaultTmpl, err)
 	}
@@ -161,7 +291,13 @@ func coerceValue(raw, varType string) (any, error) {
 		return strconv.ParseBool(raw)
 	case "int":
 		return strconv.Atoi(raw)
-	case "string", "choice", "":
+	case "float":
+		return strconv.ParseFloat(raw, 64)
+	case "list", "multichoice":
+		return parseList(raw)
+	case "map":
+		return parseMap(raw)
+	case "string", "choice", "secret", "":
 		return raw, nil
 	default:
 		return raw, nil
@@ -175,25 +311,13 @@ func zeroValue(varType string) any {
 		return false
 	case "int":
 		return 0
+	case "float":
+		return 0.0
+	case "list", "multichoice":
+		return []string{}
+	case "map":
+		return map[string]string{}
 	default:
 		return ""
 	}
 }
-
-// validateValue checks a string value against the variable's validation regex.
-func validateValue(raw string, v *config.Variable) error {
-	if v.Validate == "" {
-		return nil
-	}
-
-	re, err := regexp.Compile(v.Validate)
-	if err != nil {
-		return fmt.Errorf("invalid validation regex %q: %w", v.Validate, err)
-	}
-
-	if !re.MatchString(raw) {
-		return fmt.Errorf("value %q does not match pattern %q", raw, v.Validate)
-	}
-
-	return nil
-}
@@ -18,7 +18,7 @@ func TestCollectVariables_Overrides(t *testing.T) {
 		{Name: "use_grpc", Type: "bool", Default: "false"},
 	}
 
-	overrides := map[string]string{
+	overrides := map[string]any{
 		"project_name": "my-api",
 		"use_grpc":     "true",
 	}
@@ -80,7 +80,7 @@ func TestCollectVariables_OverrideValidation(t *testing.T) {
 		{Name: "project_name", Type: "string", Validate: "^[a-z][a-z0-9-]*$"},
 	}
 
-	overrides := map[string]string{
+	overrides := map[string]any{
 		"project_name": "INVALID",
 	}
 
@@ -96,7 +96,7 @@ func TestCollectVariables_InvalidBoolOverride(t *testing.T) {
 		{Name: "flag", Type: "bool"},
 	}
 
-	overrides := map[string]string{
+	overrides := map[string]any{
 		"flag": "not-a-bool",
 	}
 
@@ -112,7 +112,7 @@ func TestCollectVariables_InvalidIntOverride(t *testing.T) {
 		{Name: "port", Type: "int"},
 	}
 
-	overrides := map[string]string{
+	overrides := map[string]any{
 		"port": "not-a-number",
 	}
 
@@ -172,7 +172,7 @@ func TestCollectVariables_OverrideTakesPrecedence(t *testing.T) {
 		{Name: "name", Type: "string", Default: "default-name"},
 	}
 
-	overrides := map[string]string{"name": "override-name"}
+	overrides := map[string]any{"name": "override-name"}
 
 	// Even with a promptFn, overrides should win.
 	promptFn := func(_ *config.Variable, _ map[string]any) (string, error) {
@@ -214,3 +214,332 @@ func TestCollectVariables_ZeroValues(t *testing.T) {
 	assert.Equal(t, false, result["flag"])
 	assert.Equal(t, 0, result["count"])
 }
+
+func TestCollectVariables_TypedOverrides(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "name", Type: "string"},
+		{Name: "port", Type: "int"},
+		{Name: "ratio", Type: "string"},
+		{Name: "flag", Type: "bool"},
+	}
+
+	// Values decoded from a YAML/JSON values file keep their native types.
+	overrides := map[string]any{
+		"name":  "from-file",
+		"port":  float64(9090),
+		"ratio": 0.5,
+		"flag":  true,
+	}
+
+	result, err := prompt.CollectVariables(vars, overrides, false, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "from-file", result["name"])
+	assert.Equal(t, 9090, result["port"])
+	assert.Equal(t, "0.5", result["ratio"])
+	assert.Equal(t, true, result["flag"])
+}
+
+func TestCollectVariables_UnknownOverride(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "project_name", Type: "string"},
+	}
+
+	overrides := map[string]any{
+		"project_name": "my-api",
+		"projcet_nmae": "typo",
+		"another":      "x",
+	}
+
+	_, err := prompt.CollectVariables(vars, overrides, true, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown variable(s) another, projcet_nmae")
+}
+
+func TestCollectVariables_CollectionTypesFromSetSyntax(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "services", Type: "list"},
+		{Name: "flow_list", Type: "list"},
+		{Name: "labels", Type: "map"},
+		{Name: "flow_map", Type: "map"},
+		{Name: "features", Type: "multichoice", Choices: []string{"docker", "ci", "otel"}},
+		{Name: "ratio", Type: "float"},
+	}
+
+	overrides := map[string]any{
+		"services":  "api, worker,,",
+		"flow_list": "[a, b, 3]",
+		"labels":    "team=core, tier=backend",
+		"flow_map":  "{env: prod, replicas: 3}",
+		"features":  "docker,otel",
+		"ratio":     "0.75",
+	}
+
+	result, err := prompt.CollectVariables(vars, overrides, false, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"api", "worker"}, result["services"])
+	assert.Equal(t, []string{"a", "b", "3"}, result["flow_list"])
+	assert.Equal(t, map[string]string{"team": "core", "tier": "backend"}, result["labels"])
+	assert.Equal(t, map[string]string{"env": "prod", "replicas": "3"}, result["flow_map"])
+	assert.Equal(t, []string{"docker", "otel"}, result["features"])
+	assert.InDelta(t, 0.75, result["ratio"], 0.0001)
+}
+
+func TestCollectVariables_CollectionTypesFromValues(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "services", Type: "list"},
+		{Name: "labels", Type: "map"},
+	}
+
+	overrides := map[string]any{
+		"services": []any{"api", "worker"},
+		"labels":   map[string]any{"team": "core", "replicas": 3},
+	}
+
+	result, err := prompt.CollectVariables(vars, overrides, false, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"api", "worker"}, result["services"])
+	assert.Equal(t, map[string]string{"team": "core", "replicas": "3"}, result["labels"])
+}
+
+func TestCollectVariables_CollectionTypeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		variable config.Variable
+		value    any
+		errMsg   string
+	}{
+		{
+			name:     "unknown multichoice item",
+			variable: config.Variable{Name: "features", Type: "multichoice", Choices: []string{"docker"}},
+			value:    "docker,k8s",
+			errMsg:   `"k8s" is not one of the available choices`,
+		},
+		{
+			name:     "map entry without equals",
+			variable: config.Variable{Name: "labels", Type: "map"},
+			value:    "team",
+			errMsg:   "expected key=value",
+		},
+		{
+			name:     "list for scalar type",
+			variable: config.Variable{Name: "name", Type: "string"},
+			value:    []any{"a"},
+			errMsg:   "a list is not valid",
+		},
+		{
+			name:     "list item fails validation",
+			variable: config.Variable{Name: "services", Type: "list", Validate: "^[a-z]+$"},
+			value:    "api,Bad",
+			errMsg:   `value "Bad" does not match`,
+		},
+		{
+			name:     "invalid float",
+			variable: config.Variable{Name: "ratio", Type: "float"},
+			value:    "half",
+			errMsg:   "invalid override",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			overrides := map[string]any{tt.variable.Name: tt.value}
+
+			_, err := prompt.CollectVariables([]config.Variable{tt.variable}, overrides, false, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestCollectVariables_SecretNotEchoedInErrors(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "token", Type: "secret", Validate: "^tok_"},
+	}
+
+	_, err := prompt.CollectVariables(vars, map[string]any{"token": "hunter2"}, false, nil)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestCollectVariables_ExtendedZeroValues(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "ratio", Type: "float"},
+		{Name: "services", Type: "list"},
+		{Name: "labels", Type: "map"},
+		{Name: "features", Type: "multichoice", Choices: []string{"a"}},
+		{Name: "token", Type: "secret"},
+	}
+
+	result, err := prompt.CollectVariables(vars, nil, true, nil)
+	require.NoError(t, err)
+
+	assert.IsType(t, 0.0, result["ratio"])
+	assert.Zero(t, result["ratio"])
+	assert.Equal(t, []string{}, result["services"])
+	assert.Equal(t, map[string]string{}, result["labels"])
+	assert.Equal(t, []string{}, result["features"])
+	assert.Empty(t, result["token"])
+}
+
+func TestCollect_WhenSkipsVariable(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "use_grpc", Type: "bool", Default: "false"},
+		{Name: "grpc_port", Type: "int", Default: "9090", Required: true, When: "{{ .use_grpc }}"},
+		{Name: "grpc_pkg", Type: "string", Required: true, When: "{{ .use_grpc }}"},
+	}
+
+	prompted := map[string]bool{}
+	promptFn := func(v *config.Variable, _ map[string]any) (string, error) {
+		prompted[v.Name] = true
+		return "", nil
+	}
+
+	collected, err := prompt.Collect(vars, map[string]any{"grpc_port": "1234"}, false, promptFn)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"grpc_port", "grpc_pkg"}, collected.Skipped)
+	assert.Equal(t, 9090, collected.Values["grpc_port"])
+	assert.Empty(t, collected.Values["grpc_pkg"])
+	assert.Equal(t, map[string]bool{"use_grpc": true}, prompted)
+}
+
+func TestCollect_WhenActive(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "use_grpc", Type: "bool", Default: "false"},
+		{Name: "grpc_port", Type: "int", Default: "9090", When: "{{ .use_grpc }}"},
+	}
+
+	collected, err := prompt.Collect(vars, map[string]any{"use_grpc": "true", "grpc_port": "1234"}, true, nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, collected.Skipped)
+	assert.Equal(t, 1234, collected.Values["grpc_port"])
+}
+
+func TestCollectVariables_ValidationRules(t *testing.T) {
+	t.Parallel()
+
+	minPort, maxPort := 1024.0, 65535.0
+	minLen, maxLen := 3, 8
+	maxItems := 1
+
+	tests := []struct {
+		name     string
+		variable config.Variable
+		value    any
+		errMsg   string
+	}{
+		{
+			name:     "below min",
+			variable: config.Variable{Name: "port", Type: "int", Min: &minPort, Max: &maxPort},
+			value:    "80",
+			errMsg:   "less than the minimum 1024",
+		},
+		{
+			name:     "above max",
+			variable: config.Variable{Name: "port", Type: "int", Min: &minPort, Max: &maxPort},
+			value:    70000,
+			errMsg:   "greater than the maximum 65535",
+		},
+		{
+			name:     "too short",
+			variable: config.Variable{Name: "name", Type: "string", MinLength: &minLen},
+			value:    "ab",
+			errMsg:   "at least 3 characters",
+		},
+		{
+			name:     "too long",
+			variable: config.Variable{Name: "name", Type: "string", MaxLength: &maxLen},
+			value:    "much-too-long",
+			errMsg:   "at most 8 characters",
+		},
+		{
+			name:     "too many items",
+			variable: config.Variable{Name: "services", Type: "list", MaxLength: &maxItems},
+			value:    "api,worker",
+			errMsg:   "at most 1 items",
+		},
+		{
+			name:     "not in",
+			variable: config.Variable{Name: "name", Type: "string", NotIn: []string{"test", "main"}},
+			value:    "main",
+			errMsg:   `value "main" is not allowed`,
+		},
+		{
+			name:     "custom message",
+			variable: config.Variable{Name: "name", Type: "string", Validate: "^[a-z]+$", Message: "use lowercase letters only"},
+			value:    "MyAPI",
+			errMsg:   "use lowercase letters only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			overrides := map[string]any{tt.variable.Name: tt.value}
+
+			_, err := prompt.CollectVariables([]config.Variable{tt.variable}, overrides, false, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestCollectVariables_AssertAcrossVariables(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "http_port", Type: "int", Default: "8080"},
+		{
+			Name:    "grpc_port",
+			Type:    "int",
+			Default: "9090",
+			Assert:  "{{ ne .grpc_port .http_port }}",
+			Message: "grpc_port must differ from http_port",
+		},
+	}
+
+	result, err := prompt.CollectVariables(vars, nil, true, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 9090, result["grpc_port"])
+
+	_, err = prompt.CollectVariables(vars, map[string]any{"grpc_port": "8080"}, true, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "grpc_port must differ from http_port")
+}
+
+func TestCollectVariables_DefaultIsValidated(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "project_name", Type: "string", Default: "My API", Validate: "^[a-z-]+$"},
+	}
+
+	_, err := prompt.CollectVariables(vars, nil, true, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `default for "project_name" failed validation`)
+}
@@ -0,0 +1,350 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+
+	"github.com/donaldgifford/forge/internal/config"
+)
+
+// Terminal prompts for variable values on an interactive terminal.
+// Its Prompt method satisfies PromptFn.
+type Terminal struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// tty is set when input is an interactive terminal, so that secret
+	// variables can be read without echo.
+	tty *os.File
+
+	// mu guards saved, the terminal state to restore while a secret is
+	// being read with echo disabled.
+	mu    sync.Mutex
+	saved *term.State
+}
+
+// NewTerminal creates a Terminal that reads answers from in and writes
+// prompts to out.
+func NewTerminal(in io.Reader, out io.Writer) *Terminal {
+	t := &Terminal{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+
+	if f, ok := in.(*os.File); ok && IsTerminal(f) {
+		t.tty = f
+	}
+
+	return t
+}
+
+// WithContext wraps fn so that a pending prompt returns ctx.Err() as soon as
+// ctx is cancelled (e.g., on Ctrl-C), instead of blocking on input.
+func WithContext(ctx context.Context, fn PromptFn) PromptFn {
+	return withContext(ctx, fn, nil)
+}
+
+// PromptContext returns t.Prompt wrapped with WithContext. When ctx is
+// cancelled during a secret prompt, terminal echo is restored before the
+// prompt returns, as the pending read never gets to restore it.
+func (t *Terminal) PromptContext(ctx context.Context) PromptFn {
+	return withContext(ctx, t.Prompt, t.restore)
+}
+
+// withContext implements WithContext, calling onCancel, if not nil, before
+// a pending prompt returns on cancellation.
+func withContext(ctx context.Context, fn PromptFn, onCancel func()) PromptFn {
+	if fn == nil {
+		return nil
+	}
+
+	type answer struct {
+		value string
+		err   error
+	}
+
+	return func(v *config.Variable, current map[string]any) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		ch := make(chan answer, 1)
+
+		go func() {
+			value, err := fn(v, current)
+			ch <- answer{value: value, err: err}
+		}()
+
+		select {
+		case a := <-ch:
+			return a.value, a.err
+		case <-ctx.Done():
+			if onCancel != nil {
+				onCancel()
+			}
+
+			return "", ctx.Err()
+		}
+	}
+}
+
+// IsTerminal reports whether f is connected to an interactive terminal.
+// Callers should fall back to non-interactive behavior when it is not.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(fd(f))
+}
+
+// fd returns the file descriptor of f as the int golang.org/x/term takes.
+func fd(f *os.File) int {
+	return int(f.Fd()) //nolint:gosec // file descriptors fit in an int
+}
+
+// Prompt asks the user for a value for v. The variable description and
+// rendered default are shown, choice and multichoice variables are presented
+// as a numbered menu, bool variables accept yes/no answers, and secret
+// variables are read without echo. Invalid answers are reported and the
+// question is asked again.
+//
+// An empty return value means "use the default". If input ends before a
+// valid answer is given, the last answer is returned as-is so that
+// CollectVariables can report the failure.
+func (t *Terminal) Prompt(v *config.Variable, current map[string]any) (string, error) {
+	defaultVal, err := renderDefault(v.Default, current)
+	if err != nil {
+		return "", fmt.Errorf("rendering default for %q: %w", v.Name, err)
+	}
+
+	if v.Description != "" {
+		t.printf("%s\n", v.Description)
+	}
+
+	for {
+		raw, eof, err := t.ask(v, defaultVal)
+		if err != nil {
+			return "", err
+		}
+
+		answer, checkErr := checkAnswer(raw, defaultVal, v, current)
+		if checkErr == nil || eof {
+			return answer, nil
+		}
+
+		t.printf("  %v\n", checkErr)
+	}
+}
+
+// ask prints the question for v and reads a single line of input.
+func (t *Terminal) ask(v *config.Variable, defaultVal string) (answer string, eof bool, err error) {
+	switch v.Type {
+	case "choice":
+		t.printMenu(v.Choices)
+		t.printf("Select %s%s: ", v.Name, defaultHint(defaultVal))
+	case "multichoice":
+		t.printMenu(v.Choices)
+		t.printf("Select %s (comma-separated)%s: ", v.Name, defaultHint(defaultVal))
+	case "bool":
+		t.printf("%s %s: ", v.Name, boolHint(defaultVal))
+	case "list":
+		t.printf("%s (comma-separated)%s: ", v.Name, defaultHint(defaultVal))
+	case "map":
+		t.printf("%s (key=value, comma-separated)%s: ", v.Name, defaultHint(defaultVal))
+	case "secret":
+		t.printf("%s: ", v.Name)
+
+		return t.readSecret()
+	default:
+		t.printf("%s%s: ", v.Name, defaultHint(defaultVal))
+	}
+
+	return t.readLine()
+}
+
+func (t *Terminal) printMenu(choices []string) {
+	for i, c := range choices {
+		t.printf("  %d) %s\n", i+1, c)
+	}
+}
+
+// readSecret reads a line with terminal echo disabled. Input that is not a
+// terminal is not echoed in the first place and is read as any other line.
+func (t *Terminal) readSecret() (line string, eof bool, err error) {
+	if t.tty == nil {
+		return t.readLine()
+	}
+
+	// Saved so that restore can turn echo back on if the prompt is
+	// cancelled while the read is pending.
+	if state, err := term.GetState(fd(t.tty)); err == nil {
+		t.mu.Lock()
+		t.saved = state
+		t.mu.Unlock()
+	}
+
+	// In line mode the terminal hands over one line per read, so nothing
+	// is left buffered in t.in for the password read to skip.
+	b, err := term.ReadPassword(fd(t.tty))
+
+	t.mu.Lock()
+	t.saved = nil
+	t.mu.Unlock()
+
+	// The user's newline was not echoed, so end the prompt line ourselves.
+	t.printf("\n")
+
+	if errors.Is(err, io.EOF) {
+		return strings.TrimSpace(string(b)), true, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("reading secret input: %w", err)
+	}
+
+	return strings.TrimSpace(string(b)), false, nil
+}
+
+// restore restores the terminal state saved by a pending readSecret.
+func (t *Terminal) restore() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.saved == nil {
+		return
+	}
+
+	if err := term.Restore(fd(t.tty), t.saved); err != nil {
+		t.printf("warning: could not restore terminal echo: %v\n", err)
+	}
+
+	// End the prompt line the user's Ctrl-C was typed on.
+	t.printf("\n")
+	t.saved = nil
+}
+
+// readLine reads one line of input, trimming surrounding whitespace.
+func (t *Terminal) readLine() (line string, eof bool, err error) {
+	line, err = t.in.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		return strings.TrimSpace(line), true, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("reading input: %w", err)
+	}
+
+	return strings.TrimSpace(line), false, nil
+}
+
+// printf writes to the prompt output. Output is best-effort; the answer is
+// still read from input if it fails.
+func (t *Terminal) printf(format string, args ...any) {
+	fmt.Fprintf(t.out, format, args...) //nolint:errcheck // best-effort prompt output
+}
+
+// checkAnswer normalizes a raw answer for v and validates the effective value
+// (the answer, or the default when the answer is empty). The normalized
+// answer is returned even when validation fails.
+func checkAnswer(raw, defaultVal string, v *config.Variable, current map[string]any) (string, error) {
+	answer := raw
+
+	switch v.Type {
+	case "choice":
+		answer = resolveChoice(raw, v.Choices)
+	case "multichoice":
+		answer = resolveMultiChoice(raw, v.Choices)
+	case "bool":
+		answer = resolveBool(raw)
+	}
+
+	effective := answer
+	if effective == "" {
+		effective = defaultVal
+	}
+
+	if effective == "" {
+		if v.Required {
+			return answer, fmt.Errorf("a value is required")
+		}
+
+		return answer, nil
+	}
+
+	if v.Type == "choice" && !slices.Contains(v.Choices, effective) {
+		return answer, fmt.Errorf("%q is not one of the available choices", effective)
+	}
+
+	val, err := coerceValue(effective, v.Type)
+	if err != nil {
+		return answer, fmt.Errorf("not a valid %s: %w", v.Type, err)
+	}
+
+	if err := validateValue(val, v, current); err != nil {
+		return answer, err
+	}
+
+	return answer, nil
+}
+
+// resolveChoice maps a 1-based menu number to the matching choice.
+// Anything else is returned unchanged so it can be matched literally.
+func resolveChoice(raw string, choices []string) string {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > len(choices) {
+		return raw
+	}
+
+	return choices[n-1]
+}
+
+// resolveMultiChoice maps each comma-separated menu number to its choice,
+// leaving names unchanged, and rejoins the items.
+func resolveMultiChoice(raw string, choices []string) string {
+	if strings.TrimSpace(raw) == "" {
+		return ""
+	}
+
+	items := strings.Split(raw, ",")
+	for i, item := range items {
+		items[i] = resolveChoice(strings.TrimSpace(item), choices)
+	}
+
+	return strings.Join(items, ",")
+}
+
+// resolveBool maps yes/no style answers to "true"/"false".
+// Unrecognized answers are returned unchanged so validation can reject them.
+func resolveBool(raw string) string {
+	switch strings.ToLower(raw) {
+	case "y", "yes":
+		return "true"
+	case "n", "no":
+		return "false"
+	default:
+		return raw
+	}
+}
+
+func defaultHint(defaultVal string) string {
+	if defaultVal == "" {
+		return ""
+	}
+
+	return " [" + defaultVal + "]"
+}
+
+func boolHint(defaultVal string) string {
+	if b, err := strconv.ParseBool(defaultVal); err == nil && b {
+		return "[Y/n]"
+	}
+
+	return "[y/N]"
+}
@@ -0,0 +1,275 @@
+package prompt_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/config"
+	"github.com/donaldgifford/forge/internal/prompt"
+)
+
+func TestTerminal_ShowsDescriptionAndRenderedDefault(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("\n"), &out)
+
+	v := &config.Variable{
+		Name:        "go_module",
+		Description: "Go module path",
+		Type:        "string",
+		Default:     "github.com/example/{{ .project_name }}",
+	}
+
+	answer, err := term.Prompt(v, map[string]any{"project_name": "my-api"})
+	require.NoError(t, err)
+
+	assert.Empty(t, answer, "empty answer means use the default")
+	assert.Contains(t, out.String(), "Go module path")
+	assert.Contains(t, out.String(), "go_module [github.com/example/my-api]: ")
+}
+
+func TestTerminal_ChoiceMenu(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("2\n"), &out)
+
+	v := &config.Variable{
+		Name:    "license",
+		Type:    "choice",
+		Choices: []string{"MIT", "Apache-2.0", "none"},
+		Default: "MIT",
+	}
+
+	answer, err := term.Prompt(v, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Apache-2.0", answer)
+	assert.Contains(t, out.String(), "  1) MIT\n  2) Apache-2.0\n  3) none\n")
+}
+
+func TestTerminal_ChoiceRepromptsOnUnknown(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("7\nGPL\nnone\n"), &out)
+
+	v := &config.Variable{Name: "license", Type: "choice", Choices: []string{"MIT", "none"}}
+
+	answer, err := term.Prompt(v, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "none", answer)
+	assert.Equal(t, 2, strings.Count(out.String(), "not one of the available choices"))
+}
+
+func TestTerminal_BoolYesNo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"y\n", "true"},
+		{"YES\n", "true"},
+		{"n\n", "false"},
+		{"false\n", "false"},
+		{"maybe\nno\n", "false"},
+		{"\n", ""},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		term := prompt.NewTerminal(strings.NewReader(tt.input), &out)
+
+		v := &config.Variable{Name: "use_grpc", Type: "bool", Default: "false"}
+
+		answer, err := term.Prompt(v, nil)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, answer, "input %q", tt.input)
+		assert.Contains(t, out.String(), "use_grpc [y/N]: ")
+	}
+}
+
+func TestTerminal_RepromptsOnValidationFailure(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("Bad Name\nmy-api\n"), &out)
+
+	v := &config.Variable{Name: "project_name", Type: "string", Validate: "^[a-z][a-z0-9-]*$"}
+
+	answer, err := term.Prompt(v, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-api", answer)
+	assert.Contains(t, out.String(), "does not match pattern")
+	assert.Equal(t, 2, strings.Count(out.String(), "project_name: "))
+}
+
+func TestTerminal_RepromptsWhenRequired(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("\nvalue\n"), &out)
+
+	v := &config.Variable{Name: "name", Type: "string", Required: true}
+
+	answer, err := term.Prompt(v, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "value", answer)
+	assert.Contains(t, out.String(), "a value is required")
+}
+
+func TestTerminal_EOFReturnsLastAnswer(t *testing.T) {
+	t.Parallel()
+
+	term := prompt.NewTerminal(strings.NewReader(""), &bytes.Buffer{})
+
+	v := &config.Variable{Name: "name", Type: "string", Required: true}
+
+	// Input ends without an answer: the prompter must not loop forever and
+	// CollectVariables reports the missing value.
+	_, err := prompt.CollectVariables([]config.Variable{*v}, nil, false, term.Prompt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is required")
+}
+
+func TestTerminal_WithCollectVariables(t *testing.T) {
+	t.Parallel()
+
+	vars := []config.Variable{
+		{Name: "project_name", Type: "string", Required: true},
+		{Name: "port", Type: "int", Default: "8080"},
+		{Name: "use_grpc", Type: "bool", Default: "false"},
+		{Name: "license", Type: "choice", Choices: []string{"MIT", "Apache-2.0"}, Default: "MIT"},
+	}
+
+	term := prompt.NewTerminal(strings.NewReader("my-api\nabc\n9090\nyes\n2\n"), &bytes.Buffer{})
+
+	result, err := prompt.CollectVariables(vars, nil, false, term.Prompt)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-api", result["project_name"])
+	assert.Equal(t, 9090, result["port"])
+	assert.Equal(t, true, result["use_grpc"])
+	assert.Equal(t, "Apache-2.0", result["license"])
+}
+
+func TestTerminal_MultiChoiceMenu(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("1, 3\n"), &out)
+
+	v := &config.Variable{Name: "features", Type: "multichoice", Choices: []string{"docker", "ci", "otel"}}
+
+	answer, err := term.Prompt(v, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "docker,otel", answer)
+	assert.Contains(t, out.String(), "  2) ci\n")
+	assert.Contains(t, out.String(), "Select features (comma-separated): ")
+}
+
+func TestTerminal_SecretHidesDefault(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("s3cret\n"), &out)
+
+	v := &config.Variable{Name: "token", Type: "secret", Default: "default-token"}
+
+	answer, err := term.Prompt(v, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "s3cret", answer)
+	assert.NotContains(t, out.String(), "default-token")
+	assert.NotContains(t, out.String(), "s3cret")
+}
+
+func TestTerminal_ListAndMapHints(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("a=1\n"), &out)
+
+	v := &config.Variable{Name: "labels", Type: "map"}
+
+	answer, err := term.Prompt(v, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a=1", answer)
+	assert.Contains(t, out.String(), "labels (key=value, comma-separated): ")
+}
+
+func TestTerminal_RepromptsWithCustomMessage(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	term := prompt.NewTerminal(strings.NewReader("80\n8080\n"), &out)
+
+	minPort := 1024.0
+	v := &config.Variable{Name: "port", Type: "int", Min: &minPort, Message: "port must be 1024 or higher"}
+
+	answer, err := term.Prompt(v, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "8080", answer)
+	assert.Contains(t, out.String(), "port must be 1024 or higher")
+}
+
+func TestWithContext_CancelUnblocksPrompt(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	block := make(chan struct{})
+	defer close(block)
+
+	fn := prompt.WithContext(ctx, func(*config.Variable, map[string]any) (string, error) {
+		<-block
+		return "never", nil
+	})
+
+	cancel()
+
+	_, err := fn(&config.Variable{Name: "project_name", Type: "string"}, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTerminal_PromptContextCancelsPendingPrompt(t *testing.T) {
+	t.Parallel()
+
+	in, w := io.Pipe()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	fn := prompt.NewTerminal(in, &bytes.Buffer{}).PromptContext(ctx)
+
+	_, err := fn(&config.Variable{Name: "token", Type: "secret"}, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWithContext_PassesAnswerThrough(t *testing.T) {
+	t.Parallel()
+
+	fn := prompt.WithContext(context.Background(), func(*config.Variable, map[string]any) (string, error) {
+		return "my-api", nil
+	})
+
+	answer, err := fn(&config.Variable{Name: "project_name", Type: "string"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "my-api", answer)
+	assert.Nil(t, prompt.WithContext(context.Background(), nil))
+}
@@ -0,0 +1,179 @@
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// coerceOverride converts an override value to the variable's Go type. Strings
+// (from --set) use the same syntax as defaults; decoded values from a values
+// file may already be lists or maps for collection types.
+func coerceOverride(value any, varType string) (any, error) {
+	switch val := value.(type) {
+	case []any:
+		if varType != "list" && varType != "multichoice" {
+			return nil, fmt.Errorf("a list is not valid for type %q", varType)
+		}
+
+		return stringItems(val)
+	case map[string]any:
+		if varType != "map" {
+			return nil, fmt.Errorf("a map is not valid for type %q", varType)
+		}
+
+		return stringValues(val)
+	default:
+		raw, err := overrideString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return coerceValue(raw, varType)
+	}
+}
+
+// overrideString converts a scalar override value to its string form so that
+// values from a values file go through the same validation and coercion as --set.
+func overrideString(value any) (string, error) {
+	switch val := value.(type) {
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case int:
+		return strconv.Itoa(val), nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+// parseList parses list syntax: either a YAML flow sequence ("[a, b]") or
+// comma-separated items ("a,b"). Surrounding whitespace and empty items are dropped.
+func parseList(raw string) ([]string, error) {
+	raw = strings.TrimSpace(raw)
+
+	if strings.HasPrefix(raw, "[") {
+		var items []any
+		if err := yaml.Unmarshal([]byte(raw), &items); err != nil {
+			return nil, fmt.Errorf("parsing list %q: %w", raw, err)
+		}
+
+		return stringItems(items)
+	}
+
+	items := []string{}
+
+	for item := range strings.SplitSeq(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// parseMap parses map syntax: either a YAML flow mapping ("{a: 1, b: 2}") or
+// comma-separated key=value pairs ("a=1,b=2").
+func parseMap(raw string) (map[string]string, error) {
+	raw = strings.TrimSpace(raw)
+
+	if strings.HasPrefix(raw, "{") {
+		var m map[string]any
+		if err := yaml.Unmarshal([]byte(raw), &m); err != nil {
+			return nil, fmt.Errorf("parsing map %q: %w", raw, err)
+		}
+
+		return stringValues(m)
+	}
+
+	m := map[string]string{}
+
+	for pair := range strings.SplitSeq(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid map entry %q: expected key=value", pair)
+		}
+
+		m[key] = strings.TrimSpace(value)
+	}
+
+	return m, nil
+}
+
+// stringItems converts decoded list items to strings. Nested values are rejected.
+func stringItems(items []any) ([]string, error) {
+	out := make([]string, 0, len(items))
+
+	for i, item := range items {
+		s, err := overrideString(item)
+		if err != nil {
+			return nil, fmt.Errorf("list item %d: %w", i, err)
+		}
+
+		out = append(out, s)
+	}
+
+	return out, nil
+}
+
+// stringValues converts decoded map values to strings. Nested values are rejected.
+func stringValues(m map[string]any) (map[string]string, error) {
+	out := make(map[string]string, len(m))
+
+	for k, v := range m {
+		s, err := overrideString(v)
+		if err != nil {
+			return nil, fmt.Errorf("map key %q: %w", k, err)
+		}
+
+		out[k] = s
+	}
+
+	return out, nil
+}
+
+// valueItems returns the string items of a coerced value for validation:
+// each element of a list, each value of a map (in key order), or the value itself.
+func valueItems(val any) []string {
+	switch v := val.(type) {
+	case []string:
+		return v
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		items := make([]string, 0, len(keys))
+		for _, k := range keys {
+			items = append(items, v[k])
+		}
+
+		return items
+	default:
+		s, err := overrideString(val)
+		if err != nil {
+			return []string{fmt.Sprint(val)}
+		}
+
+		return []string{s}
+	}
+}
@@ -0,0 +1,189 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/donaldgifford/forge/internal/config"
+)
+
+// validateValue checks a coerced value against the variable's validation rules.
+// When the variable declares a message, it replaces the description of any
+// rule failure so authors can explain the constraint in their own words.
+func validateValue(val any, v *config.Variable, current map[string]any) error {
+	err := checkRules(val, v, current)
+	if err == nil {
+		return nil
+	}
+
+	if v.Message != "" {
+		return errors.New(v.Message)
+	}
+
+	return err
+}
+
+// checkRules applies each validation rule in turn and returns the first failure.
+// For list and multichoice variables the regex and not_in rules apply to each
+// item, and for map variables to each value. Multichoice items must be one of
+// the declared choices.
+func checkRules(val any, v *config.Variable, current map[string]any) error {
+	items := valueItems(val)
+
+	if v.Type == "multichoice" {
+		for _, item := range items {
+			if !slices.Contains(v.Choices, item) {
+				return fmt.Errorf("%q is not one of the available choices: %s", item, strings.Join(v.Choices, ", "))
+			}
+		}
+	}
+
+	if err := checkPattern(items, v); err != nil {
+		return err
+	}
+
+	if err := checkNotIn(items, v); err != nil {
+		return err
+	}
+
+	if err := checkRange(val, v); err != nil {
+		return err
+	}
+
+	if err := checkLength(val, v); err != nil {
+		return err
+	}
+
+	return checkAssert(val, v, current)
+}
+
+func checkPattern(items []string, v *config.Variable) error {
+	if v.Validate == "" {
+		return nil
+	}
+
+	re, err := regexp.Compile(v.Validate)
+	if err != nil {
+		return fmt.Errorf("invalid validation regex %q: %w", v.Validate, err)
+	}
+
+	for _, item := range items {
+		if re.MatchString(item) {
+			continue
+		}
+
+		// Never echo secret values back in error messages.
+		if v.Type == "secret" {
+			return fmt.Errorf("value does not match pattern %q", v.Validate)
+		}
+
+		return fmt.Errorf("value %q does not match pattern %q", item, v.Validate)
+	}
+
+	return nil
+}
+
+func checkNotIn(items []string, v *config.Variable) error {
+	for _, item := range items {
+		if !slices.Contains(v.NotIn, item) {
+			continue
+		}
+
+		if v.Type == "secret" {
+			return fmt.Errorf("value is not allowed")
+		}
+
+		return fmt.Errorf("value %q is not allowed", item)
+	}
+
+	return nil
+}
+
+// checkRange enforces min and max for int and float variables.
+func checkRange(val any, v *config.Variable) error {
+	var n float64
+
+	switch num := val.(type) {
+	case int:
+		n = float64(num)
+	case float64:
+		n = num
+	default:
+		return nil
+	}
+
+	if v.Min != nil && n < *v.Min {
+		return fmt.Errorf("value %v is less than the minimum %v", val, *v.Min)
+	}
+
+	if v.Max != nil && n > *v.Max {
+		return fmt.Errorf("value %v is greater than the maximum %v", val, *v.Max)
+	}
+
+	return nil
+}
+
+// checkLength enforces min_length and max_length: the number of characters
+// for string values, or the number of entries for lists and maps.
+func checkLength(val any, v *config.Variable) error {
+	if v.MinLength == nil && v.MaxLength == nil {
+		return nil
+	}
+
+	var (
+		n    int
+		unit string
+	)
+
+	switch typed := val.(type) {
+	case string:
+		n, unit = utf8.RuneCountInString(typed), "characters"
+	case []string:
+		n, unit = len(typed), "items"
+	case map[string]string:
+		n, unit = len(typed), "entries"
+	default:
+		return nil
+	}
+
+	if v.MinLength != nil && n < *v.MinLength {
+		return fmt.Errorf("must have at least %d %s, got %d", *v.MinLength, unit, n)
+	}
+
+	if v.MaxLength != nil && n > *v.MaxLength {
+		return fmt.Errorf("must have at most %d %s, got %d", *v.MaxLength, unit, n)
+	}
+
+	return nil
+}
+
+// checkAssert evaluates the assert expression against the variables collected
+// so far plus the value being validated.
+func checkAssert(val any, v *config.Variable, current map[string]any) error {
+	if v.Assert == "" {
+		return nil
+	}
+
+	scope := maps.Clone(current)
+	if scope == nil {
+		scope = make(map[string]any, 1)
+	}
+
+	scope[v.Name] = val
+
+	ok, err := evaluateExpr(v.Assert, scope)
+	if err != nil {
+		return fmt.Errorf("evaluating assert: %w", err)
+	}
+
+	if !ok {
+		return fmt.Errorf("assertion %q failed", v.Assert)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package registry
+
+// ResolveCommit returns the commit the registry content at registryRoot
+// corresponds to: HEAD of the git checkout holding it (a local clone, or a
+// git source fetched with go-getter), or else, for a registry no git
+// checkout tracks, the latest_commit recorded in registry.yaml for the
+// blueprint at blueprintPath. It returns an empty string when neither is
+// known, including for a checkout with uncommitted changes under
+// registryRoot: HEAD does not hold its content.
+func ResolveCommit(registryRoot, blueprintPath string) string {
+	if commit, tracked := gitCommit(registryRoot); tracked {
+		return commit
+	}
+
+	reg, err := LoadIndex(registryRoot)
+	if err != nil {
+		return ""
+	}
+
+	entry, err := FindBlueprint(reg, blueprintPath)
+	if err != nil {
+		return ""
+	}
+
+	return entry.LatestCommit
+}
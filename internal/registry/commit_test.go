@@ -0,0 +1,107 @@
+package registry_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/registry"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test",
+		"GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=test",
+		"GIT_COMMITTER_EMAIL=test@test.com",
+	)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v failed: %s", args, out)
+
+	return strings.TrimSpace(string(out))
+}
+
+const commitIndex = `apiVersion: v1
+name: test
+blueprints:
+  - name: go/api
+    path: go/api
+    latest_commit: "abc123"
+`
+
+func TestResolveCommit_GitCheckout(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.yaml"), []byte(commitIndex), 0o644))
+
+	runGit(t, dir, "init")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", "init")
+	head := runGit(t, dir, "rev-parse", "HEAD")
+
+	assert.Equal(t, head, registry.ResolveCommit(dir, "go/api"))
+}
+
+func TestResolveCommit_DirtyCheckout(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.yaml"), []byte(commitIndex), 0o644))
+
+	runGit(t, dir, "init")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", "init")
+
+	// HEAD does not hold the registry's content, and neither does the
+	// commit the index records.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "go", "api"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go", "api", "blueprint.yaml"), []byte("name: api\n"), 0o644))
+
+	assert.Empty(t, registry.ResolveCommit(dir, "go/api"))
+}
+
+func TestResolveCommit_UntrackedInEnclosingRepo(t *testing.T) {
+	t.Parallel()
+
+	// A repository enclosing the registry, such as a git-tracked home
+	// directory, that does not track it.
+	home := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".bashrc"), []byte("# dotfiles\n"), 0o644))
+	runGit(t, home, "init")
+	runGit(t, home, "add", ".bashrc")
+	runGit(t, home, "commit", "-m", "dotfiles")
+
+	dir := filepath.Join(home, "registry")
+	require.NoError(t, os.MkdirAll(dir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.yaml"), []byte(commitIndex), 0o644))
+
+	assert.Equal(t, "abc123", registry.ResolveCommit(dir, "go/api"))
+}
+
+func TestResolveCommit_IndexFallback(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.yaml"), []byte(commitIndex), 0o644))
+
+	assert.Equal(t, "abc123", registry.ResolveCommit(dir, "go/api"))
+	assert.Empty(t, registry.ResolveCommit(dir, "go/cli"))
+}
+
+func TestResolveCommit_Unknown(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, registry.ResolveCommit(t.TempDir(), "go/api"))
+}
@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Checkout checks out ref (a tag, branch or commit) of the git repository
+// holding the registry at root into a temporary clone. It returns the
+// registry's directory in the clone and a function that removes the clone.
+// A registry in a subdirectory of its repository keeps that subdirectory.
+func Checkout(ctx context.Context, root, ref string) (dir string, cleanup func(), err error) {
+	top, err := runGit(ctx, root, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, fmt.Errorf("registry %s is not a git repository: %w", root, err)
+	}
+
+	prefix, err := runGit(ctx, root, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "forge-checkout-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temp directory: %w", err)
+	}
+
+	cleanup = func() { _ = os.RemoveAll(tmpDir) }
+
+	if _, err := runGit(ctx, "", "clone", "--quiet", "--no-checkout", top, tmpDir); err != nil {
+		cleanup()
+
+		return "", nil, fmt.Errorf("cloning registry %s: %w", root, err)
+	}
+
+	if _, err := runGit(ctx, tmpDir, "-c", "advice.detachedHead=false", "checkout", "--quiet", ref, "--"); err != nil {
+		cleanup()
+
+		return "", nil, fmt.Errorf("checking out %s of registry %s: %w", ref, root, err)
+	}
+
+	return filepath.Join(tmpDir, prefix), cleanup, nil
+}
+
+// gitCommit returns the commit checked out in the git work tree holding
+// dir if dir is tracked by it and its content is that of the commit.
+// tracked reports whether the work tree tracks dir at all; when it does
+// but dir has uncommitted changes or untracked files, commit is empty, as
+// no commit holds its content.
+func gitCommit(dir string) (commit string, tracked bool) {
+	// An enclosing repository, such as a git-tracked home directory, does
+	// not hold a registry it does not track.
+	if _, err := runGit(context.Background(), dir, "ls-files", "--error-unmatch", "--", "."); err != nil {
+		return "", false
+	}
+
+	status, err := runGit(context.Background(), dir, "status", "--porcelain", "--", ".")
+	if err != nil || status != "" {
+		return "", true
+	}
+
+	head, err := runGit(context.Background(), dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", true
+	}
+
+	return head, true
+}
+
+// gitTags returns the tags of the git repository holding dir, or nil if
+// dir is not in one.
+func gitTags(dir string) []string {
+	out, err := runGit(context.Background(), dir, "tag", "--list")
+	if err != nil || out == "" {
+		return nil
+	}
+
+	return strings.Split(out, "\n")
+}
+
+// runGit runs git in dir (the current directory if empty) and returns its
+// trimmed standard output.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,74 @@
+package registry_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/registry"
+)
+
+func TestCheckout(t *testing.T) {
+	t.Parallel()
+
+	dir := writeVersionedRegistry(t, "go/api/v1.0.0", "go/api/v2.0.0")
+
+	checkout, cleanup, err := registry.Checkout(context.Background(), dir, "go/api/v1.0.0")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(checkout, "go", "api", "blueprint.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "version: go/api/v1.0.0")
+
+	// The registry itself is left at its own checkout.
+	content, err = os.ReadFile(filepath.Join(dir, "go", "api", "blueprint.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "version: go/api/v2.0.0")
+
+	cleanup()
+	assert.NoDirExists(t, checkout)
+}
+
+func TestCheckout_RegistryInSubdirectory(t *testing.T) {
+	t.Parallel()
+
+	repo := t.TempDir()
+	root := filepath.Join(repo, "blueprints")
+	require.NoError(t, os.MkdirAll(root, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "registry.yaml"), []byte("name: v1\n"), 0o644))
+
+	runGit(t, repo, "init")
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "-m", "v1")
+	runGit(t, repo, "tag", "v1.0.0")
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "registry.yaml"), []byte("name: v2\n"), 0o644))
+	runGit(t, repo, "commit", "-am", "v2")
+
+	checkout, cleanup, err := registry.Checkout(context.Background(), root, "v1.0.0")
+	require.NoError(t, err)
+
+	defer cleanup()
+
+	content, err := os.ReadFile(filepath.Join(checkout, "registry.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: v1\n", string(content))
+}
+
+func TestCheckout_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := registry.Checkout(context.Background(), t.TempDir(), "v1.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a git repository")
+
+	dir := writeVersionedRegistry(t, "v1.0.0")
+
+	_, _, err = registry.Checkout(context.Background(), dir, "v9.9.9")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checking out v9.9.9")
+}
@@ -3,6 +3,8 @@ package registry
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -17,7 +19,9 @@ type ResolvedBlueprint struct {
 	// Ref is the git ref to fetch (tag, branch, commit). Empty means latest/HEAD.
 	Ref string
 
-	// Standalone indicates this is a standalone blueprint (no registry.yaml expected).
+	// Standalone indicates this is a standalone blueprint (no registry.yaml expected):
+	// a repository or directory with blueprint.yaml at its root, which RegistryURL
+	// locates. BlueprintPath is then empty.
 	Standalone bool
 }
 
@@ -30,23 +34,22 @@ type ResolvedBlueprint struct {
 //   - "github.com/acme/blueprints//go/api?ref=v2.1.0" — full URL with ref
 //   - "git@github.com:someone/blueprint.git" — standalone (SSH)
 //   - "https://github.com/someone/blueprint.git" — standalone (HTTPS)
+//   - "file:///srv/git/blueprint.git" — standalone (local git repository)
+//   - "./blueprint", "/path/to/blueprint" — standalone (local directory)
+//
+// Standalone references take a ref as a query parameter, e.g.
+// "https://github.com/someone/blueprint.git?ref=v1.0.0".
 func Resolve(input, defaultRegistryURL string) (*ResolvedBlueprint, error) {
 	if input == "" {
 		return nil, fmt.Errorf("blueprint reference cannot be empty")
 	}
 
-	// SSH git URL: git@host:owner/repo.git
-	if strings.HasPrefix(input, "git@") {
-		return &ResolvedBlueprint{
-			RegistryURL: input,
-			Standalone:  true,
-		}, nil
-	}
+	if isStandalone(input) {
+		source, ref := splitQueryRef(input)
 
-	// HTTPS .git URL: standalone blueprint
-	if strings.HasSuffix(input, ".git") {
 		return &ResolvedBlueprint{
-			RegistryURL: input,
+			RegistryURL: source,
+			Ref:         ref,
 			Standalone:  true,
 		}, nil
 	}
@@ -60,6 +63,20 @@ func Resolve(input, defaultRegistryURL string) (*ResolvedBlueprint, error) {
 	return resolveShortName(input, defaultRegistryURL)
 }
 
+// isStandalone reports whether input refers to a standalone blueprint: an
+// SSH git URL (git@host:owner/repo.git), a URL ending in .git, a file://
+// URL or a local path.
+func isStandalone(input string) bool {
+	source, _, _ := strings.Cut(input, "?")
+
+	switch {
+	case strings.HasPrefix(source, "git@"), strings.HasSuffix(source, ".git"), strings.HasPrefix(source, "file://"):
+		return true
+	default:
+		return filepath.IsAbs(source) || strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+	}
+}
+
 // resolveFullURL parses a go-getter URL like "github.com/acme/blueprints//go/api?ref=v2.1.0".
 func resolveFullURL(input string) (*ResolvedBlueprint, error) {
 	parts := strings.SplitN(input, "//", 2)
@@ -133,12 +150,17 @@ func splitQueryRef(subpathAndQuery string) (subpath, ref string) {
 }
 
 // GetterURL constructs the full go-getter URL from a resolved blueprint.
+// Standalone blueprints served over HTTP(S) or file:// are fetched with git.
 func (r *ResolvedBlueprint) GetterURL() string {
+	url := r.RegistryURL + "//" + r.BlueprintPath
+
 	if r.Standalone {
-		return r.RegistryURL
-	}
+		url = r.RegistryURL
 
-	url := r.RegistryURL + "//" + r.BlueprintPath
+		if scheme, _, ok := strings.Cut(url, "://"); ok && slices.Contains([]string{"http", "https", "file"}, scheme) {
+			url = "git::" + url
+		}
+	}
 
 	if r.Ref != "" {
 		url += "?ref=" + r.Ref
@@ -77,6 +77,32 @@ func TestResolve_HTTPSStandalone(t *testing.T) {
 	assert.True(t, resolved.Standalone)
 }
 
+func TestResolve_LocalStandalone(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		source string
+		ref    string
+	}{
+		"file:///srv/git/blueprint.git":          {source: "file:///srv/git/blueprint.git"},
+		"file:///srv/git/bp.git?ref=v1.0.0":      {source: "file:///srv/git/bp.git", ref: "v1.0.0"},
+		"https://github.com/x/bp.git?ref=v2.0.0": {source: "https://github.com/x/bp.git", ref: "v2.0.0"},
+		"./blueprint":                            {source: "./blueprint"},
+		"../blueprint?ref=main":                  {source: "../blueprint", ref: "main"},
+		"/srv/blueprints/solo":                   {source: "/srv/blueprints/solo"},
+	}
+
+	for input, tt := range tests {
+		resolved, err := registry.Resolve(input, "github.com/acme/blueprints")
+		require.NoError(t, err, input)
+
+		assert.True(t, resolved.Standalone, input)
+		assert.Equal(t, tt.source, resolved.RegistryURL, input)
+		assert.Equal(t, tt.ref, resolved.Ref, input)
+		assert.Empty(t, resolved.BlueprintPath, input)
+	}
+}
+
 func TestResolve_EmptyInput(t *testing.T) {
 	t.Parallel()
 
@@ -134,6 +160,23 @@ func TestResolvedBlueprint_GetterURL(t *testing.T) {
 			},
 			expected: "git@github.com:someone/blueprint.git",
 		},
+		{
+			name: "standalone blueprint over https with ref",
+			resolved: registry.ResolvedBlueprint{
+				RegistryURL: "https://github.com/someone/blueprint.git",
+				Ref:         "v1.0.0",
+				Standalone:  true,
+			},
+			expected: "git::https://github.com/someone/blueprint.git?ref=v1.0.0",
+		},
+		{
+			name: "standalone blueprint in a local repository",
+			resolved: registry.ResolvedBlueprint{
+				RegistryURL: "file:///srv/git/blueprint.git",
+				Standalone:  true,
+			},
+			expected: "git::file:///srv/git/blueprint.git",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,312 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+)
+
+// Version is a release of a blueprint.
+type Version struct {
+	Version *version.Version
+
+	// Ref is the git ref holding the release: a tag, or the commit recorded
+	// in registry.yaml. It is empty for the release the registry holds when
+	// it records no commit for it.
+	Ref string
+}
+
+// Constraint is a version range a blueprint reference pins a project to.
+type Constraint struct {
+	// constraints is nil for "latest", which matches every release.
+	constraints version.Constraints
+}
+
+// IsConstraint reports whether ref, the part of a blueprint reference after
+// "@", is a version constraint ("latest", "^2.0", "~1.4", ">=1.2 <2")
+// rather than a literal git ref such as a tag, branch or revision
+// ("HEAD~1", "main^"). Only a ref that parses as a constraint is one.
+func IsConstraint(ref string) bool {
+	if ref == "latest" {
+		return true
+	}
+
+	if !strings.ContainsAny(ref, "^~<>= ,") {
+		return false
+	}
+
+	_, err := ParseConstraint(ref)
+
+	return err == nil
+}
+
+// ParseConstraint parses a version constraint. It accepts "latest" and
+// space- or comma-separated comparisons (=, !=, >, <, >=, <=, ~>), plus
+// caret and tilde ranges as npm and Cargo know them:
+//
+//   - ^1.2.3 allows changes that keep the leftmost non-zero segment
+//     (>=1.2.3 <2.0.0; ^0.3 is >=0.3 <0.4);
+//   - ~1.4.2 allows patch changes (>=1.4.2 <1.5.0), ~1 minor ones (<2).
+func ParseConstraint(s string) (*Constraint, error) {
+	if strings.TrimSpace(s) == "latest" {
+		return &Constraint{}, nil
+	}
+
+	var terms []string
+
+	for _, term := range constraintTerms(s) {
+		expanded, err := expandRange(term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+
+		terms = append(terms, expanded...)
+	}
+
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("invalid version constraint %q: empty", s)
+	}
+
+	cs, err := version.NewConstraint(strings.Join(terms, ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+	}
+
+	return &Constraint{constraints: cs}, nil
+}
+
+// Check reports whether v satisfies the constraint. "latest" matches every
+// release that is not a prerelease.
+func (c *Constraint) Check(v *version.Version) bool {
+	if c.constraints == nil {
+		return v.Prerelease() == ""
+	}
+
+	return c.constraints.Check(v)
+}
+
+// constraintTerms splits a constraint into its comparisons, joining an
+// operator separated from its version by a space (">= 1.2").
+func constraintTerms(s string) []string {
+	var terms []string
+
+	pending := ""
+
+	for _, field := range strings.Fields(strings.ReplaceAll(s, ",", " ")) {
+		if strings.Trim(field, "^~<>=!") == "" {
+			pending += field
+
+			continue
+		}
+
+		terms = append(terms, pending+field)
+		pending = ""
+	}
+
+	if pending != "" {
+		terms = append(terms, pending)
+	}
+
+	return terms
+}
+
+// expandRange rewrites a caret or tilde range as the comparisons go-version
+// understands. Other terms are returned as they are.
+func expandRange(term string) ([]string, error) {
+	var (
+		op  string
+		ver string
+	)
+
+	switch {
+	case strings.HasPrefix(term, "~>"):
+		return []string{term}, nil
+	case strings.HasPrefix(term, "^"), strings.HasPrefix(term, "~"):
+		op, ver = term[:1], term[1:]
+	default:
+		return []string{term}, nil
+	}
+
+	segs, err := versionSegments(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	// The segment that may not change: the leftmost non-zero one for a
+	// caret, the minor one (major if only that is given) for a tilde.
+	fixed := min(1, len(segs)-1)
+	if op == "^" {
+		fixed = len(segs) - 1
+
+		for i, seg := range segs {
+			if seg != 0 {
+				fixed = i
+
+				break
+			}
+		}
+	}
+
+	upper := make([]string, len(segs))
+	for i := range segs {
+		switch {
+		case i < fixed:
+			upper[i] = strconv.Itoa(segs[i])
+		case i == fixed:
+			upper[i] = strconv.Itoa(segs[i] + 1)
+		default:
+			upper[i] = "0"
+		}
+	}
+
+	return []string{">=" + ver, "<" + strings.Join(upper, ".")}, nil
+}
+
+// versionSegments returns the numeric segments given in ver, e.g. [1 4] for
+// "v1.4".
+func versionSegments(ver string) ([]int, error) {
+	if _, err := version.NewVersion(ver); err != nil {
+		return nil, err
+	}
+
+	core, _, _ := strings.Cut(strings.TrimPrefix(ver, "v"), "-")
+	core, _, _ = strings.Cut(core, "+")
+
+	var segs []int
+
+	for part := range strings.SplitSeq(core, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", ver)
+		}
+
+		segs = append(segs, n)
+	}
+
+	return segs, nil
+}
+
+// Versions returns the releases of the blueprint at blueprintPath in the
+// registry at registryRoot, oldest first. Releases are found from git tags
+// of the registry repository: tags scoped to the blueprint
+// ("go/api/v2.1.0") or, if it has none, registry-wide tags ("v2.1.0").
+// Without tags, the versions listed for the blueprint in registry.yaml are
+// used.
+func Versions(registryRoot, blueprintPath string) ([]Version, error) {
+	versions := tagVersions(gitTags(registryRoot), blueprintPath)
+
+	if len(versions) == 0 {
+		var err error
+
+		versions, err = indexVersions(registryRoot, blueprintPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortStableFunc(versions, func(a, b Version) int { return a.Version.Compare(b.Version) })
+
+	return slices.CompactFunc(versions, func(a, b Version) bool { return a.Version.Equal(b.Version) }), nil
+}
+
+// tagVersions returns the releases of blueprintPath among tags.
+func tagVersions(tags []string, blueprintPath string) []Version {
+	var scoped, global []Version
+
+	for _, tag := range tags {
+		name, isScoped := strings.CutPrefix(tag, blueprintPath+"/")
+		if !isScoped && strings.Contains(tag, "/") {
+			continue
+		}
+
+		v, err := version.NewVersion(name)
+		if err != nil {
+			continue
+		}
+
+		if isScoped {
+			scoped = append(scoped, Version{Version: v, Ref: tag})
+		} else {
+			global = append(global, Version{Version: v, Ref: tag})
+		}
+	}
+
+	if len(scoped) > 0 {
+		return scoped
+	}
+
+	return global
+}
+
+// indexVersions returns the releases of blueprintPath listed in
+// registry.yaml: its current version and its version history.
+func indexVersions(registryRoot, blueprintPath string) ([]Version, error) {
+	reg, err := LoadIndex(registryRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := FindBlueprint(reg, blueprintPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+
+	if v, err := version.NewVersion(entry.Version); err == nil {
+		versions = append(versions, Version{Version: v, Ref: entry.LatestCommit})
+	}
+
+	for _, release := range entry.Versions {
+		v, err := version.NewVersion(release.Version)
+		if err != nil {
+			return nil, fmt.Errorf("blueprint %s: invalid version %q in registry.yaml: %w", blueprintPath, release.Version, err)
+		}
+
+		if release.Commit == "" {
+			return nil, fmt.Errorf("blueprint %s: version %s in registry.yaml has no commit", blueprintPath, release.Version)
+		}
+
+		versions = append(versions, Version{Version: v, Ref: release.Commit})
+	}
+
+	return versions, nil
+}
+
+// ResolveVersion returns the highest release of the blueprint at
+// blueprintPath that satisfies constraint (see ParseConstraint and
+// Versions).
+func ResolveVersion(registryRoot, blueprintPath, constraint string) (*Version, error) {
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := Versions(registryRoot, blueprintPath)
+	if err != nil {
+		return nil, fmt.Errorf("listing versions of blueprint %s: %w", blueprintPath, err)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf(
+			"no versions of blueprint %s found — tag releases as %s/vX.Y.Z or vX.Y.Z, or list them in registry.yaml",
+			blueprintPath, blueprintPath)
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if c.Check(versions[i].Version) {
+			return &versions[i], nil
+		}
+	}
+
+	available := make([]string, len(versions))
+	for i := range versions {
+		available[i] = versions[i].Version.String()
+	}
+
+	return nil, fmt.Errorf("no version of blueprint %s matches %q (available: %s)",
+		blueprintPath, constraint, strings.Join(available, ", "))
+}
@@ -0,0 +1,169 @@
+package registry_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/donaldgifford/forge/internal/registry"
+)
+
+func TestIsConstraint(t *testing.T) {
+	t.Parallel()
+
+	for _, ref := range []string{"latest", "^2.0", "~1.4", ">=1.2 <2", ">= 1.2, < 2", "~> 1.4"} {
+		assert.True(t, registry.IsConstraint(ref), ref)
+	}
+
+	for _, ref := range []string{"", "v2.1.0", "main", "go/api/v2.1.0", "abc123", "HEAD~1", "main^", "v1.0^{}"} {
+		assert.False(t, registry.IsConstraint(ref), ref)
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"^2.0", "2.0.0", true},
+		{"^2.0", "2.9.1", true},
+		{"^2.0", "3.0.0", false},
+		{"^2.0", "1.9.0", false},
+		{"^0.3", "0.3.5", true},
+		{"^0.3", "0.4.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.4", "1.4.9", true},
+		{"~1.4", "1.5.0", false},
+		{"~1.4.2", "1.4.1", false},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+		{">=1.2 <2", "1.2.0", true},
+		{">=1.2 <2", "2.0.0", false},
+		{">= 1.2, < 2", "1.5.0", true},
+		{"~> 1.4.0", "1.9.0", false},
+		{"v1.2.0", "1.2.0", true},
+		{"latest", "9.9.9", true},
+		{"latest", "10.0.0-rc.1", false},
+		{"^2.0", "2.1.0-rc.1", false},
+	}
+
+	for _, tt := range tests {
+		c, err := registry.ParseConstraint(tt.constraint)
+		require.NoError(t, err, tt.constraint)
+
+		assert.Equal(t, tt.want, c.Check(version.Must(version.NewVersion(tt.version))), "%s vs %s", tt.constraint, tt.version)
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, constraint := range []string{"^", "^x.y", ">=", "~1.a"} {
+		_, err := registry.ParseConstraint(constraint)
+		require.Error(t, err, constraint)
+		assert.Contains(t, err.Error(), "invalid version constraint")
+	}
+}
+
+// writeVersionedRegistry creates a git registry holding go/api, committing
+// and tagging each release in turn with the given tag.
+func writeVersionedRegistry(t *testing.T, tags ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "go", "api"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.yaml"),
+		[]byte("apiVersion: v1\nname: test\nblueprints:\n  - name: go/api\n    path: go/api\n"), 0o644))
+
+	runGit(t, dir, "init")
+
+	for _, tag := range tags {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go", "api", "blueprint.yaml"),
+			[]byte("apiVersion: v1\nname: api\nversion: "+tag+"\n"), 0o644))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-m", tag)
+		runGit(t, dir, "tag", tag)
+	}
+
+	return dir
+}
+
+func TestResolveVersion_ScopedTags(t *testing.T) {
+	t.Parallel()
+
+	dir := writeVersionedRegistry(t, "go/api/v1.0.0", "go/api/v1.1.0", "go/api/v2.0.0", "v9.0.0", "go/cli/v3.0.0")
+
+	tests := map[string]string{
+		"^1.0":     "go/api/v1.1.0",
+		"~1.0":     "go/api/v1.0.0",
+		">=1.1 <2": "go/api/v1.1.0",
+		"latest":   "go/api/v2.0.0",
+	}
+
+	for constraint, wantRef := range tests {
+		v, err := registry.ResolveVersion(dir, "go/api", constraint)
+		require.NoError(t, err, constraint)
+		assert.Equal(t, wantRef, v.Ref, constraint)
+	}
+
+	_, err := registry.ResolveVersion(dir, "go/api", "^3")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no version of blueprint go/api matches "^3" (available: 1.0.0, 1.1.0, 2.0.0)`)
+}
+
+func TestResolveVersion_RegistryTags(t *testing.T) {
+	t.Parallel()
+
+	dir := writeVersionedRegistry(t, "v1.0.0", "v1.2.0", "v2.0.0")
+
+	v, err := registry.ResolveVersion(dir, "go/api", "^1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.0", v.Ref)
+	assert.Equal(t, "1.2.0", v.Version.String())
+}
+
+func TestResolveVersion_IndexHistory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.yaml"), []byte(`apiVersion: v1
+name: test
+blueprints:
+  - name: go/api
+    path: go/api
+    version: "2.1.0"
+    latest_commit: ccc
+    versions:
+      - version: "2.0.0"
+        commit: bbb
+      - version: "1.4.0"
+        commit: aaa
+`), 0o644))
+
+	v, err := registry.ResolveVersion(dir, "go/api", "~1.4")
+	require.NoError(t, err)
+	assert.Equal(t, "aaa", v.Ref)
+
+	v, err = registry.ResolveVersion(dir, "go/api", "^2")
+	require.NoError(t, err)
+	assert.Equal(t, "ccc", v.Ref)
+}
+
+func TestResolveVersion_NoVersions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "registry.yaml"),
+		[]byte("apiVersion: v1\nname: test\nblueprints:\n  - name: go/api\n    path: go/api\n"), 0o644))
+
+	_, err := registry.ResolveVersion(dir, "go/api", "^1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no versions of blueprint go/api found")
+}
@@ -33,7 +33,7 @@ func ReportConflicts(w io.Writer, files []ConflictFile) error {
 	}
 
 	for _, f := range files {
-		if _, err := fmt.Fprintf(w, "  CONFLICT %s (%d conflict region(s))\n", f.Path, len(f.Conflicts)); err != nil {
+		if _, err := fmt.Fprintf(w, "  CONFLICT %s (%d conflict region(s))%s\n", f.Path, len(f.Conflicts), conflictLines(f.Conflicts)); err != nil {
 			return fmt.Errorf("writing conflict report: %w", err)
 		}
 	}
@@ -45,8 +45,30 @@ func ReportConflicts(w io.Writer, files []ConflictFile) error {
 	return &ConflictError{Files: files}
 }
 
-// StripConflictMarkers resolves all conflict regions in content by keeping
-// the specified side ("local" or "remote").
+// conflictLines lists the lines of the merged file the conflicts cover, as
+// " at lines 3-7, 12-16". Conflicts without lines, such as those of binary
+// files, are left out.
+func conflictLines(conflicts []Conflict) string {
+	var ranges []string
+
+	for i := range conflicts {
+		r := conflicts[i].Merged
+		if r.Count == 0 {
+			continue
+		}
+
+		ranges = append(ranges, fmt.Sprintf("%d-%d", r.Start, r.Start+r.Count-1))
+	}
+
+	if len(ranges) == 0 {
+		return ""
+	}
+
+	return " at lines " + strings.Join(ranges, ", ")
+}
+
+// StripConflictMarkers resolves all conflict regions in content, in either
+// ConflictStyle, by keeping the specified side ("local" or "remote").
 func StripConflictMarkers(content, keepSide string) string {
 	var result []string
 
@@ -61,6 +83,9 @@ func StripConflictMarkers(content, keepSide string) string {
 			inConflict = true
 			inLocal = true
 			inRemote = false
+		case inConflict && inLocal && strings.HasPrefix(line, "||||||| "):
+			// The base section of a diff3-style conflict is dropped.
+			inLocal = false
 		case inConflict && line == "=======":
 			inLocal = false
 			inRemote = true
@@ -55,6 +55,26 @@ func TestReportConflicts_WithConflicts(t *testing.T) {
 	assert.Contains(t, output, "Resolve conflicts manually")
 }
 
+func TestReportConflicts_LineRanges(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	files := []forgesync.ConflictFile{
+		{
+			Path: "Makefile",
+			Conflicts: []forgesync.Conflict{
+				{Merged: forgesync.LineRange{Start: 3, Count: 5}},
+				{Merged: forgesync.LineRange{Start: 12, Count: 5}},
+			},
+		},
+	}
+
+	err := forgesync.ReportConflicts(&buf, files)
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "CONFLICT Makefile (2 conflict region(s)) at lines 3-7, 12-16\n")
+}
+
 func TestStripConflictMarkers_KeepLocal(t *testing.T) {
 	t.Parallel()
 
@@ -123,3 +143,12 @@ func TestConflictError_Message(t *testing.T) {
 
 	assert.Equal(t, "2 file(s) have merge conflicts", err.Error())
 }
+
+func TestStripConflictMarkers_Diff3(t *testing.T) {
+	t.Parallel()
+
+	content := "line1\n<<<<<<< local\nlocal-change\n||||||| base\nbase\n=======\nremote-change\n>>>>>>> remote\nline3\n"
+
+	assert.Equal(t, "line1\nlocal-change\nline3\n", forgesync.StripConflictMarkers(content, "local"))
+	assert.Equal(t, "line1\nremote-change\nline3\n", forgesync.StripConflictMarkers(content, "remote"))
+}
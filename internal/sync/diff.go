@@ -0,0 +1,143 @@
+package sync
+
+// hunk is a region where a version of a file differs from the base:
+// base[baseStart:baseEnd] became lines[start:end] of the version.
+type hunk struct {
+	baseStart, baseEnd int
+	start, end         int
+}
+
+// diffLines returns the hunks that turn the lines of base into those of
+// version, in order.
+func diffLines(base, version []string) []hunk {
+	d := &differ{a: base, b: version}
+	d.lcs(0, len(base), 0, len(version))
+
+	var hunks []hunk
+
+	// A final match past the end closes the last hunk.
+	i, j := 0, 0
+
+	for _, m := range append(d.matches, [2]int{len(base), len(version)}) {
+		if m[0] > i || m[1] > j {
+			hunks = append(hunks, hunk{baseStart: i, baseEnd: m[0], start: j, end: m[1]})
+		}
+
+		i, j = m[0]+1, m[1]+1
+	}
+
+	return hunks
+}
+
+// differ finds a longest common subsequence of the lines a and b with
+// Myers' linear-space algorithm ("An O(ND) Difference Algorithm and Its
+// Variations", 1986).
+type differ struct {
+	a, b []string
+
+	// matches holds the index pairs of the lines in the subsequence, in
+	// order.
+	matches [][2]int
+}
+
+// lcs appends the matches of a[alo:ahi] and b[blo:bhi] to d.matches.
+func (d *differ) lcs(alo, ahi, blo, bhi int) {
+	for alo < ahi && blo < bhi && d.a[alo] == d.b[blo] {
+		d.matches = append(d.matches, [2]int{alo, blo})
+		alo++
+		blo++
+	}
+
+	// Matches in the common suffix are appended after the middle.
+	suffix := 0
+	for alo < ahi-suffix && blo < bhi-suffix && d.a[ahi-1-suffix] == d.b[bhi-1-suffix] {
+		suffix++
+	}
+
+	ahi -= suffix
+	bhi -= suffix
+
+	// With the common ends trimmed, lines only on one side cannot match.
+	if alo < ahi && blo < bhi {
+		x, y, u, v := d.middleSnake(alo, ahi, blo, bhi)
+
+		d.lcs(alo, x, blo, y)
+
+		for ; x < u; x, y = x+1, y+1 {
+			d.matches = append(d.matches, [2]int{x, y})
+		}
+
+		d.lcs(u, ahi, v, bhi)
+	}
+
+	for i := range suffix {
+		d.matches = append(d.matches, [2]int{ahi + i, bhi + i})
+	}
+}
+
+// middleSnake returns the middle snake of an optimal edit path between
+// a[alo:ahi] and b[blo:bhi]: the diagonal run of matching lines from (x, y)
+// to (u, v) that the forward and reverse searches meet on. Both ranges must
+// be non-empty and differ in their first and last lines.
+func (d *differ) middleSnake(alo, ahi, blo, bhi int) (x, y, u, v int) {
+	n, m := ahi-alo, bhi-blo
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+
+	// forward[k] is the furthest x reached from the start on diagonal
+	// k = x-y, reverse[k] the furthest distance from the end on diagonal
+	// k = (n-x)-(m-y). Both are offset so that k may be negative.
+	off := maxD + 1
+	forward := make([]int, 2*off+1)
+	reverse := make([]int, 2*off+1)
+
+	for step := 0; step <= maxD; step++ {
+		for k := -step; k <= step; k += 2 {
+			sx, ex := follow(forward, off, k, step, func(x, y int) bool {
+				return x < n && y < m && d.a[alo+x] == d.b[blo+y]
+			})
+
+			// The forward path overlaps a reverse path of one step less.
+			if kr := delta - k; odd && kr >= -(step-1) && kr <= step-1 && ex+reverse[off+kr] >= n {
+				return alo + sx, blo + sx - k, alo + ex, blo + ex - k
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			sx, ex := follow(reverse, off, k, step, func(x, y int) bool {
+				return x < n && y < m && d.a[ahi-1-x] == d.b[bhi-1-y]
+			})
+
+			// The reverse path overlaps a forward path of as many steps.
+			if kf := delta - k; !odd && kf >= -step && kf <= step && ex+forward[off+kf] >= n {
+				return ahi - ex, bhi - ex + k, ahi - sx, bhi - sx + k
+			}
+		}
+	}
+
+	// Unreachable: the searches meet within maxD steps.
+	return alo, blo, alo, blo
+}
+
+// follow extends the furthest reaching path of the given step on diagonal
+// k of v by one edit and then along the diagonal while match holds. It
+// records the new furthest x in v and returns the x where the diagonal run
+// starts and ends.
+func follow(v []int, off, k, step int, match func(x, y int) bool) (start, end int) {
+	var x int
+	if k == -step || (k != step && v[off+k-1] < v[off+k+1]) {
+		x = v[off+k+1]
+	} else {
+		x = v[off+k-1] + 1
+	}
+
+	start = x
+	for match(x, x-k) {
+		x++
+	}
+
+	v[off+k] = x
+
+	return start, x
+}
@@ -2,11 +2,14 @@
 package sync
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
+	"github.com/donaldgifford/forge/internal/defaults"
 	"github.com/donaldgifford/forge/internal/lockfile"
 	tmpl "github.com/donaldgifford/forge/internal/template"
 )
@@ -26,6 +29,24 @@ type Opts struct {
 	Force bool
 	// FileFilter limits sync to a single file path.
 	FileFilter string
+	// Ref is the registry ref RegistryDir was checked out at, if any. It is
+	// recorded in the lockfile as the project's ref once every file is
+	// synced.
+	Ref string
+	// Constraint is the version range Ref was resolved from, if any. It is
+	// recorded along with Ref, replacing the project's range.
+	Constraint string
+	// Commit is the registry commit RegistryDir holds, if known. It is
+	// recorded in the lockfile for the files synced, as the base of the next
+	// three-way merge.
+	Commit string
+	// ConflictStyle is how merged files are written where local and
+	// upstream changes conflict (default ConflictStyleMerge).
+	ConflictStyle ConflictStyle
+	// RegistryDirs maps the registry URL of a blueprint added to the project
+	// (forge add) to the local registry content, for blueprints added from a
+	// registry other than RegistryDir. Such blueprints have no base content.
+	RegistryDirs map[string]string
 }
 
 // Result holds the outcome of a sync operation.
@@ -34,6 +55,10 @@ type Result struct {
 	Skipped       []string
 	Conflicts     []string
 	ConflictFiles []ConflictFile
+
+	// missing counts the files in Skipped because the registry no longer
+	// has their source.
+	missing int
 }
 
 // Run executes the sync workflow.
@@ -43,6 +68,11 @@ func Run(opts *Opts) (*Result, error) {
 		projectDir = "."
 	}
 
+	style := cmp.Or(opts.ConflictStyle, ConflictStyleMerge)
+	if !slices.Contains([]ConflictStyle{ConflictStyleMerge, ConflictStyleDiff3}, style) {
+		return nil, fmt.Errorf("invalid conflict style %q, must be one of: merge, diff3", style)
+	}
+
 	lockPath := filepath.Join(projectDir, lockfile.FileName)
 
 	lock, err := lockfile.Read(lockPath)
@@ -51,7 +81,69 @@ func Run(opts *Opts) (*Result, error) {
 	}
 
 	result := &Result{}
-	renderer := tmpl.NewRenderer()
+
+	// The project moves to Ref only when every file is synced to it.
+	moved := !opts.DryRun && opts.FileFilter == "" && opts.Ref != "" &&
+		(opts.Ref != lock.Blueprint.Ref || opts.Constraint != lock.Blueprint.Constraint)
+	if moved {
+		lock.Blueprint.Ref, lock.Blueprint.Constraint = opts.Ref, opts.Constraint
+	}
+
+	// BaseDir holds the registry at the project's commit, so it is only the
+	// base of blueprints last synced at that commit.
+	base := lock.Blueprint
+
+	// Files already matching a new commit are still recorded as synced at it.
+	advanced := !opts.DryRun && opts.Commit != "" && opts.Commit != base.Commit
+
+	for _, applied := range lock.Blueprints() {
+		blueprintOpts := opts
+		if applied.Blueprint.Commit != base.Commit || applied.Blueprint.RegistryURL != base.RegistryURL {
+			own := *opts
+			own.BaseDir = ""
+			blueprintOpts = &own
+		}
+
+		if err := syncBlueprint(blueprintOpts, applied, result); err != nil {
+			return nil, err
+		}
+	}
+
+	// Update lockfile if not dry-run.
+	if !opts.DryRun && (len(result.Updated) > 0 || moved || advanced) {
+		now := time.Now().UTC()
+
+		for _, applied := range lock.Blueprints() {
+			applied.LastSynced = now
+
+			// Recompute content hashes for synced files.
+			updateFileHashes(projectDir, applied)
+		}
+
+		if err := lockfile.Write(lockPath, lock); err != nil {
+			return nil, fmt.Errorf("updating lockfile: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// syncBlueprint syncs the files tracked for one blueprint applied to the
+// project.
+func syncBlueprint(opts *Opts, lock *lockfile.Lockfile, result *Result) error {
+	if dir, ok := opts.RegistryDirs[lock.Blueprint.RegistryURL]; ok {
+		own := *opts
+		own.RegistryDir, own.BaseDir, own.Commit = dir, "", ""
+		opts = &own
+	}
+
+	partials, err := defaults.ResolvePartials(opts.RegistryDir, lock.Sources()...)
+	if err != nil {
+		return fmt.Errorf("resolving partials: %w", err)
+	}
+
+	renderer := tmpl.NewRenderer().WithFuncOptions(tmpl.FuncOptions{Seed: lock.Seed}).WithPartials(partials)
+	vars := lock.TemplateVars()
 
 	// Sync defaults.
 	for i := range lock.Defaults {
@@ -61,9 +153,13 @@ func Run(opts *Opts) (*Result, error) {
 			continue
 		}
 
-		if err := syncDefault(opts, d, lock.Variables, renderer, result); err != nil {
-			return nil, fmt.Errorf("syncing default %s: %w", d.Path, err)
+		missing := result.missing
+
+		if err := syncDefault(opts, d, vars, renderer, result); err != nil {
+			return fmt.Errorf("syncing default %s: %w", d.Path, err)
 		}
+
+		markSynced(opts, &d.SyncedCommit, missing, result)
 	}
 
 	// Sync managed files.
@@ -74,24 +170,55 @@ func Run(opts *Opts) (*Result, error) {
 			continue
 		}
 
+		missing := result.missing
+
 		if err := syncManagedFile(opts, mf, lock, renderer, result); err != nil {
-			return nil, fmt.Errorf("syncing managed file %s: %w", mf.Path, err)
+			return fmt.Errorf("syncing managed file %s: %w", mf.Path, err)
 		}
+
+		markSynced(opts, &mf.SyncedCommit, missing, result)
 	}
 
-	// Update lockfile if not dry-run.
-	if !opts.DryRun && len(result.Updated) > 0 {
-		lock.LastSynced = time.Now().UTC()
+	// Sync generated files.
+	for i := range lock.Generated {
+		g := &lock.Generated[i]
 
-		// Recompute content hashes for synced files.
-		updateFileHashes(projectDir, lock)
+		if opts.FileFilter != "" && g.Path != opts.FileFilter {
+			continue
+		}
 
-		if err := lockfile.Write(lockPath, lock); err != nil {
-			return nil, fmt.Errorf("updating lockfile: %w", err)
+		missing := result.missing
+
+		if err := syncGenerated(opts, g, lock, renderer, result); err != nil {
+			return fmt.Errorf("syncing generated file %s: %w", g.Path, err)
 		}
+
+		markSynced(opts, &g.SyncedCommit, missing, result)
 	}
 
-	return result, nil
+	// Every file is now at the registry commit.
+	if opts.Commit != "" && !opts.DryRun && opts.FileFilter == "" {
+		lock.Blueprint.Commit = opts.Commit
+	}
+
+	return nil
+}
+
+// markSynced records opts.Commit as the commit a file was last synced at,
+// unless this is a dry run or the registry no longer has the file's source
+// (result.missing grew past missing). A file skipped because it already
+// matches the registry is synced too.
+func markSynced(opts *Opts, syncedCommit *string, missing int, result *Result) {
+	if opts.Commit != "" && !opts.DryRun && result.missing == missing {
+		*syncedCommit = opts.Commit
+	}
+}
+
+// skipMissing records relPath as skipped because the registry no longer has
+// its source.
+func skipMissing(result *Result, relPath string) {
+	result.Skipped = append(result.Skipped, relPath)
+	result.missing++
 }
 
 func syncDefault(
@@ -101,21 +228,21 @@ func syncDefault(
 	renderer *tmpl.Renderer,
 	result *Result,
 ) error {
-	sourcePath := findSourceFile(opts.RegistryDir, d.Path)
+	sourcePath := defaults.FindSource(opts.RegistryDir, d.Path)
 	if sourcePath == "" {
-		result.Skipped = append(result.Skipped, d.Path)
+		skipMissing(result, d.Path)
 
 		return nil
 	}
 
-	sourceContent, err := readSourceContent(sourcePath, vars, renderer)
+	sourceContent, err := renderer.WithDelimiterPair(d.Delimiters).WithSeedKey(d.Path).ReadSource(sourcePath, d.Verbatim, vars)
 	if err != nil {
 		return err
 	}
 
 	localPath := filepath.Join(opts.ProjectDir, d.Path)
 
-	return applyOverwrite(localPath, sourceContent, opts.DryRun, result)
+	return applyOverwrite(localPath, sourceContent, defaults.SourceMode(sourcePath), opts.DryRun, result)
 }
 
 func syncManagedFile(
@@ -125,39 +252,91 @@ func syncManagedFile(
 	renderer *tmpl.Renderer,
 	result *Result,
 ) error {
-	sourcePath := findSourceFile(opts.RegistryDir, mf.Path)
+	bpPath := cmp.Or(mf.Blueprint, lock.Blueprint.Path)
+
+	sourcePath := defaults.FindSource(opts.RegistryDir, mf.Path)
 	if sourcePath == "" {
-		// Check in blueprint directory.
-		sourcePath = findBlueprintFile(opts.RegistryDir, lock.Blueprint.Path, mf.Path)
+		// Check in the directory of the blueprint providing the file.
+		sourcePath = defaults.FindBlueprintFile(opts.RegistryDir, bpPath, mf.Path)
 	}
 
 	if sourcePath == "" {
-		result.Skipped = append(result.Skipped, mf.Path)
+		skipMissing(result, mf.Path)
 
 		return nil
 	}
 
-	sourceContent, err := readSourceContent(sourcePath, lock.Variables, renderer)
+	sourceContent, err := renderer.WithDelimiterPair(mf.Delimiters).WithSeedKey(mf.Path).ReadSource(sourcePath, mf.Verbatim, lock.TemplateVars())
 	if err != nil {
 		return err
 	}
 
 	localPath := filepath.Join(opts.ProjectDir, mf.Path)
+	mode := defaults.SourceMode(sourcePath)
 
 	if mf.Strategy == "merge" {
-		return applyMerge(opts, mf, lock, renderer, localPath, sourceContent, result)
+		base := func() ([]byte, error) {
+			return resolveBaseContent(opts, lock, bpPath, mf.Path, mf.Path, mf.Verbatim, mf.Delimiters, lock.TemplateVars())
+		}
+
+		return applyMerge(opts, mf.Path, localPath, sourceContent, base, mode, result)
 	}
 
-	return applyOverwrite(localPath, sourceContent, opts.DryRun, result)
+	return applyOverwrite(localPath, sourceContent, mode, opts.DryRun, result)
 }
 
-func applyMerge(
+// syncGenerated re-renders a file produced by a generate rule with the item
+// it was generated for.
+func syncGenerated(
 	opts *Opts,
-	mf *lockfile.ManagedFileEntry,
+	g *lockfile.GeneratedEntry,
 	lock *lockfile.Lockfile,
 	renderer *tmpl.Renderer,
-	localPath string,
+	result *Result,
+) error {
+	bpPath := cmp.Or(g.Blueprint, lock.Blueprint.Path)
+
+	sourcePath := defaults.FindBlueprintFile(opts.RegistryDir, bpPath, g.Template)
+	if sourcePath == "" {
+		sourcePath = defaults.FindSource(opts.RegistryDir, g.Template)
+	}
+
+	if sourcePath == "" {
+		skipMissing(result, g.Path)
+
+		return nil
+	}
+
+	vars := g.Vars(lock)
+
+	sourceContent, err := renderer.WithDelimiterPair(g.Delimiters).WithSeedKey(g.Path).RenderFile(sourcePath, vars)
+	if err != nil {
+		return fmt.Errorf("rendering template %s: %w", sourcePath, err)
+	}
+
+	localPath := filepath.Join(opts.ProjectDir, g.Path)
+	mode := defaults.SourceMode(sourcePath)
+
+	if g.Strategy == "merge" {
+		base := func() ([]byte, error) {
+			return resolveBaseContent(opts, lock, bpPath, g.Template, g.Path, false, g.Delimiters, vars)
+		}
+
+		return applyMerge(opts, g.Path, localPath, sourceContent, base, mode, result)
+	}
+
+	return applyOverwrite(localPath, sourceContent, mode, opts.DryRun, result)
+}
+
+// applyMerge three-way merges remoteContent into the local file at
+// localPath, using the content base returns as the common ancestor. relPath
+// is the file's project path for reporting conflicts.
+func applyMerge(
+	opts *Opts,
+	relPath, localPath string,
 	remoteContent []byte,
+	base func() ([]byte, error),
+	mode os.FileMode,
 	result *Result,
 ) error {
 	// Read local file.
@@ -165,55 +344,67 @@ func applyMerge(
 	if err != nil {
 		if os.IsNotExist(err) {
 			// No local file — accept remote content directly.
-			return applyOverwrite(localPath, remoteContent, opts.DryRun, result)
+			return applyOverwrite(localPath, remoteContent, mode, opts.DryRun, result)
 		}
 
 		return fmt.Errorf("reading local file %s: %w", localPath, err)
 	}
 
 	// Resolve base content from the base registry directory.
-	baseContent, err := resolveBaseContent(opts, mf, lock, renderer)
+	baseContent, err := base()
 	if err != nil {
 		// If base is unavailable, fall back to overwrite.
-		return applyOverwrite(localPath, remoteContent, opts.DryRun, result)
+		return applyOverwrite(localPath, remoteContent, mode, opts.DryRun, result)
 	}
 
-	merged := ThreeWayMerge(baseContent, localContent, remoteContent)
+	merged := ThreeWayMergeStyle(baseContent, localContent, remoteContent, cmp.Or(opts.ConflictStyle, ConflictStyleMerge))
 
 	if merged.HasConflicts {
-		result.Conflicts = append(result.Conflicts, mf.Path)
+		result.Conflicts = append(result.Conflicts, relPath)
 		result.ConflictFiles = append(result.ConflictFiles, ConflictFile{
-			Path:      mf.Path,
+			Path:      relPath,
 			Conflicts: merged.Conflicts,
 		})
 	}
 
-	return applyOverwrite(localPath, merged.Content, opts.DryRun, result)
+	return applyOverwrite(localPath, merged.Content, mode, opts.DryRun, result)
 }
 
+// resolveBaseContent renders the source file relPath of the blueprint at
+// bpPath from the base registry content with vars, using the partials of
+// the base registry. seedKey is the key of the file's seeded UUIDs.
 func resolveBaseContent(
 	opts *Opts,
-	mf *lockfile.ManagedFileEntry,
 	lock *lockfile.Lockfile,
-	renderer *tmpl.Renderer,
+	bpPath, relPath, seedKey string,
+	verbatim bool,
+	delims []string,
+	vars map[string]any,
 ) ([]byte, error) {
 	if opts.BaseDir == "" {
 		return nil, fmt.Errorf("no base directory configured")
 	}
 
-	basePath := findSourceFile(opts.BaseDir, mf.Path)
+	basePath := defaults.FindSource(opts.BaseDir, relPath)
 	if basePath == "" {
-		basePath = findBlueprintFile(opts.BaseDir, lock.Blueprint.Path, mf.Path)
+		basePath = defaults.FindBlueprintFile(opts.BaseDir, bpPath, relPath)
 	}
 
 	if basePath == "" {
-		return nil, fmt.Errorf("base file not found for %s", mf.Path)
+		return nil, fmt.Errorf("base file not found for %s", relPath)
 	}
 
-	return readSourceContent(basePath, lock.Variables, renderer)
+	partials, err := defaults.ResolvePartials(opts.BaseDir, lock.Sources()...)
+	if err != nil {
+		return nil, fmt.Errorf("resolving base partials: %w", err)
+	}
+
+	renderer := tmpl.NewRenderer().WithFuncOptions(tmpl.FuncOptions{Seed: lock.Seed}).WithPartials(partials)
+
+	return renderer.WithDelimiterPair(delims).WithSeedKey(seedKey).ReadSource(basePath, verbatim, vars)
 }
 
-// updateFileHashes recomputes SHA256 hashes for all tracked files in the lockfile.
+// updateFileHashes recomputes SHA256 hashes and modes for all tracked files in the lockfile.
 func updateFileHashes(projectDir string, lock *lockfile.Lockfile) {
 	for i := range lock.Defaults {
 		d := &lock.Defaults[i]
@@ -222,6 +413,7 @@ func updateFileHashes(projectDir string, lock *lockfile.Lockfile) {
 
 		if err == nil {
 			d.Hash = lockfile.ContentHash(content)
+			d.Mode = lockfile.FileMode(filepath.Join(projectDir, renderedPath))
 		}
 	}
 
@@ -231,51 +423,17 @@ func updateFileHashes(projectDir string, lock *lockfile.Lockfile) {
 
 		if err == nil {
 			mf.Hash = lockfile.ContentHash(content)
+			mf.Mode = lockfile.FileMode(filepath.Join(projectDir, mf.Path))
 		}
 	}
-}
-
-// findBlueprintFile looks for a file in the blueprint's own directory.
-func findBlueprintFile(registryDir, blueprintPath, relPath string) string {
-	candidate := filepath.Join(registryDir, blueprintPath, relPath)
-	if _, err := os.Stat(candidate); err == nil {
-		return candidate
-	}
 
-	return ""
-}
+	for i := range lock.Generated {
+		g := &lock.Generated[i]
+		content, err := os.ReadFile(filepath.Clean(filepath.Join(projectDir, g.Path)))
 
-// findSourceFile looks for a file in known registry locations.
-func findSourceFile(registryDir, relPath string) string {
-	// Check direct path first.
-	candidate := filepath.Join(registryDir, relPath)
-	if _, err := os.Stat(candidate); err == nil {
-		return candidate
-	}
-
-	// Check _defaults/.
-	candidate = filepath.Join(registryDir, "_defaults", relPath)
-	if _, err := os.Stat(candidate); err == nil {
-		return candidate
-	}
-
-	return ""
-}
-
-func readSourceContent(sourcePath string, vars map[string]any, renderer *tmpl.Renderer) ([]byte, error) {
-	if tmpl.IsTemplate(sourcePath) {
-		content, err := renderer.RenderFile(sourcePath, vars)
-		if err != nil {
-			return nil, fmt.Errorf("rendering template %s: %w", sourcePath, err)
+		if err == nil {
+			g.Hash = lockfile.ContentHash(content)
+			g.Mode = lockfile.FileMode(filepath.Join(projectDir, g.Path))
 		}
-
-		return content, nil
-	}
-
-	content, err := os.ReadFile(filepath.Clean(sourcePath))
-	if err != nil {
-		return nil, fmt.Errorf("reading source %s: %w", sourcePath, err)
 	}
-
-	return content, nil
 }
@@ -277,6 +277,55 @@ func TestSync_MergeStrategy_Conflict(t *testing.T) {
 	assert.Contains(t, string(content), ">>>>>>> remote")
 }
 
+func TestSync_MergeStrategy_ConflictStyle(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	registryDir := t.TempDir()
+	baseDir := t.TempDir()
+
+	for dir, content := range map[string]string{
+		filepath.Join(baseDir, "test", "bp"):     "line1\nline2\nline3\n",
+		filepath.Join(registryDir, "test", "bp"): "line1\nline2-remote\nline3\n",
+		projectDir:                               "line1\nline2-local\nline3\n",
+	} {
+		require.NoError(t, os.MkdirAll(dir, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Makefile"), []byte(content), 0o644))
+	}
+
+	lock := &lockfile.Lockfile{
+		Blueprint: lockfile.BlueprintRef{
+			Name: "test-bp",
+			Path: "test/bp",
+		},
+		ManagedFiles: []lockfile.ManagedFileEntry{
+			{Path: "Makefile", Strategy: "merge"},
+		},
+		Variables: map[string]any{},
+	}
+
+	require.NoError(t, lockfile.Write(filepath.Join(projectDir, lockfile.FileName), lock))
+
+	_, err := forgesync.Run(&forgesync.Opts{
+		ProjectDir: projectDir, RegistryDir: registryDir, BaseDir: baseDir, ConflictStyle: "zdiff3",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid conflict style")
+
+	result, err := forgesync.Run(&forgesync.Opts{
+		ProjectDir: projectDir, RegistryDir: registryDir, BaseDir: baseDir, ConflictStyle: forgesync.ConflictStyleDiff3,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.ConflictFiles, 1)
+	assert.Equal(t, []string{"line2"}, result.ConflictFiles[0].Conflicts[0].BaseLines)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, "Makefile"))
+	require.NoError(t, err)
+	assert.Equal(t,
+		"line1\n<<<<<<< local\nline2-local\n||||||| base\nline2\n=======\nline2-remote\n>>>>>>> remote\nline3\n",
+		string(content))
+}
+
 func TestSync_MergeStrategy_NoBaseDir_FallsBackToOverwrite(t *testing.T) {
 	t.Parallel()
 
@@ -359,3 +408,269 @@ func TestSync_MissingSource(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, result.Skipped, "nonexistent.file")
 }
+
+func TestSync_OverwriteAppliesSourceMode(t *testing.T) {
+	t.Parallel()
+
+	projectDir, registryDir := setupSyncTest(t)
+
+	// Same content, but the registry file is now executable.
+	require.NoError(t, os.Chmod(filepath.Join(registryDir, "_defaults", ".editorconfig"), 0o755))
+
+	result, err := forgesync.Run(&forgesync.Opts{
+		ProjectDir:  projectDir,
+		RegistryDir: registryDir,
+	})
+	require.NoError(t, err)
+	assert.Len(t, result.Updated, 1)
+
+	info, err := os.Stat(filepath.Join(projectDir, ".editorconfig"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	lock, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "0755", lock.Defaults[0].Mode)
+}
+
+func TestSync_GeneratedFilesRenderedPerItem(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	registryDir := t.TempDir()
+
+	bpDir := filepath.Join(registryDir, "test", "bp", "_cmd")
+	require.NoError(t, os.MkdirAll(bpDir, 0o750))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(bpDir, "main.go.tmpl"),
+		[]byte("// {{ .name }}: {{ .command }} v2\npackage main\n"),
+		0o644,
+	))
+
+	lock := &lockfile.Lockfile{
+		Blueprint: lockfile.BlueprintRef{Name: "test-bp", Path: "test/bp"},
+		Variables: map[string]any{"name": "demo"},
+		Generated: []lockfile.GeneratedEntry{
+			{Path: "cmd/serve/main.go", Template: "_cmd/main.go.tmpl", As: "command", Item: "serve", Strategy: "overwrite"},
+			{Path: "cmd/migrate/main.go", Template: "_cmd/main.go.tmpl", As: "command", Item: "migrate", Strategy: "overwrite"},
+		},
+	}
+	require.NoError(t, lockfile.Write(filepath.Join(projectDir, lockfile.FileName), lock))
+
+	for _, cmd := range []string{"serve", "migrate"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "cmd", cmd), 0o750))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(projectDir, "cmd", cmd, "main.go"),
+			[]byte("// demo: "+cmd+"\npackage main\n"),
+			0o644,
+		))
+	}
+
+	result, err := forgesync.Run(&forgesync.Opts{ProjectDir: projectDir, RegistryDir: registryDir})
+	require.NoError(t, err)
+	assert.Len(t, result.Updated, 2)
+
+	for _, cmd := range []string{"serve", "migrate"} {
+		content, err := os.ReadFile(filepath.Join(projectDir, "cmd", cmd, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "// demo: "+cmd+" v2\npackage main\n", string(content))
+	}
+
+	updated, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.NotEmpty(t, updated.Generated[0].Hash)
+}
+
+func TestSync_ComposedBlueprintSources(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	registryDir := t.TempDir()
+
+	writeRegistryFile := func(rel, content string) {
+		path := filepath.Join(registryDir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	writeRegistryFile("ci/github/ci.yml", "on: [push, pull_request]\n")
+	writeRegistryFile("ci/github/_partials/job.tmpl", "runs-on: {{ .os }}")
+	writeRegistryFile("go/base/_cmd/main.go.tmpl", "{{ template \"job\" . }}\n// {{ .command }}\n")
+
+	lock := &lockfile.Lockfile{
+		Blueprint: lockfile.BlueprintRef{Name: "api", Path: "go/api", Composed: []string{"go/base", "ci/github"}},
+		Variables: map[string]any{"os": "linux"},
+		ManagedFiles: []lockfile.ManagedFileEntry{
+			{Path: "ci.yml", Strategy: "overwrite", Blueprint: "ci/github"},
+		},
+		Generated: []lockfile.GeneratedEntry{
+			{Path: "cmd/serve/main.go", Template: "_cmd/main.go.tmpl", Blueprint: "go/base", As: "command", Item: "serve", Strategy: "overwrite"},
+		},
+	}
+	require.NoError(t, lockfile.Write(filepath.Join(projectDir, lockfile.FileName), lock))
+
+	result, err := forgesync.Run(&forgesync.Opts{ProjectDir: projectDir, RegistryDir: registryDir})
+	require.NoError(t, err)
+	assert.Len(t, result.Updated, 2)
+	assert.Empty(t, result.Skipped)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, "ci.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "on: [push, pull_request]\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(projectDir, "cmd", "serve", "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "runs-on: linux\n// serve\n", string(content))
+}
+
+func TestSync_AppliedBlueprints(t *testing.T) {
+	t.Parallel()
+
+	projectDir, registryDir := setupSyncTest(t)
+	otherRegistryDir := t.TempDir()
+
+	ciDir := filepath.Join(otherRegistryDir, "ci", "github")
+	require.NoError(t, os.MkdirAll(ciDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(ciDir, "ci.yml"), []byte("name: demo v2\n"), 0o644))
+
+	lock, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+
+	lock.Applied = []lockfile.Lockfile{{
+		Blueprint:    lockfile.BlueprintRef{Name: "github", Path: "ci/github", RegistryURL: "https://example.com/other"},
+		Variables:    map[string]any{"project_name": "demo"},
+		ManagedFiles: []lockfile.ManagedFileEntry{{Path: "ci.yml", Strategy: "overwrite"}},
+	}}
+	require.NoError(t, lockfile.Write(filepath.Join(projectDir, lockfile.FileName), lock))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "ci.yml"), []byte("name: demo\n"), 0o644))
+
+	result, err := forgesync.Run(&forgesync.Opts{
+		ProjectDir:   projectDir,
+		RegistryDir:  registryDir,
+		RegistryDirs: map[string]string{"https://example.com/other": otherRegistryDir},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(projectDir, "ci.yml")}, result.Updated)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, "ci.yml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: demo v2\n", string(content))
+
+	updated, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	require.Len(t, updated.Applied, 1)
+	assert.Equal(t, lockfile.ContentHash([]byte("name: demo v2\n")), updated.Applied[0].ManagedFiles[0].Hash)
+}
+
+func TestSync_RecordsCommit(t *testing.T) {
+	t.Parallel()
+
+	projectDir, registryDir := setupSyncTest(t)
+
+	require.NoError(t, os.WriteFile(
+		filepath.Join(registryDir, "_defaults", ".editorconfig"),
+		[]byte("root = true\nindent_style = tab\n"),
+		0o644,
+	))
+
+	_, err := forgesync.Run(&forgesync.Opts{
+		ProjectDir:  projectDir,
+		RegistryDir: registryDir,
+		Commit:      "def456",
+	})
+	require.NoError(t, err)
+
+	lock, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+
+	assert.Equal(t, "def456", lock.Blueprint.Commit)
+	assert.Equal(t, "def456", lock.Defaults[0].SyncedCommit)
+}
+
+func TestSync_RecordsCommitForUnchangedFiles(t *testing.T) {
+	t.Parallel()
+
+	projectDir, _ := setupSyncTest(t)
+	registryDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "_defaults"), 0o750))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(registryDir, "_defaults", ".editorconfig"),
+		[]byte("root = true\nindent_style = space\n"),
+		0o644,
+	))
+
+	lock := &lockfile.Lockfile{
+		Blueprint: lockfile.BlueprintRef{Name: "test-bp", Path: "test/bp", Commit: "abc123"},
+		Defaults: []lockfile.DefaultEntry{
+			{Path: ".editorconfig", Source: "registry-default", Strategy: "overwrite", SyncedCommit: "abc123"},
+			{Path: ".gitignore", Source: "registry-default", Strategy: "overwrite", SyncedCommit: "abc123"},
+		},
+		Variables: map[string]any{},
+	}
+	require.NoError(t, lockfile.Write(filepath.Join(projectDir, lockfile.FileName), lock))
+
+	result, err := forgesync.Run(&forgesync.Opts{
+		ProjectDir:  projectDir,
+		RegistryDir: registryDir,
+		Commit:      "def456",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Updated)
+
+	updated, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "def456", updated.Defaults[0].SyncedCommit, "content already matching the registry is synced")
+	assert.Equal(t, "abc123", updated.Defaults[1].SyncedCommit, "a file the registry no longer has is not")
+}
+
+func TestSync_RecordsRef(t *testing.T) {
+	t.Parallel()
+
+	projectDir, registryDir := setupSyncTest(t)
+
+	// Nothing changed, but the project still moves to the new release.
+	result, err := forgesync.Run(&forgesync.Opts{
+		ProjectDir:  projectDir,
+		RegistryDir: registryDir,
+		Ref:         "v1.1.0",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Updated)
+
+	lock, err := lockfile.Read(filepath.Join(projectDir, lockfile.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", lock.Blueprint.Ref)
+}
+
+func TestSync_RefReplacesConstraint(t *testing.T) {
+	t.Parallel()
+
+	projectDir, registryDir := setupSyncTest(t)
+	lockPath := filepath.Join(projectDir, lockfile.FileName)
+
+	lock, err := lockfile.Read(lockPath)
+	require.NoError(t, err)
+
+	lock.Blueprint.Ref, lock.Blueprint.Constraint = "v1.2.0", "^1.0"
+	require.NoError(t, lockfile.Write(lockPath, lock))
+
+	// Pinning to a literal ref drops the version range.
+	_, err = forgesync.Run(&forgesync.Opts{ProjectDir: projectDir, RegistryDir: registryDir, Ref: "v3.0.0"})
+	require.NoError(t, err)
+
+	lock, err = lockfile.Read(lockPath)
+	require.NoError(t, err)
+	assert.Equal(t, "v3.0.0", lock.Blueprint.Ref)
+	assert.Empty(t, lock.Blueprint.Constraint)
+
+	// A dry run leaves the project where it is.
+	_, err = forgesync.Run(&forgesync.Opts{
+		ProjectDir: projectDir, RegistryDir: registryDir, Ref: "v2.1.0", Constraint: "^2.0", DryRun: true,
+	})
+	require.NoError(t, err)
+
+	lock, err = lockfile.Read(lockPath)
+	require.NoError(t, err)
+	assert.Equal(t, "v3.0.0", lock.Blueprint.Ref)
+}
@@ -1,13 +1,46 @@
 package sync
 
 import (
+	"bytes"
+	"slices"
 	"strings"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+// ConflictStyle is how a merge writes the regions both sides changed.
+type ConflictStyle string
+
+// Conflict styles.
+const (
+	// ConflictStyleMerge writes the local and remote lines of a region
+	// between "<<<<<<< local", "=======" and ">>>>>>> remote" markers.
+	ConflictStyleMerge ConflictStyle = "merge"
+
+	// ConflictStyleDiff3 also writes the base's lines, after a
+	// "||||||| base" marker following the local ones.
+	ConflictStyleDiff3 ConflictStyle = "diff3"
 )
 
 // Conflict describes a merge conflict region.
 type Conflict struct {
+	BaseLines   []string
 	LocalLines  []string
 	RemoteLines []string
+
+	// Base, Local and Remote locate the region in each input, Merged the
+	// region, markers included, in the merged content.
+	Base   LineRange
+	Local  LineRange
+	Remote LineRange
+	Merged LineRange
+}
+
+// LineRange is a range of lines of a file. Lines are numbered from 1; an
+// empty range starts at the line it precedes.
+type LineRange struct {
+	Start int
+	Count int
 }
 
 // MergeResult holds the output of a three-way merge.
@@ -17,85 +50,249 @@ type MergeResult struct {
 	HasConflicts bool
 }
 
-// ThreeWayMerge performs a line-based three-way merge.
+// ThreeWayMerge performs a line-based three-way merge, writing conflicts
+// in ConflictStyleMerge.
 //
 // Inputs:
 //   - base: the common ancestor (last synced version)
 //   - local: the current local file
 //   - remote: the latest version from the registry
 //
-// When both sides change the same lines, conflict markers are inserted.
+// See ThreeWayMergeStyle.
 func ThreeWayMerge(base, local, remote []byte) *MergeResult {
-	baseLines := splitLines(string(base))
+	return ThreeWayMergeStyle(base, local, remote, ConflictStyleMerge)
+}
+
+// ThreeWayMergeStyle performs a line-based three-way merge in the manner of
+// diff3: local and remote are each diffed against base, changes in
+// separate regions of base are applied together, and where both sides
+// changed the same or adjacent lines differently, the region is written
+// with conflict markers in the given style. A missing final newline is a
+// change like any other, so the side that added or removed it wins.
+// Binary content is never merged line by line: see mergeBinary.
+func ThreeWayMergeStyle(base, local, remote []byte, style ConflictStyle) *MergeResult {
+	if tmpl.IsBinary(base) || tmpl.IsBinary(local) || tmpl.IsBinary(remote) {
+		return mergeBinary(base, local, remote)
+	}
+
+	switch {
+	case bytes.Equal(base, remote), bytes.Equal(local, remote):
+		// No upstream changes, or both made the same ones — keep local as-is.
+		return &MergeResult{Content: local}
+	case bytes.Equal(base, local):
+		// No local changes — accept remote.
+		return &MergeResult{Content: remote}
+	}
+
+	return mergeLines(splitLines(string(base)), splitLines(string(local)), splitLines(string(remote)), style)
+}
+
+// TwoWayMerge merges two versions of a file that have no common ancestor,
+// taking the lines they have in common as one: lines only one of them has
+// are kept, and where they have different lines in the same place, the
+// region is written with conflict markers. Binary content is merged as a
+// whole: see mergeBinary.
+func TwoWayMerge(local, remote []byte) *MergeResult {
+	if tmpl.IsBinary(local) || tmpl.IsBinary(remote) || bytes.Equal(local, remote) {
+		return mergeBinary(nil, local, remote)
+	}
+
 	localLines := splitLines(string(local))
 	remoteLines := splitLines(string(remote))
 
-	// If base == remote, no upstream changes — keep local as-is.
-	if linesEqual(baseLines, remoteLines) {
-		return &MergeResult{Content: local}
+	d := &differ{a: localLines, b: remoteLines}
+	d.lcs(0, len(localLines), 0, len(remoteLines))
+
+	base := make([]string, len(d.matches))
+	for i, m := range d.matches {
+		base[i] = localLines[m[0]]
 	}
 
-	// If base == local, no local changes — accept remote.
-	if linesEqual(baseLines, localLines) {
+	return mergeLines(base, localLines, remoteLines, ConflictStyleMerge)
+}
+
+// mergeBinary merges binary content as a whole. If only one side changed,
+// that side wins. If both changed differently, the local file is kept
+// untouched and a conflict without markers is reported.
+func mergeBinary(base, local, remote []byte) *MergeResult {
+	switch {
+	case bytes.Equal(base, remote), bytes.Equal(local, remote):
+		return &MergeResult{Content: local}
+	case bytes.Equal(base, local):
 		return &MergeResult{Content: remote}
+	default:
+		// A single conflict region covering the whole file.
+		return &MergeResult{Content: local, Conflicts: []Conflict{{}}, HasConflicts: true}
 	}
+}
+
+// noNewline ends the lines of content without a final newline. As lines
+// never contain a newline, it cannot be mistaken for one.
+const noNewline = "\n"
+
+// mergeLines merges the lines of local and remote, as split by splitLines,
+// with base as their common ancestor.
+func mergeLines(base, local, remote []string, style ConflictStyle) *MergeResult {
+	m := &merger{base: base, local: local, remote: remote, style: style}
+
+	pos := 0
+
+	regions := changedRegions(diffLines(base, local), diffLines(base, remote))
 
-	// Both sides have changes — perform line-by-line merge.
-	return mergeLines(baseLines, localLines, remoteLines)
+	for i := range regions {
+		r := &regions[i]
+
+		// Lines between regions are unchanged on both sides.
+		m.out = append(m.out, base[pos:r.baseStart]...)
+		m.resolve(r)
+		pos = r.baseEnd
+	}
+
+	m.out = append(m.out, base[pos:]...)
+
+	return &MergeResult{
+		Content:      []byte(joinLines(m.out)),
+		Conflicts:    m.conflicts,
+		HasConflicts: len(m.conflicts) > 0,
+	}
 }
 
-func mergeLines(base, local, remote []string) *MergeResult {
-	var result []string
-	var conflicts []Conflict
+// region is a region of base that local, remote or both changed.
+type region struct {
+	baseStart, baseEnd int
+
+	// local and remote hold the hunks of each side in the region, none if
+	// the side did not change it.
+	local, remote []hunk
+}
 
-	maxLen := max(len(base), len(local), len(remote))
+// changedRegions groups the hunks of local and remote into the regions of
+// base they change. Hunks of the two sides that overlap or touch fall into
+// one region.
+func changedRegions(localHunks, remoteHunks []hunk) []region {
+	var regions []region
 
-	for i := range maxLen {
-		baseLine := getLine(base, i)
-		localLine := getLine(local, i)
-		remoteLine := getLine(remote, i)
+	for len(localHunks) > 0 || len(remoteHunks) > 0 {
+		// A region starts at the first hunk of either side...
+		var r region
 
 		switch {
-		case localLine == remoteLine && baseLine == localLine:
-			// No changes on this line.
-			result = append(result, baseLine)
-		case baseLine == localLine:
-			// Only remote changed.
-			result = append(result, remoteLine)
-		case baseLine == remoteLine:
-			// Only local changed.
-			result = append(result, localLine)
-		case localLine == remoteLine:
-			// Both changed to the same thing.
-			result = append(result, localLine)
+		case len(remoteHunks) == 0:
+			r.baseStart = localHunks[0].baseStart
+		case len(localHunks) == 0:
+			r.baseStart = remoteHunks[0].baseStart
 		default:
-			// Conflict: both sides changed differently.
-			result = append(result,
-				"<<<<<<< local",
-				localLine,
-				"=======",
-				remoteLine,
-				">>>>>>> remote",
-			)
-			conflicts = append(conflicts, Conflict{
-				LocalLines:  []string{localLine},
-				RemoteLines: []string{remoteLine},
-			})
+			r.baseStart = min(localHunks[0].baseStart, remoteHunks[0].baseStart)
 		}
+
+		r.baseEnd = r.baseStart
+
+		// ...and takes in every hunk that starts before it ends.
+		nLocal, nRemote := 0, 0
+
+		for grew := true; grew; {
+			grew = false
+
+			if nLocal < len(localHunks) && localHunks[nLocal].baseStart <= r.baseEnd {
+				r.baseEnd = max(r.baseEnd, localHunks[nLocal].baseEnd)
+				nLocal++
+				grew = true
+			}
+
+			if nRemote < len(remoteHunks) && remoteHunks[nRemote].baseStart <= r.baseEnd {
+				r.baseEnd = max(r.baseEnd, remoteHunks[nRemote].baseEnd)
+				nRemote++
+				grew = true
+			}
+		}
+
+		r.local, localHunks = localHunks[:nLocal], localHunks[nLocal:]
+		r.remote, remoteHunks = remoteHunks[:nRemote], remoteHunks[nRemote:]
+		regions = append(regions, r)
 	}
 
-	merged := strings.Join(result, "\n")
-	if merged != "" && !strings.HasSuffix(merged, "\n") {
-		merged += "\n"
+	return regions
+}
+
+// sideRange returns the range of lines the region became on the side with
+// the given hunks in it. The side's lines outside its hunks are those of
+// base, so the range is that of the hunks, widened by the base lines the
+// region covers beyond them.
+func (r *region) sideRange(hunks []hunk) (start, end int) {
+	first, last := hunks[0], hunks[len(hunks)-1]
+
+	return first.start - (first.baseStart - r.baseStart), last.end + (r.baseEnd - last.baseEnd)
+}
+
+// merger accumulates the merged lines of mergeLines.
+type merger struct {
+	base, local, remote []string
+	style               ConflictStyle
+
+	out       []string
+	conflicts []Conflict
+}
+
+// resolve appends the merged lines of r: the lines of the side that changed
+// it, the lines both sides changed it to alike, or a conflict.
+func (m *merger) resolve(r *region) {
+	if len(r.remote) == 0 {
+		start, end := r.sideRange(r.local)
+		m.out = append(m.out, m.local[start:end]...)
+
+		return
 	}
 
-	return &MergeResult{
-		Content:      []byte(merged),
-		Conflicts:    conflicts,
-		HasConflicts: len(conflicts) > 0,
+	remoteStart, remoteEnd := r.sideRange(r.remote)
+	if len(r.local) == 0 {
+		m.out = append(m.out, m.remote[remoteStart:remoteEnd]...)
+
+		return
+	}
+
+	localStart, localEnd := r.sideRange(r.local)
+
+	baseLines := m.base[r.baseStart:r.baseEnd]
+	localLines := m.local[localStart:localEnd]
+	remoteLines := m.remote[remoteStart:remoteEnd]
+
+	if slices.Equal(localLines, remoteLines) {
+		m.out = append(m.out, localLines...)
+
+		return
+	}
+
+	conflict := Conflict{
+		BaseLines:   withoutNoNewline(baseLines),
+		LocalLines:  withoutNoNewline(localLines),
+		RemoteLines: withoutNoNewline(remoteLines),
+		Base:        LineRange{Start: r.baseStart + 1, Count: len(withoutNoNewline(baseLines))},
+		Local:       LineRange{Start: localStart + 1, Count: len(withoutNoNewline(localLines))},
+		Remote:      LineRange{Start: remoteStart + 1, Count: len(withoutNoNewline(remoteLines))},
 	}
+
+	start := len(m.out)
+
+	// Every line of a conflict region ends with a newline, so that the
+	// markers start lines of their own.
+	m.out = append(m.out, "<<<<<<< local")
+	m.out = append(m.out, conflict.LocalLines...)
+
+	if m.style == ConflictStyleDiff3 {
+		m.out = append(m.out, "||||||| base")
+		m.out = append(m.out, conflict.BaseLines...)
+	}
+
+	m.out = append(m.out, "=======")
+	m.out = append(m.out, conflict.RemoteLines...)
+	m.out = append(m.out, ">>>>>>> remote")
+
+	conflict.Merged = LineRange{Start: start + 1, Count: len(m.out) - start}
+	m.conflicts = append(m.conflicts, conflict)
 }
 
+// splitLines splits s into lines, without their newlines. If s does not end
+// with a newline, noNewline follows its last line.
 func splitLines(s string) []string {
 	if s == "" {
 		return nil
@@ -103,32 +300,36 @@ func splitLines(s string) []string {
 
 	lines := strings.Split(s, "\n")
 
-	// Remove trailing empty line from final newline.
-	if len(lines) > 0 && lines[len(lines)-1] == "" {
-		lines = lines[:len(lines)-1]
+	// Split leaves an empty line after a final newline and none otherwise.
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
 	}
 
-	return lines
+	return append(lines, noNewline)
 }
 
-func getLine(lines []string, i int) string {
-	if i < len(lines) {
-		return lines[i]
-	}
+// joinLines joins lines as split by splitLines.
+func joinLines(lines []string) string {
+	var b strings.Builder
 
-	return ""
-}
+	for _, line := range lines {
+		if line == noNewline {
+			// Only a final newline can be missing.
+			return strings.TrimSuffix(b.String(), "\n")
+		}
 
-func linesEqual(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
+		b.WriteString(line)
+		b.WriteByte('\n')
 	}
 
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
+	return b.String()
+}
+
+// withoutNoNewline returns lines without a trailing noNewline.
+func withoutNoNewline(lines []string) []string {
+	if len(lines) > 0 && lines[len(lines)-1] == noNewline {
+		return lines[:len(lines)-1]
 	}
 
-	return true
+	return lines
 }
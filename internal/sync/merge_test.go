@@ -135,3 +135,179 @@ func TestThreeWayMerge_MultipleConflicts(t *testing.T) {
 	assert.True(t, result.HasConflicts)
 	assert.Len(t, result.Conflicts, 2)
 }
+
+func TestThreeWayMerge_Binary(t *testing.T) {
+	t.Parallel()
+
+	base := []byte("PNG\x00base")
+	local := []byte("PNG\x00local")
+	remote := []byte("PNG\x00remote")
+
+	// Only remote changed: remote wins.
+	result := forgesync.ThreeWayMerge(base, base, remote)
+	assert.False(t, result.HasConflicts)
+	assert.Equal(t, remote, result.Content)
+
+	// Only local changed: local is kept.
+	result = forgesync.ThreeWayMerge(base, local, base)
+	assert.False(t, result.HasConflicts)
+	assert.Equal(t, local, result.Content)
+
+	// Both changed: local is kept untouched, without conflict markers.
+	result = forgesync.ThreeWayMerge(base, local, remote)
+	assert.True(t, result.HasConflicts)
+	assert.Len(t, result.Conflicts, 1)
+	assert.Equal(t, local, result.Content)
+}
+
+func TestThreeWayMerge_InsertedLinesAlign(t *testing.T) {
+	t.Parallel()
+
+	base := []byte("a\nb\nc\nd\n")
+	local := []byte("a\nb\nc\nd-local\n")
+	remote := []byte("header\n\na\nb\nc\nd\n")
+
+	result := forgesync.ThreeWayMerge(base, local, remote)
+
+	require.NotNil(t, result)
+	assert.False(t, result.HasConflicts)
+	assert.Equal(t, "header\n\na\nb\nc\nd-local\n", string(result.Content))
+}
+
+func TestThreeWayMerge_DeletionAndEdit(t *testing.T) {
+	t.Parallel()
+
+	base := []byte("a\nb\nc\nd\ne\n")
+	local := []byte("a\nc\nd\ne\n")
+	remote := []byte("a\nb\nc\nd\ne-remote\nf\n")
+
+	result := forgesync.ThreeWayMerge(base, local, remote)
+
+	require.NotNil(t, result)
+	assert.False(t, result.HasConflicts)
+	assert.Equal(t, "a\nc\nd\ne-remote\nf\n", string(result.Content))
+}
+
+func TestThreeWayMerge_MultiLineConflict(t *testing.T) {
+	t.Parallel()
+
+	base := []byte("a\nb\nc\nd\n")
+	local := []byte("a\nb-local\nc-local\nd\n")
+	remote := []byte("a\nb-remote\nd\n")
+
+	result := forgesync.ThreeWayMerge(base, local, remote)
+
+	require.NotNil(t, result)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t,
+		"a\n<<<<<<< local\nb-local\nc-local\n=======\nb-remote\n>>>>>>> remote\nd\n",
+		string(result.Content))
+
+	conflict := result.Conflicts[0]
+	assert.Equal(t, []string{"b", "c"}, conflict.BaseLines)
+	assert.Equal(t, []string{"b-local", "c-local"}, conflict.LocalLines)
+	assert.Equal(t, []string{"b-remote"}, conflict.RemoteLines)
+	assert.Equal(t, forgesync.LineRange{Start: 2, Count: 2}, conflict.Base)
+	assert.Equal(t, forgesync.LineRange{Start: 2, Count: 2}, conflict.Local)
+	assert.Equal(t, forgesync.LineRange{Start: 2, Count: 1}, conflict.Remote)
+	assert.Equal(t, forgesync.LineRange{Start: 2, Count: 6}, conflict.Merged)
+}
+
+func TestThreeWayMerge_ConflictStyleDiff3(t *testing.T) {
+	t.Parallel()
+
+	base := []byte("a\nb\nc\n")
+	local := []byte("a\nb-local\nc\n")
+	remote := []byte("a\nb-remote\nc\n")
+
+	result := forgesync.ThreeWayMergeStyle(base, local, remote, forgesync.ConflictStyleDiff3)
+
+	require.NotNil(t, result)
+	assert.True(t, result.HasConflicts)
+	assert.Equal(t,
+		"a\n<<<<<<< local\nb-local\n||||||| base\nb\n=======\nb-remote\n>>>>>>> remote\nc\n",
+		string(result.Content))
+	assert.Equal(t, forgesync.LineRange{Start: 2, Count: 7}, result.Conflicts[0].Merged)
+
+	resolved := forgesync.StripConflictMarkers(string(result.Content), "local")
+	assert.Equal(t, "a\nb-local\nc\n", resolved)
+}
+
+func TestThreeWayMerge_TrailingNewline(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		base   string
+		local  string
+		remote string
+		want   string
+	}{
+		{
+			name:   "kept missing",
+			base:   "a\nb\nc",
+			local:  "a-local\nb\nc",
+			remote: "a\nb\nc-remote",
+			want:   "a-local\nb\nc-remote",
+		},
+		{
+			name:   "added upstream",
+			base:   "a\nb\nc",
+			local:  "a-local\nb\nc",
+			remote: "a\nb\nc\n",
+			want:   "a-local\nb\nc\n",
+		},
+		{
+			name:   "removed locally",
+			base:   "a\nb\nc\n",
+			local:  "a\nb\nc",
+			remote: "a-remote\nb\nc\n",
+			want:   "a-remote\nb\nc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := forgesync.ThreeWayMerge([]byte(tt.base), []byte(tt.local), []byte(tt.remote))
+
+			require.NotNil(t, result)
+			assert.False(t, result.HasConflicts)
+			assert.Equal(t, tt.want, string(result.Content))
+		})
+	}
+}
+
+func TestThreeWayMerge_ConflictWithoutTrailingNewline(t *testing.T) {
+	t.Parallel()
+
+	base := []byte("a\nb")
+	local := []byte("a\nb-local")
+	remote := []byte("a\nb-remote")
+
+	result := forgesync.ThreeWayMerge(base, local, remote)
+
+	require.NotNil(t, result)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, []string{"b-local"}, result.Conflicts[0].LocalLines)
+	assert.Equal(t, "a\n<<<<<<< local\nb-local\n=======\nb-remote\n>>>>>>> remote", string(result.Content))
+}
+
+func TestTwoWayMerge(t *testing.T) {
+	t.Parallel()
+
+	// Lines only one side has are kept.
+	result := forgesync.TwoWayMerge([]byte("a\nb\n"), []byte("a\nb\n\nc\n"))
+	assert.False(t, result.HasConflicts)
+	assert.Equal(t, "a\nb\n\nc\n", string(result.Content))
+
+	result = forgesync.TwoWayMerge([]byte("a\nlocal\nb\n"), []byte("a\nb\nremote\n"))
+	assert.False(t, result.HasConflicts)
+	assert.Equal(t, "a\nlocal\nb\nremote\n", string(result.Content))
+
+	// Different lines in the same place conflict.
+	result = forgesync.TwoWayMerge([]byte("a\nlocal\nb\n"), []byte("a\nremote\nb\n"))
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "a\n<<<<<<< local\nlocal\n=======\nremote\n>>>>>>> remote\nb\n", string(result.Content))
+}
@@ -7,16 +7,17 @@ import (
 	"path/filepath"
 )
 
-// applyOverwrite replaces a local file with new content.
-// If dryRun is true, records the change without writing.
-func applyOverwrite(localPath string, newContent []byte, dryRun bool, result *Result) error {
-	// Check if file exists and content differs.
+// applyOverwrite replaces a local file with new content and sets its
+// permission bits to mode. A zero mode leaves existing permissions alone
+// (new files get 0o644). If dryRun is true, records the change without writing.
+func applyOverwrite(localPath string, newContent []byte, mode os.FileMode, dryRun bool, result *Result) error {
+	// Check if file exists and content or mode differs.
 	existing, err := os.ReadFile(filepath.Clean(localPath))
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("reading local file %s: %w", localPath, err)
 	}
 
-	if bytes.Equal(existing, newContent) {
+	if bytes.Equal(existing, newContent) && modeMatches(localPath, mode) {
 		result.Skipped = append(result.Skipped, localPath)
 
 		return nil
@@ -33,11 +34,38 @@ func applyOverwrite(localPath string, newContent []byte, dryRun bool, result *Re
 		return fmt.Errorf("creating directory for %s: %w", localPath, err)
 	}
 
-	if err := os.WriteFile(localPath, newContent, 0o644); err != nil {
+	writeMode := mode
+	if writeMode == 0 {
+		writeMode = 0o644
+	}
+
+	if err := os.WriteFile(localPath, newContent, writeMode); err != nil {
 		return fmt.Errorf("writing %s: %w", localPath, err)
 	}
 
+	// WriteFile only applies the mode to new files.
+	if mode != 0 {
+		if err := os.Chmod(localPath, mode); err != nil {
+			return fmt.Errorf("setting mode on %s: %w", localPath, err)
+		}
+	}
+
 	result.Updated = append(result.Updated, localPath)
 
 	return nil
 }
+
+// modeMatches reports whether the file at path already has the permission
+// bits mode. A zero mode always matches.
+func modeMatches(path string, mode os.FileMode) bool {
+	if mode == 0 {
+		return true
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return info.Mode().Perm() == mode.Perm()
+}
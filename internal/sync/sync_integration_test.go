@@ -1,14 +1,18 @@
 package sync_test
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/donaldgifford/forge/internal/lockfile"
+	"github.com/donaldgifford/forge/internal/registry"
 	forgesync "github.com/donaldgifford/forge/internal/sync"
 )
 
@@ -161,6 +165,84 @@ func TestSync_MergeWithConflict_FullCycle(t *testing.T) {
 	assert.Contains(t, string(content), "line4-remote") // clean merge from remote
 }
 
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test",
+		"GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=test",
+		"GIT_COMMITTER_EMAIL=test@test.com",
+	)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v failed: %s", args, out)
+
+	return strings.TrimSpace(string(out))
+}
+
+// TestSync_MergeWithGitBase_FullCycle tests a three-way merge against a
+// local git registry, with the base checked out at the commit the project
+// was last synced at.
+func TestSync_MergeWithGitBase_FullCycle(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	registryDir := t.TempDir()
+	configPath := filepath.Join(registryDir, "test", "bp", "config.yaml")
+
+	// The registry at the project's commit.
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o750))
+	require.NoError(t, os.WriteFile(configPath, []byte("line1\nline2\nline3\nline4\n"), 0o644))
+	runGit(t, registryDir, "init")
+	runGit(t, registryDir, "add", "-A")
+	runGit(t, registryDir, "commit", "-m", "v1")
+	baseCommit := runGit(t, registryDir, "rev-parse", "HEAD")
+
+	// Remote changed line 4 since.
+	require.NoError(t, os.WriteFile(configPath, []byte("line1\nline2\nline3\nline4-remote\n"), 0o644))
+	runGit(t, registryDir, "commit", "-am", "v2")
+
+	// Local changed line 2.
+	require.NoError(t, os.WriteFile(
+		filepath.Join(projectDir, "config.yaml"),
+		[]byte("line1\nline2-local\nline3\nline4\n"), 0o644,
+	))
+
+	lock := &lockfile.Lockfile{
+		Blueprint: lockfile.BlueprintRef{
+			Name:   "test-bp",
+			Path:   "test/bp",
+			Commit: baseCommit,
+		},
+		ManagedFiles: []lockfile.ManagedFileEntry{
+			{Path: "config.yaml", Strategy: "merge"},
+		},
+		Variables: map[string]any{},
+	}
+	require.NoError(t, lockfile.Write(filepath.Join(projectDir, lockfile.FileName), lock))
+
+	baseDir, cleanup, err := registry.Checkout(context.Background(), registryDir, baseCommit)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	result, err := forgesync.Run(&forgesync.Opts{
+		ProjectDir:  projectDir,
+		RegistryDir: registryDir,
+		BaseDir:     baseDir,
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Conflicts)
+	assert.Len(t, result.Updated, 1)
+
+	content, err := os.ReadFile(filepath.Join(projectDir, "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "line1\nline2-local\nline3\nline4-remote\n", string(content))
+}
+
 // TestSync_NewFileCreated verifies that sync creates files that exist
 // in the registry but not locally.
 func TestSync_NewFileCreated(t *testing.T) {
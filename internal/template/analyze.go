@@ -0,0 +1,122 @@
+package template
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// builtinFuncs are the functions text/template predefines.
+var builtinFuncs = []string{
+	"and", "call", "html", "index", "slice", "js", "len", "not", "or",
+	"print", "printf", "println", "urlquery",
+	"eq", "ge", "gt", "le", "lt", "ne",
+}
+
+// Reference is a reference to the root context found by Analyze.
+type Reference struct {
+	// Template is the name of the template (the analyzed text or a partial
+	// it invokes with the root context) holding the reference.
+	Template string
+
+	// Line and Col locate the reference in the template, both 1-based.
+	Line int
+	Col  int
+
+	// Name is the top-level variable referenced, e.g. "config" for
+	// .config.name.
+	Name string
+
+	// Ref is the reference as written, e.g. ".config.name".
+	Ref string
+}
+
+// FuncCall is a call to a function the Renderer does not define.
+type FuncCall struct {
+	Template string
+	Line     int
+	Col      int
+	Name     string
+}
+
+// Analysis lists what a template refers to, as found by Analyze.
+type Analysis struct {
+	// Refs are the references to the root context, in template order.
+	Refs []Reference
+
+	// UnknownFuncs are calls to functions that are neither forge functions,
+	// text/template builtins nor include.
+	UnknownFuncs []FuncCall
+}
+
+// Analyze parses text as the template name, without rendering it, and lists
+// its references to the root context and its calls to unknown functions.
+// Partials the template invokes with the root context are walked too.
+// Unlike rendering, unknown functions are reported rather than failing the
+// parse; syntax errors are returned as an error.
+func (r *Renderer) Analyze(name, text string) (*Analysis, error) {
+	left, right := r.delims()
+	trees := make(map[string]*parse.Tree)
+
+	for _, partial := range slices.Sorted(maps.Keys(r.partials)) {
+		if err := parseTree(partial, r.partials[partial], DefaultLeftDelim, DefaultRightDelim, trees); err != nil {
+			return nil, fmt.Errorf("parsing partial %q: %w", partial, err)
+		}
+	}
+
+	if err := parseTree(name, text, left, right, trees); err != nil {
+		return nil, fmt.Errorf("parsing template %q: %w", name, err)
+	}
+
+	known := maps.Clone(r.funcMap)
+	known["include"] = nil
+
+	for _, fn := range builtinFuncs {
+		known[fn] = nil
+	}
+
+	a := &Analysis{}
+	w := &treeWalker{
+		lookup: func(name string) *parse.Tree { return trees[name] },
+		onRef: func(tree *parse.Tree, node parse.Node, ident []string, ref string, _ bool) {
+			line, col := position(tree, node)
+			a.Refs = append(a.Refs, Reference{Template: tree.ParseName, Line: line, Col: col, Name: ident[0], Ref: ref})
+		},
+		onFunc: func(tree *parse.Tree, node *parse.IdentifierNode) {
+			if _, ok := known[node.Ident]; ok {
+				return
+			}
+
+			line, col := position(tree, node)
+			a.UnknownFuncs = append(a.UnknownFuncs, FuncCall{Template: tree.ParseName, Line: line, Col: col, Name: node.Ident})
+		},
+	}
+	w.walkTemplate(name)
+
+	return a, nil
+}
+
+// AnalyzePath is Analyze for a path template, accepting the {{varname}}
+// shorthand that RenderPath does. Paths without template actions have an
+// empty Analysis.
+func (r *Renderer) AnalyzePath(path string) (*Analysis, error) {
+	left, _ := r.delims()
+	if !strings.Contains(path, left) {
+		return &Analysis{}, nil
+	}
+
+	return r.Analyze(path, r.normalizePathTemplate(path))
+}
+
+// parseTree parses text as the template name into trees, including any
+// templates it defines, without checking that functions exist.
+func parseTree(name, text, left, right string, trees map[string]*parse.Tree) error {
+	t := parse.New(name)
+	t.Mode = parse.SkipFuncCheck
+
+	_, err := t.Parse(text, left, right, trees)
+
+	return err
+}
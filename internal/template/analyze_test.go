@@ -0,0 +1,52 @@
+package template_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+func TestAnalyze_ReferencesAndUnknownFunctions(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer().WithPartials(map[string]string{"header": "# {{ .title }}"})
+
+	text := "{{ .name | snakecase }}\n{{ include \"header\" . }}{{ range .items }}{{ .ignored | upper }}{{ end }}{{ $.config.port }}"
+
+	a, err := r.Analyze("main.go.tmpl", text)
+	require.NoError(t, err)
+
+	assert.Equal(t, []tmpl.Reference{
+		{Template: "main.go.tmpl", Line: 1, Col: 4, Name: "name", Ref: ".name"},
+		{Template: "header", Line: 1, Col: 6, Name: "title", Ref: ".title"},
+		{Template: "main.go.tmpl", Line: 2, Col: 34, Name: "items", Ref: ".items"},
+		{Template: "main.go.tmpl", Line: 2, Col: 78, Name: "config", Ref: "$.config.port"},
+	}, a.Refs)
+	assert.Equal(t, []tmpl.FuncCall{
+		{Template: "main.go.tmpl", Line: 1, Col: 12, Name: "snakecase"},
+	}, a.UnknownFuncs)
+}
+
+func TestAnalyze_SyntaxError(t *testing.T) {
+	t.Parallel()
+
+	_, err := tmpl.NewRenderer().Analyze("bad.tmpl", "{{ if .x }}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.tmpl:1")
+}
+
+func TestAnalyzePath_Shorthand(t *testing.T) {
+	t.Parallel()
+
+	a, err := tmpl.NewRenderer().WithDelimiters("[[", "]]").AnalyzePath("[[project_name]]/cmd/main.go")
+	require.NoError(t, err)
+	require.Len(t, a.Refs, 1)
+	assert.Equal(t, "project_name", a.Refs[0].Name)
+
+	a, err = tmpl.NewRenderer().AnalyzePath("cmd/main.go")
+	require.NoError(t, err)
+	assert.Empty(t, a.Refs)
+}
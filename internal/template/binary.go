@@ -0,0 +1,37 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// sniffLen is the number of leading bytes inspected for binary detection,
+// the same window git uses.
+const sniffLen = 8000
+
+// IsBinary reports whether data looks like binary content: a NUL byte within
+// its first 8000 bytes. Binary content is never rendered or line-merged.
+func IsBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), sniffLen)], 0) >= 0
+}
+
+// IsBinaryFile reports whether the file at path looks like binary content.
+func IsBinaryFile(path string) (bool, error) {
+	f, err := os.Open(path) //nolint:gosec // paths are from registry content, not untrusted user input
+	if err != nil {
+		return false, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return IsBinary(buf[:n]), nil
+}
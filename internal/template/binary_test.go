@@ -0,0 +1,42 @@
+package template_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+func TestIsBinary(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, tmpl.IsBinary(nil))
+	assert.False(t, tmpl.IsBinary([]byte("package main\n\nfunc main() {}\n")))
+	assert.False(t, tmpl.IsBinary([]byte("héllo {{ .name }}\n")))
+	assert.True(t, tmpl.IsBinary([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")))
+}
+
+func TestIsBinaryFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	text := filepath.Join(dir, "a.txt.tmpl")
+	binary := filepath.Join(dir, "logo.png.tmpl")
+	require.NoError(t, os.WriteFile(text, []byte("{{ .name }}\n"), 0o644))
+	require.NoError(t, os.WriteFile(binary, []byte("PNG\x00{{ .name }}"), 0o644))
+
+	got, err := tmpl.IsBinaryFile(text)
+	require.NoError(t, err)
+	assert.False(t, got)
+
+	got, err = tmpl.IsBinaryFile(binary)
+	require.NoError(t, err)
+	assert.True(t, got)
+
+	_, err = tmpl.IsBinaryFile(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@
 package template
 
 import (
+	"maps"
 	"os"
 	"strings"
 	"text/template"
@@ -9,9 +10,10 @@ import (
 	"unicode"
 )
 
-// FuncMap returns the custom template function map used by forge templates.
+// FuncMap returns the custom template function map used by forge templates:
+// casing helpers and the extended function set (see extendedFuncs).
 func FuncMap() template.FuncMap {
-	return template.FuncMap{
+	funcs := template.FuncMap{
 		"snakeCase":  snakeCase,
 		"camelCase":  camelCase,
 		"pascalCase": pascalCase,
@@ -26,6 +28,10 @@ func FuncMap() template.FuncMap {
 		"env":        os.Getenv,
 		"default":    defaultVal,
 	}
+
+	maps.Copy(funcs, extendedFuncs())
+
+	return funcs
 }
 
 // snakeCase converts a string to snake_case.
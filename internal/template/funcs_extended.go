@@ -0,0 +1,317 @@
+package template
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	"gopkg.in/yaml.v3"
+)
+
+// FuncOptions makes the time- and randomness-dependent template functions
+// deterministic, so that the same inputs always render the same output.
+type FuncOptions struct {
+	// Now, if non-zero, is the time the now function reports.
+	Now time.Time
+
+	// Seed, if non-empty, derives uuidv4 values from the seed, the template
+	// (see Renderer.WithSeedKey) and the call position instead of a random
+	// source. Rendering the same template with the same seed yields the same
+	// UUIDs.
+	Seed string
+}
+
+// extendedFuncs returns the general-purpose template functions: string
+// helpers, encoding, collections, regular expressions and semver.
+// Arguments are ordered so the operand comes last, for piping.
+func extendedFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Strings.
+		"indent":    indent,
+		"nindent":   nindent,
+		"quote":     quote,
+		"squote":    squote,
+		"trim":      strings.TrimSpace,
+		"contains":  contains,
+		"hasPrefix": hasPrefix,
+		"hasSuffix": hasSuffix,
+		"join":      join,
+		"split":     split,
+		"repeat":    repeat,
+
+		// Regular expressions.
+		"regexMatch":      regexMatch,
+		"regexReplaceAll": regexReplaceAll,
+
+		// Encoding and hashing.
+		"toYaml":       toYaml,
+		"toJson":       toJSON,
+		"toPrettyJson": toPrettyJSON,
+		"b64enc":       b64enc,
+		"b64dec":       b64dec,
+		"sha256sum":    sha256sum,
+
+		// Collections and logic.
+		"list":    list,
+		"dict":    dict,
+		"ternary": ternary,
+
+		// Versions and identifiers.
+		"semverCompare": semverCompare,
+		"uuidv4":        randomUUID,
+	}
+}
+
+// indent prefixes every line of s with n spaces.
+// Usage: {{ include "job" . | indent 4 }}.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// nindent is indent preceded by a newline, for use after a YAML key.
+func nindent(n int, s string) string {
+	return "\n" + indent(n, s)
+}
+
+// quote wraps the string form of v in double quotes, escaping as Go does.
+func quote(v any) string {
+	return strconv.Quote(toString(v))
+}
+
+// squote wraps the string form of v in single quotes.
+func squote(v any) string {
+	return "'" + toString(v) + "'"
+}
+
+// contains reports whether substr is within s.
+func contains(substr, s string) bool {
+	return strings.Contains(s, substr)
+}
+
+// hasPrefix reports whether s begins with prefix.
+func hasPrefix(prefix, s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// hasSuffix reports whether s ends with suffix.
+func hasSuffix(suffix, s string) bool {
+	return strings.HasSuffix(s, suffix)
+}
+
+// join joins the elements of a list (a []string, []any or any slice) with sep.
+func join(sep string, v any) string {
+	return strings.Join(toStrings(v), sep)
+}
+
+// split splits s around each sep into a list.
+func split(sep, s string) []string {
+	return strings.Split(s, sep)
+}
+
+// repeat returns s repeated n times.
+func repeat(n int, s string) string {
+	return strings.Repeat(s, n)
+}
+
+// regexMatch reports whether s contains a match of the regular expression.
+func regexMatch(regex, s string) (bool, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(s), nil
+}
+
+// regexReplaceAll replaces matches of regex in s with repl, which may refer
+// to submatches as $1 or ${name}.
+// Usage: {{ .name | regexReplaceAll "[^a-z0-9]+" "-" }}.
+func regexReplaceAll(regex, repl, s string) (string, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return "", err
+	}
+
+	return re.ReplaceAllString(s, repl), nil
+}
+
+// toYaml encodes v as YAML, without the trailing newline.
+func toYaml(v any) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// toJSON encodes v as compact JSON.
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// toPrettyJSON encodes v as JSON indented with two spaces.
+func toPrettyJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// b64enc encodes s with standard base64.
+func b64enc(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// b64dec decodes standard base64.
+func b64dec(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// sha256sum returns the hex-encoded SHA-256 digest of s.
+func sha256sum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// list returns its arguments as a list.
+func list(items ...any) []any {
+	return items
+}
+
+// dict builds a map from alternating keys and values:
+// {{ dict "name" .project_name "port" 8080 }}.
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires an even number of arguments, got %d", len(pairs))
+	}
+
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		m[toString(pairs[i])] = pairs[i+1]
+	}
+
+	return m, nil
+}
+
+// ternary returns trueVal if cond is true, else falseVal:
+// {{ .use_tls | ternary "https" "http" }}.
+func ternary(trueVal, falseVal any, cond bool) any {
+	if cond {
+		return trueVal
+	}
+
+	return falseVal
+}
+
+// semverCompare reports whether ver satisfies constraint, e.g.
+// {{ semverCompare ">= 1.22" .go_version }}. Constraints are comma-separated
+// comparisons (=, !=, >, <, >=, <=, and ~> for "pessimistic" matching).
+func semverCompare(constraint, ver string) (bool, error) {
+	c, err := version.NewConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := version.NewVersion(ver)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Check(v), nil
+}
+
+// randomUUID returns a random version 4 UUID.
+func randomUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	return formatUUID(b), nil
+}
+
+// seededUUIDFunc returns a uuidv4 function that derives each UUID from the
+// seed, the key and text of the template and how many UUIDs the template has
+// generated so far.
+func seededUUIDFunc(seed, key, text string) func() string {
+	n := 0
+
+	return func() string {
+		h := sha256.New()
+		h.Write([]byte(seed + "\x00" + key + "\x00" + text + "\x00"))
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(n)))
+		n++
+
+		var b [16]byte
+		copy(b[:], h.Sum(nil))
+
+		return formatUUID(b)
+	}
+}
+
+// formatUUID sets the version 4 and variant bits and formats b as a UUID.
+func formatUUID(b [16]byte) string {
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// toString formats v for string functions; nil becomes "".
+func toString(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(v)
+}
+
+// toStrings converts any slice to a []string.
+func toStrings(v any) []string {
+	if s, ok := v.([]string); ok {
+		return s
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []string{toString(v)}
+	}
+
+	out := make([]string, rv.Len())
+	for i := range rv.Len() {
+		out[i] = toString(rv.Index(i).Interface())
+	}
+
+	return out
+}
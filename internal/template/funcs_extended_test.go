@@ -0,0 +1,156 @@
+package template_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+func TestFuncMap_Extended(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer()
+	vars := map[string]any{
+		"items":  []any{"a", "b", "c"},
+		"names":  []string{"x", "y"},
+		"config": map[string]any{"name": "api", "port": 8080},
+		"tls":    true,
+	}
+
+	tests := []struct {
+		tmplStr  string
+		expected string
+	}{
+		{`{{ "a\nb" | indent 2 }}`, "  a\n  b"},
+		{`key:{{ "a\nb" | nindent 2 }}`, "key:\n  a\n  b"},
+		{`{{ "say \"hi\"" | quote }}`, `"say \"hi\""`},
+		{`{{ 42 | quote }}`, `"42"`},
+		{`{{ "x" | squote }}`, "'x'"},
+		{`{{ "  x  " | trim }}`, "x"},
+		{`{{ "foobar" | contains "oba" }}`, "true"},
+		{`{{ "foobar" | hasPrefix "foo" }}`, "true"},
+		{`{{ "foobar" | hasSuffix "foo" }}`, "false"},
+		{`{{ .items | join ", " }}`, "a, b, c"},
+		{`{{ .names | join "-" }}`, "x-y"},
+		{`{{ range split "," "a,b" }}[{{ . }}]{{ end }}`, "[a][b]"},
+		{`{{ "ab" | repeat 3 }}`, "ababab"},
+		{`{{ "v1.2.3" | regexMatch "^v[0-9]+" }}`, "true"},
+		{`{{ regexReplaceAll "[^a-z]+" "-" "My Project!" }}`, "-y-roject-"},
+		{`{{ "My Project!" | regexReplaceAll "[^a-z]+" "-" }}`, "-y-roject-"},
+		{`{{ regexReplaceAll "(\\w+)@(\\w+)" "$2/$1" "me@host" }}`, "host/me"},
+		{`{{ .config | toYaml }}`, "name: api\nport: 8080"},
+		{`{{ .config | toJson }}`, `{"name":"api","port":8080}`},
+		{`{{ list 1 "a" | toPrettyJson }}`, "[\n  1,\n  \"a\"\n]"},
+		{`{{ "forge" | b64enc }}`, "Zm9yZ2U="},
+		{`{{ "Zm9yZ2U=" | b64dec }}`, "forge"},
+		{`{{ "abc" | sha256sum }}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`{{ $d := dict "name" "api" "port" 80 }}{{ $d.name }}:{{ $d.port }}`, "api:80"},
+		{`{{ .tls | ternary "https" "http" }}`, "https"},
+		{`{{ semverCompare ">= 1.22, < 2" "1.25.4" }}`, "true"},
+		{`{{ semverCompare "~> 1.2.0" "1.3.0" }}`, "false"},
+	}
+
+	for _, tt := range tests {
+		result, err := r.RenderString(tt.tmplStr, vars)
+		require.NoError(t, err, "template: %s", tt.tmplStr)
+		assert.Equal(t, tt.expected, result, "template: %s", tt.tmplStr)
+	}
+}
+
+func TestFuncMap_ExtendedErrors(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer()
+
+	for _, tmplStr := range []string{
+		`{{ dict "odd" }}`,
+		`{{ "x" | regexMatch "(" }}`,
+		`{{ "!!" | b64dec }}`,
+		`{{ semverCompare "nonsense" "1.0.0" }}`,
+		`{{ semverCompare ">= 1.0" "not-a-version" }}`,
+	} {
+		_, err := r.RenderString(tmplStr, nil)
+		require.Error(t, err, "template: %s", tmplStr)
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestFuncMap_UUIDv4(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer()
+
+	a, err := r.RenderString(`{{ uuidv4 }}`, nil)
+	require.NoError(t, err)
+	b, err := r.RenderString(`{{ uuidv4 }}`, nil)
+	require.NoError(t, err)
+
+	assert.Regexp(t, uuidPattern, a)
+	assert.NotEqual(t, a, b)
+}
+
+func TestRenderer_WithFuncOptions(t *testing.T) {
+	t.Parallel()
+
+	fixed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := tmpl.NewRenderer().WithFuncOptions(tmpl.FuncOptions{Now: fixed, Seed: "s1"})
+
+	year, err := r.RenderString(`{{ now "2006-01-02" }}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-01", year)
+
+	first, err := r.RenderString(`{{ uuidv4 }} {{ uuidv4 }}`, nil)
+	require.NoError(t, err)
+	again, err := r.RenderString(`{{ uuidv4 }} {{ uuidv4 }}`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, first, again, "same seed and template should render the same UUIDs")
+
+	ids := strings.Fields(first)
+	require.Len(t, ids, 2)
+	assert.Regexp(t, uuidPattern, ids[0])
+	assert.NotEqual(t, ids[0], ids[1], "UUIDs within a template should differ")
+
+	other, err := tmpl.NewRenderer().WithFuncOptions(tmpl.FuncOptions{Seed: "s2"}).RenderString(`{{ uuidv4 }} {{ uuidv4 }}`, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other)
+}
+
+func TestRenderer_SeededUUIDsPerFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{ .name }} {{ uuidv4 }}"), 0o644))
+
+	r := tmpl.NewRenderer().WithFuncOptions(tmpl.FuncOptions{Seed: "s1"})
+
+	uuid := func(key, name string) string {
+		t.Helper()
+
+		out, err := r.WithSeedKey(key).RenderFile(path, map[string]any{"name": name})
+		require.NoError(t, err)
+
+		fields := strings.Fields(string(out))
+		require.Len(t, fields, 2)
+
+		return fields[1]
+	}
+
+	// One template rendered twice, for two outputs with different data.
+	a := uuid("services/a.yaml", "a")
+	assert.NotEqual(t, a, uuid("services/b.yaml", "b"))
+
+	// Identical templates in different places.
+	assert.NotEqual(t, uuid("a/config.yaml.tmpl", "x"), uuid("b/config.yaml.tmpl", "x"))
+
+	// The same file renders the same UUIDs every time.
+	assert.Equal(t, a, uuid("services/a.yaml", "a"))
+}
@@ -3,9 +3,11 @@ package template
 import (
 	"bytes"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"text/template"
 )
@@ -14,21 +16,134 @@ import (
 // It normalizes shorthand path variables to Go template syntax: {{varname}} → {{.varname}}.
 var pathVarPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
 
+// Default action delimiters used when a Renderer has no custom delimiters.
+const (
+	DefaultLeftDelim  = "{{"
+	DefaultRightDelim = "}}"
+)
+
 // Renderer renders Go text/templates with the forge custom function map.
 type Renderer struct {
 	funcMap template.FuncMap
+
+	// leftDelim and rightDelim are the action delimiters; empty means the
+	// Go defaults.
+	leftDelim  string
+	rightDelim string
+
+	// pathVar matches shorthand path variables for the delimiters in use.
+	pathVar *regexp.Regexp
+
+	// partials are named templates available to every render, keyed by name.
+	partials map[string]string
+
+	// opts makes now and uuidv4 deterministic.
+	opts FuncOptions
+
+	// seedKey, if set, identifies the file being rendered to a seeded
+	// uuidv4 in place of the template's name.
+	seedKey string
+
+	// strict makes undefined variables in file templates an error.
+	strict bool
 }
 
+// maxIncludeDepth bounds nested include calls so a partial that includes
+// itself fails instead of overflowing the stack.
+const maxIncludeDepth = 100
+
 // NewRenderer creates a Renderer with the standard forge function map.
 func NewRenderer() *Renderer {
 	return &Renderer{
 		funcMap: FuncMap(),
+		pathVar: pathVarPattern,
+	}
+}
+
+// WithDelimiters returns a copy of r that uses left and right as action
+// delimiters in RenderFile, RenderString and RenderPath, for templating files
+// that contain {{ }} themselves. Empty delimiters keep the defaults.
+func (r *Renderer) WithDelimiters(left, right string) *Renderer {
+	if left == "" || right == "" || (left == DefaultLeftDelim && right == DefaultRightDelim) {
+		return r
+	}
+
+	c := *r
+	c.leftDelim = left
+	c.rightDelim = right
+	c.pathVar = regexp.MustCompile(
+		regexp.QuoteMeta(left) + `\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*` + regexp.QuoteMeta(right),
+	)
+
+	return &c
+}
+
+// WithDelimiterPair is WithDelimiters for delimiters recorded as a
+// [left, right] pair, as the lockfile records them. Anything but a pair
+// keeps r's delimiters.
+func (r *Renderer) WithDelimiterPair(delims []string) *Renderer {
+	if len(delims) != 2 {
+		return r
+	}
+
+	return r.WithDelimiters(delims[0], delims[1])
+}
+
+// WithFuncOptions returns a copy of r whose now and uuidv4 functions follow
+// opts, making renders reproducible.
+func (r *Renderer) WithFuncOptions(opts FuncOptions) *Renderer {
+	c := *r
+	c.opts = opts
+
+	return &c
+}
+
+// WithSeedKey returns a copy of r whose seeded uuidv4 derives its values
+// from key rather than from the template's file name. Callers pass a path
+// that is the same whenever the file is rendered and unique to it, such as
+// its path relative to the registry or the project, so that files rendered
+// from one template, or from identical templates, get different UUIDs.
+func (r *Renderer) WithSeedKey(key string) *Renderer {
+	c := *r
+	c.seedKey = key
+
+	return &c
+}
+
+// WithStrict returns a copy of r in which RenderFile fails on references to
+// undefined variables, reporting each with its template, line and column.
+// Values passed to the default function may still be undefined.
+func (r *Renderer) WithStrict(strict bool) *Renderer {
+	c := *r
+	c.strict = strict
+
+	return &c
+}
+
+// WithPartials returns a copy of r that makes partials (template text keyed
+// by name) available to every template, either with {{ template "name" . }}
+// or with the include function, whose output can be piped. Partials use
+// the default {{ }} delimiters even in files with custom ones.
+func (r *Renderer) WithPartials(partials map[string]string) *Renderer {
+	c := *r
+	c.partials = partials
+
+	return &c
+}
+
+// delims returns the action delimiters r renders with.
+func (r *Renderer) delims() (left, right string) {
+	if r.leftDelim == "" {
+		return DefaultLeftDelim, DefaultRightDelim
 	}
+
+	return r.leftDelim, r.rightDelim
 }
 
 // RenderFile reads a template file and renders it with the given variables.
 // File templates use missingkey=zero to allow the default function to work
-// with optional variables.
+// with optional variables. In strict mode (see WithStrict) undefined
+// variables are an error instead.
 func (r *Renderer) RenderFile(tmplPath string, vars map[string]any) ([]byte, error) {
 	data, err := os.ReadFile(tmplPath) //nolint:gosec // template paths are from registry content, not untrusted user input
 	if err != nil {
@@ -37,7 +152,63 @@ func (r *Renderer) RenderFile(tmplPath string, vars map[string]any) ([]byte, err
 
 	name := filepath.Base(tmplPath)
 
-	return r.renderWithOption(name, string(data), vars, "missingkey=zero")
+	if !r.strict {
+		return r.renderWithOption(name, string(data), vars, "missingkey=zero")
+	}
+
+	tmpl, err := r.parse(name, string(data), "missingkey=error")
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err = checkUndefined(tmpl, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return execute(tmpl, vars)
+}
+
+// ReadSource reads the source file at path, rendering it with vars if it is
+// a template. Verbatim (copy_without_render) and binary sources are read
+// as-is.
+func (r *Renderer) ReadSource(path string, verbatim bool, vars map[string]any) ([]byte, error) {
+	if IsTemplate(path) && !verbatim {
+		binary, err := IsBinaryFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !binary {
+			content, err := r.RenderFile(path, vars)
+			if err != nil {
+				return nil, fmt.Errorf("rendering template %s: %w", path, err)
+			}
+
+			return content, nil
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading source %s: %w", path, err)
+	}
+
+	return content, nil
+}
+
+// Check parses text as the template name and reports every reference to an
+// undefined variable as an *UndefinedError, without rendering. References
+// passed to the default function are allowed.
+func (r *Renderer) Check(name, text string, vars map[string]any) error {
+	tmpl, err := r.parse(name, text, "missingkey=error")
+	if err != nil {
+		return err
+	}
+
+	_, err = checkUndefined(tmpl, vars)
+
+	return err
 }
 
 // RenderString renders an inline template string with the given variables.
@@ -56,13 +227,15 @@ func (r *Renderer) RenderString(tmpl string, vars map[string]any) (string, error
 //
 // Path templates support shorthand syntax: {{varname}} is normalized to {{.varname}}
 // so that directory names like "{{project_name}}" work without requiring the dot prefix.
+// With custom delimiters the same applies, e.g. "[[project_name]]".
 func (r *Renderer) RenderPath(path string, vars map[string]any) (string, error) {
-	if !strings.Contains(path, "{{") {
+	left, _ := r.delims()
+	if !strings.Contains(path, left) {
 		return path, nil
 	}
 
 	// Normalize {{varname}} → {{.varname}} for path convenience.
-	normalized := normalizePathTemplate(path)
+	normalized := r.normalizePathTemplate(path)
 
 	result, err := r.RenderString(normalized, vars)
 	if err != nil {
@@ -76,16 +249,18 @@ func (r *Renderer) RenderPath(path string, vars map[string]any) (string, error)
 // This allows directory names like "{{project_name}}" to work without requiring the
 // Go template dot prefix. Expressions that already use dot notation (e.g., {{.varname}})
 // or contain function calls/pipes are left unchanged.
-func normalizePathTemplate(path string) string {
-	return pathVarPattern.ReplaceAllStringFunc(path, func(match string) string {
-		inner := strings.TrimSpace(match[2 : len(match)-2])
+func (r *Renderer) normalizePathTemplate(path string) string {
+	left, right := r.delims()
+
+	return r.pathVar.ReplaceAllStringFunc(path, func(match string) string {
+		inner := strings.TrimSpace(match[len(left) : len(match)-len(right)])
 
 		// Already has a dot prefix — leave it alone.
 		if strings.HasPrefix(inner, ".") {
 			return match
 		}
 
-		return "{{." + inner + "}}"
+		return left + "." + inner + right
 	})
 }
 
@@ -104,18 +279,84 @@ func (r *Renderer) render(name, text string, vars map[string]any) ([]byte, error
 }
 
 func (r *Renderer) renderWithOption(name, text string, vars map[string]any, option string) ([]byte, error) {
-	tmpl, err := template.New(name).
+	tmpl, err := r.parse(name, text, option)
+	if err != nil {
+		return nil, err
+	}
+
+	return execute(tmpl, vars)
+}
+
+// parse parses text as the template name, together with r's partials.
+// Partials always use the default delimiters, whatever those of the file
+// including them.
+func (r *Renderer) parse(name, text, option string) (*template.Template, error) {
+	root := template.New(name).
+		Delims(r.leftDelim, r.rightDelim).
 		Funcs(r.funcMap).
-		Option(option).
-		Parse(text)
+		Option(option)
+	root.Funcs(template.FuncMap{"include": includeFunc(root)})
+	r.applyFuncOptions(root, name, text)
+
+	for _, partial := range slices.Sorted(maps.Keys(r.partials)) {
+		if _, err := root.New(partial).Delims(DefaultLeftDelim, DefaultRightDelim).Parse(r.partials[partial]); err != nil {
+			return nil, fmt.Errorf("parsing partial %q: %w", partial, err)
+		}
+	}
+
+	tmpl, err := root.Parse(text)
 	if err != nil {
 		return nil, fmt.Errorf("parsing template %q: %w", name, err)
 	}
 
+	return tmpl, nil
+}
+
+func execute(tmpl *template.Template, vars map[string]any) ([]byte, error) {
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, vars); err != nil {
-		return nil, fmt.Errorf("executing template %q: %w", name, err)
+		return nil, fmt.Errorf("executing template %q: %w", tmpl.Name(), err)
 	}
 
 	return buf.Bytes(), nil
 }
+
+// applyFuncOptions overrides now and uuidv4 on root when r has
+// deterministic options.
+func (r *Renderer) applyFuncOptions(root *template.Template, name, text string) {
+	if !r.opts.Now.IsZero() {
+		fixed := r.opts.Now
+		root.Funcs(template.FuncMap{"now": func(layout string) string { return fixed.Format(layout) }})
+	}
+
+	if r.opts.Seed != "" {
+		key := name
+		if r.seedKey != "" {
+			key = r.seedKey
+		}
+
+		root.Funcs(template.FuncMap{"uuidv4": seededUUIDFunc(r.opts.Seed, key, text)})
+	}
+}
+
+// includeFunc returns the include template function for root: it executes
+// the named template and returns its output as a string.
+func includeFunc(root *template.Template) func(string, any) (string, error) {
+	depth := 0
+
+	return func(name string, data any) (string, error) {
+		if depth >= maxIncludeDepth {
+			return "", fmt.Errorf("include %q: nested more than %d levels", name, maxIncludeDepth)
+		}
+
+		depth++
+		defer func() { depth-- }()
+
+		var buf strings.Builder
+		if err := root.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+
+		return buf.String(), nil
+	}
+}
@@ -119,6 +119,130 @@ func TestRenderPath(t *testing.T) {
 	}
 }
 
+func TestRenderer_WithDelimiters(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer().WithDelimiters("[[", "]]")
+	vars := map[string]any{"project_name": "my-api"}
+
+	got, err := r.RenderString("name: [[ .project_name | upper ]] value: {{ .Values.x }}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "name: MY-API value: {{ .Values.x }}", got)
+
+	path, err := r.RenderPath("[[project_name]]/{{keep}}/main.go", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "my-api/{{keep}}/main.go", path)
+
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "workflow.yml.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("name: [[ .project_name ]]\nref: ${{ github.ref }}\n"), 0o644))
+
+	content, err := r.RenderFile(tmplPath, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "name: my-api\nref: ${{ github.ref }}\n", string(content))
+}
+
+func TestRenderer_WithDelimitersDefaults(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer()
+	assert.Same(t, r, r.WithDelimiters("", ""))
+	assert.Same(t, r, r.WithDelimiters("{{", "}}"))
+}
+
+func TestRenderer_WithDelimiterPair(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer()
+	assert.Same(t, r, r.WithDelimiterPair(nil))
+	assert.Same(t, r, r.WithDelimiterPair([]string{"[["}))
+
+	got, err := r.WithDelimiterPair([]string{"[[", "]]"}).RenderString("[[ .name ]] {{ .name }}", map[string]any{"name": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "x {{ .name }}", got)
+}
+
+func TestRenderer_ReadSource(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tmplPath := filepath.Join(dir, "README.md.tmpl")
+	plainPath := filepath.Join(dir, "Makefile")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("# {{ .name }}\n"), 0o644))
+	require.NoError(t, os.WriteFile(plainPath, []byte("# {{ .name }}\n"), 0o644))
+
+	r := tmpl.NewRenderer()
+	vars := map[string]any{"name": "demo"}
+
+	content, err := r.ReadSource(tmplPath, false, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "# demo\n", string(content))
+
+	content, err = r.ReadSource(tmplPath, true, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "# {{ .name }}\n", string(content), "verbatim templates are read as-is")
+
+	content, err = r.ReadSource(plainPath, false, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "# {{ .name }}\n", string(content))
+
+	_, err = r.ReadSource(filepath.Join(dir, "missing.tmpl"), false, vars)
+	require.Error(t, err)
+}
+
+func TestRenderer_WithPartials(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer().WithPartials(map[string]string{
+		"license-header": "// Copyright {{ .owner }}",
+		"ci/job":         "job:\n  name: {{ .name }}",
+	})
+	vars := map[string]any{"owner": "Acme", "name": "test"}
+
+	got, err := r.RenderString("{{ template \"license-header\" . }}\npackage main", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "// Copyright Acme\npackage main", got)
+
+	got, err = r.RenderString("{{ include \"ci/job\" . | upper }}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "JOB:\n  NAME: TEST", got)
+
+	_, err = r.RenderString("{{ include \"missing\" . }}", vars)
+	require.Error(t, err)
+}
+
+func TestRenderer_PartialsKeepDefaultDelimiters(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer().
+		WithPartials(map[string]string{"header": "# {{ .name }}"}).
+		WithDelimiters("[[", "]]")
+	vars := map[string]any{"name": "test"}
+
+	got, err := r.RenderString("[[ include \"header\" . ]]\nrun: ${{ github.sha }}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "# test\nrun: ${{ github.sha }}", got)
+
+	got, err = r.RenderString("[[ template \"header\" . ]]", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "# test", got)
+
+	analysis, err := r.Analyze("file", "[[ include \"header\" . ]]")
+	require.NoError(t, err)
+	require.Len(t, analysis.Refs, 1)
+	assert.Equal(t, "name", analysis.Refs[0].Name)
+}
+
+func TestRenderer_IncludeRecursion(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer().WithPartials(map[string]string{"loop": "{{ include \"loop\" . }}"})
+
+	_, err := r.RenderString("{{ include \"loop\" . }}", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nested more than")
+}
+
 func TestStripTemplateExtension(t *testing.T) {
 	t.Parallel()
 
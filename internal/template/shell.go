@@ -0,0 +1,158 @@
+package template
+
+import (
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// shellQuoting is the quoting context of a position in a shell command.
+type shellQuoting int
+
+const (
+	shellUnquoted shellQuoting = iota
+	shellSingleQuoted
+	shellDoubleQuoted
+)
+
+// shellEscapers names the functions that escape the output of an action for
+// each quoting context.
+var shellEscapers = map[shellQuoting]string{
+	shellUnquoted:     "_forgeShellQuote",
+	shellSingleQuoted: "_forgeShellEscapeSingleQuoted",
+	shellDoubleQuoted: "_forgeShellEscapeDoubleQuoted",
+}
+
+// RenderShell renders a shell command template, such as a post_create hook,
+// with the given variables. The output of every action is escaped for the
+// quoting context it appears in, so a value is always a single word of the
+// command: unquoted values are single-quoted as needed ("my app" becomes
+// 'my app'), and values inside quotes are escaped for them. Templates must
+// therefore not quote values themselves with quote or squote. As in file
+// templates, an undefined variable is an empty word unless r is strict (see
+// WithStrict), when it is an error.
+func (r *Renderer) RenderShell(cmd string, vars map[string]any) (string, error) {
+	option := "missingkey=zero"
+	if r.strict {
+		option = "missingkey=error"
+	}
+
+	tmpl, err := r.parse("inline", cmd, option)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl.Funcs(template.FuncMap{
+		shellEscapers[shellUnquoted]:     shellQuote,
+		shellEscapers[shellSingleQuoted]: escapeSingleQuoted,
+		shellEscapers[shellDoubleQuoted]: escapeDoubleQuoted,
+	})
+
+	escapeShellList(tmpl.Root, shellUnquoted)
+
+	out, err := execute(tmpl, vars)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// escapeShellList appends the escaper for its quoting context to every
+// action in list, starting in context q, and returns the context at the end
+// of the list. The branches of if, range and with are assumed to leave the
+// context as they found it.
+func escapeShellList(list *parse.ListNode, q shellQuoting) shellQuoting {
+	if list == nil {
+		return q
+	}
+
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			q = shellQuotingAfter(q, n.Text)
+		case *parse.ActionNode:
+			// Declarations write nothing.
+			if len(n.Pipe.Decl) == 0 {
+				n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+					NodeType: parse.NodeCommand,
+					Pos:      n.Pos,
+					Args:     []parse.Node{parse.NewIdentifier(shellEscapers[q]).SetPos(n.Pos)},
+				})
+			}
+		case *parse.IfNode:
+			escapeShellList(n.ElseList, q)
+			q = escapeShellList(n.List, q)
+		case *parse.RangeNode:
+			escapeShellList(n.ElseList, q)
+			q = escapeShellList(n.List, q)
+		case *parse.WithNode:
+			escapeShellList(n.ElseList, q)
+			q = escapeShellList(n.List, q)
+		}
+	}
+
+	return q
+}
+
+// shellQuotingAfter returns the quoting context after text, starting in
+// context q.
+func shellQuotingAfter(q shellQuoting, text []byte) shellQuoting {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case q == shellSingleQuoted:
+			if c == '\'' {
+				q = shellUnquoted
+			}
+		case c == '\\':
+			i++
+		case q == shellDoubleQuoted:
+			if c == '"' {
+				q = shellUnquoted
+			}
+		case c == '\'':
+			q = shellSingleQuoted
+		case c == '"':
+			q = shellDoubleQuoted
+		}
+	}
+
+	return q
+}
+
+// shellQuote returns v quoted as a single shell word. Words made only of
+// characters the shell gives no meaning to are returned as they are.
+func shellQuote(v any) string {
+	s := toString(v)
+	if s != "" && strings.Trim(s, shellSafe) == "" {
+		return s
+	}
+
+	return "'" + escapeSingleQuoted(s) + "'"
+}
+
+// shellSafe holds the characters a shell word may contain unquoted.
+const shellSafe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-"
+
+// escapeSingleQuoted escapes v for use inside single quotes, where nothing
+// but the closing quote is special: each quote closes the string, adds an
+// escaped quote and reopens it.
+func escapeSingleQuoted(v any) string {
+	return strings.ReplaceAll(toString(v), "'", `'\''`)
+}
+
+// escapeDoubleQuoted escapes v for use inside double quotes, where
+// backslash, quote, dollar and backquote are special.
+func escapeDoubleQuoted(v any) string {
+	var b strings.Builder
+
+	for _, c := range toString(v) {
+		if strings.ContainsRune("\\\"$`", c) {
+			b.WriteByte('\\')
+		}
+
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
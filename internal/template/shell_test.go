@@ -0,0 +1,57 @@
+package template_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+func TestRenderShell(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]any{
+		"safe":   "my-api",
+		"evil":   "x; rm -rf ~",
+		"quoted": `it's "$(id)"`,
+		"empty":  "",
+	}
+
+	tests := []struct {
+		name string
+		cmd  string
+		want string
+	}{
+		{name: "safe unquoted", cmd: "echo {{ .safe }}", want: "echo my-api"},
+		{name: "unquoted", cmd: "echo {{ .evil }}", want: `echo 'x; rm -rf ~'`},
+		{name: "unquoted quotes", cmd: "echo {{ .quoted }}", want: `echo 'it'\''s "$(id)"'`},
+		{name: "empty", cmd: "echo {{ .empty }} done", want: "echo '' done"},
+		{name: "single-quoted", cmd: "git commit -m 'Scaffold {{ .quoted }}'", want: `git commit -m 'Scaffold it'\''s "$(id)"'`},
+		{name: "double-quoted", cmd: `echo "name: {{ .quoted }}"`, want: `echo "name: it's \"\$(id)\""`},
+		{name: "after quotes", cmd: `echo "a" {{ .evil }} 'b'`, want: `echo "a" 'x; rm -rf ~' 'b'`},
+		{name: "functions", cmd: "echo {{ .evil | upper }}", want: `echo 'X; RM -RF ~'`},
+		{name: "branches", cmd: "{{ if .safe }}echo {{ .evil }}{{ end }}", want: `echo 'x; rm -rf ~'`},
+		{name: "undefined", cmd: "echo {{ .unset }} done", want: "echo '' done"},
+		{name: "undefined in quotes", cmd: `echo "[{{ .unset }}]"`, want: `echo "[]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tmpl.NewRenderer().RenderShell(tt.cmd, vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenderShell_StrictRejectsUndefined(t *testing.T) {
+	t.Parallel()
+
+	_, err := tmpl.NewRenderer().WithStrict(true).RenderShell("echo {{ .unset }}", map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unset")
+}
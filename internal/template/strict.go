@@ -0,0 +1,145 @@
+package template
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// UndefinedRef is a reference to a variable that is not defined.
+type UndefinedRef struct {
+	// Template is the name of the template (file or partial) holding the reference.
+	Template string
+
+	// Line and Col locate the reference in the template, both 1-based.
+	Line int
+	Col  int
+
+	// Ref is the reference as written, e.g. ".projcet_name".
+	Ref string
+}
+
+// String formats the reference as "template:line:col: .ref".
+func (u UndefinedRef) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", u.Template, u.Line, u.Col, u.Ref)
+}
+
+// UndefinedError lists every undefined variable reference found in strict mode.
+type UndefinedError struct {
+	Refs []UndefinedRef
+}
+
+func (e *UndefinedError) Error() string {
+	lines := make([]string, len(e.Refs))
+	for i, ref := range e.Refs {
+		lines[i] = "  " + ref.String()
+	}
+
+	return fmt.Sprintf("%d undefined variable reference(s):\n%s", len(e.Refs), strings.Join(lines, "\n"))
+}
+
+// checkUndefined walks tmpl and the partials it invokes with the root
+// context and reports references to undefined variables. References whose
+// value is passed to default are optional: the returned vars holds a nil
+// placeholder for each missing one, so execution with missingkey=error lets
+// default supply the fallback.
+func checkUndefined(tmpl *template.Template, vars map[string]any) (map[string]any, error) {
+	c := &undefinedChecker{vars: vars}
+	w := &treeWalker{
+		lookup: func(name string) *parse.Tree {
+			if t := tmpl.Lookup(name); t != nil {
+				return t.Tree
+			}
+
+			return nil
+		},
+		onRef: c.checkRef,
+	}
+	w.walkTemplate(tmpl.Name())
+
+	if len(c.refs) > 0 {
+		return nil, &UndefinedError{Refs: c.refs}
+	}
+
+	if len(c.optional) == 0 {
+		return vars, nil
+	}
+
+	result := maps.Clone(vars)
+	for _, ident := range c.optional {
+		setOptional(result, ident)
+	}
+
+	return result, nil
+}
+
+type undefinedChecker struct {
+	vars     map[string]any
+	refs     []UndefinedRef
+	optional [][]string
+}
+
+// checkRef records ident as undefined, or as optional when it is missing
+// but passed to default.
+func (c *undefinedChecker) checkRef(tree *parse.Tree, node parse.Node, ident []string, ref string, optional bool) {
+	if defined(c.vars, ident) {
+		return
+	}
+
+	if optional {
+		c.optional = append(c.optional, ident)
+
+		return
+	}
+
+	line, col := position(tree, node)
+	c.refs = append(c.refs, UndefinedRef{Template: tree.ParseName, Line: line, Col: col, Ref: ref})
+}
+
+// defined reports whether ident resolves in vars. Lookups through values
+// that are not maps cannot be checked statically and count as defined.
+func defined(vars map[string]any, ident []string) bool {
+	var cur any = vars
+
+	for _, name := range ident {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return true
+		}
+
+		cur, ok = m[name]
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setOptional stores a nil placeholder at ident, copying any nested maps
+// on the way so the caller's values are not modified.
+func setOptional(vars map[string]any, ident []string) {
+	m := vars
+
+	for _, name := range ident[:len(ident)-1] {
+		next, ok := m[name].(map[string]any)
+		if !ok {
+			if _, exists := m[name]; exists {
+				return
+			}
+
+			next = map[string]any{}
+		} else {
+			next = maps.Clone(next)
+		}
+
+		m[name] = next
+		m = next
+	}
+
+	if _, exists := m[ident[len(ident)-1]]; !exists {
+		m[ident[len(ident)-1]] = nil
+	}
+}
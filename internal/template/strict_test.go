@@ -0,0 +1,87 @@
+package template_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmpl "github.com/donaldgifford/forge/internal/template"
+)
+
+func TestRenderFile_StrictReportsEveryUndefinedReference(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go.tmpl")
+	text := "package {{ .projcet_name }}\n\n// {{ .description | default \"none\" }}\n{{ if .use_docker }}docker{{ end }}\n{{ $.config.nmae }}\n"
+	require.NoError(t, os.WriteFile(path, []byte(text), 0o644))
+
+	r := tmpl.NewRenderer().WithStrict(true)
+
+	_, err := r.RenderFile(path, map[string]any{
+		"project_name": "api",
+		"config":       map[string]any{"name": "api"},
+	})
+	require.Error(t, err)
+
+	var undef *tmpl.UndefinedError
+	require.True(t, errors.As(err, &undef))
+	assert.Equal(t, []tmpl.UndefinedRef{
+		{Template: "main.go.tmpl", Line: 1, Col: 12, Ref: ".projcet_name"},
+		{Template: "main.go.tmpl", Line: 4, Col: 7, Ref: ".use_docker"},
+		{Template: "main.go.tmpl", Line: 5, Col: 5, Ref: "$.config.nmae"},
+	}, undef.Refs)
+	assert.Contains(t, err.Error(), "main.go.tmpl:1:12: .projcet_name")
+}
+
+func TestRenderFile_StrictAllowsDefault(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "README.md.tmpl")
+	text := "# {{ .name }}\n{{ .description | default \"TODO\" }} {{ default \"none\" .config.owner }}\n"
+	require.NoError(t, os.WriteFile(path, []byte(text), 0o644))
+
+	vars := map[string]any{"name": "api", "config": map[string]any{}}
+	r := tmpl.NewRenderer().WithStrict(true)
+
+	result, err := r.RenderFile(path, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "# api\nTODO none\n", string(result))
+	assert.Empty(t, vars["config"], "caller values should not be modified")
+}
+
+func TestRenderFile_NonStrictRendersZero(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("[{{ .typo }}]"), 0o644))
+
+	result, err := tmpl.NewRenderer().RenderFile(path, map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, "[<no value>]", string(result))
+}
+
+func TestRenderer_CheckFollowsPartials(t *testing.T) {
+	t.Parallel()
+
+	r := tmpl.NewRenderer().WithPartials(map[string]string{
+		"header": "// {{ .ownr }}",
+	})
+
+	err := r.Check("main.go.tmpl", "{{ template \"header\" . }}\n{{ range .items }}{{ .anything }}{{ end }}", map[string]any{"items": []any{}})
+	require.Error(t, err)
+
+	var undef *tmpl.UndefinedError
+	require.True(t, errors.As(err, &undef))
+	require.Len(t, undef.Refs, 1)
+	assert.Equal(t, "header", undef.Refs[0].Template)
+	assert.Equal(t, ".ownr", undef.Refs[0].Ref)
+
+	require.NoError(t, r.Check("ok.tmpl", "{{ include \"header\" . }}", map[string]any{"ownr": "me"}))
+}
@@ -0,0 +1,192 @@
+package template
+
+import (
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// treeWalker walks a template's parse tree, and the partials it invokes with
+// the root context, reporting variable references and function calls.
+//
+// Only references to the root context are reported (.name and $.name, and
+// nested keys of map values). Inside range and with blocks the dot is
+// something else and is left to execution.
+type treeWalker struct {
+	// lookup returns the parse tree of a named template, or nil.
+	lookup func(name string) *parse.Tree
+
+	// onRef is called for each reference to the root context. ident is the
+	// key path (e.g. [config name] for .config.name), ref the reference as
+	// written, and optional is true when the value is passed to default.
+	onRef func(tree *parse.Tree, node parse.Node, ident []string, ref string, optional bool)
+
+	// onFunc, if set, is called for each function name used.
+	onFunc func(tree *parse.Tree, node *parse.IdentifierNode)
+
+	visited map[string]bool
+
+	// tree is the template being walked, for reporting positions.
+	tree *parse.Tree
+}
+
+// walkTemplate walks the named template once.
+func (w *treeWalker) walkTemplate(name string) {
+	t := w.lookup(name)
+	if t == nil || w.visited[name] {
+		return
+	}
+
+	if w.visited == nil {
+		w.visited = map[string]bool{}
+	}
+
+	w.visited[name] = true
+	outer := w.tree
+	w.tree = t
+	w.walk(t.Root, true)
+	w.tree = outer
+}
+
+// walk walks node. rootDot is true when the dot is the root context.
+func (w *treeWalker) walk(node parse.Node, rootDot bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			w.walk(child, rootDot)
+		}
+	case *parse.ActionNode:
+		w.walkPipe(n.Pipe, rootDot)
+	case *parse.IfNode:
+		w.walkBranch(&n.BranchNode, rootDot, rootDot)
+	case *parse.RangeNode:
+		w.walkBranch(&n.BranchNode, rootDot, false)
+	case *parse.WithNode:
+		w.walkBranch(&n.BranchNode, rootDot, false)
+	case *parse.TemplateNode:
+		w.walkPipe(n.Pipe, rootDot)
+
+		if passesRoot(n.Pipe, rootDot) {
+			w.walkTemplate(n.Name)
+		}
+	}
+}
+
+// walkBranch walks an if, range or with node. The body runs with bodyRoot;
+// the else branch keeps the outer dot.
+func (w *treeWalker) walkBranch(b *parse.BranchNode, rootDot, bodyRoot bool) {
+	w.walkPipe(b.Pipe, rootDot)
+	w.walk(b.List, bodyRoot)
+	w.walk(b.ElseList, rootDot)
+}
+
+func (w *treeWalker) walkPipe(pipe *parse.PipeNode, rootDot bool) {
+	if pipe == nil {
+		return
+	}
+
+	for i, cmd := range pipe.Cmds {
+		// ".x | default ..." makes .x optional.
+		pipedToDefault := i+1 < len(pipe.Cmds) && isDefaultCmd(pipe.Cmds[i+1]) && len(cmd.Args) == 1
+
+		for j, arg := range cmd.Args {
+			// "default ... .x" makes .x optional too.
+			optional := pipedToDefault || (j > 0 && isDefaultCmd(cmd))
+			w.walkArg(arg, rootDot, optional)
+		}
+
+		if name, ok := includedTemplate(cmd, rootDot); ok {
+			w.walkTemplate(name)
+		}
+	}
+}
+
+func (w *treeWalker) walkArg(arg parse.Node, rootDot, optional bool) {
+	switch a := arg.(type) {
+	case *parse.FieldNode:
+		if rootDot {
+			w.onRef(w.tree, a, a.Ident, "."+strings.Join(a.Ident, "."), optional)
+		}
+	case *parse.VariableNode:
+		if len(a.Ident) > 1 && a.Ident[0] == "$" && rootDot {
+			w.onRef(w.tree, a, a.Ident[1:], strings.Join(a.Ident, "."), optional)
+		}
+	case *parse.IdentifierNode:
+		if w.onFunc != nil {
+			w.onFunc(w.tree, a)
+		}
+	case *parse.PipeNode:
+		w.walkPipe(a, rootDot)
+	case *parse.ChainNode:
+		w.walkArg(a.Node, rootDot, false)
+	}
+}
+
+func isDefaultCmd(cmd *parse.CommandNode) bool {
+	if len(cmd.Args) == 0 {
+		return false
+	}
+
+	id, ok := cmd.Args[0].(*parse.IdentifierNode)
+
+	return ok && id.Ident == "default"
+}
+
+// passesRoot reports whether pipe is just the root context (. or $).
+func passesRoot(pipe *parse.PipeNode, rootDot bool) bool {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+
+	switch a := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode:
+		return rootDot
+	case *parse.VariableNode:
+		return len(a.Ident) == 1 && a.Ident[0] == "$"
+	}
+
+	return false
+}
+
+// includedTemplate returns the partial named by an include call that passes
+// the root context, e.g. {{ include "header" . }}.
+func includedTemplate(cmd *parse.CommandNode, rootDot bool) (string, bool) {
+	if len(cmd.Args) != 3 {
+		return "", false
+	}
+
+	id, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok || id.Ident != "include" {
+		return "", false
+	}
+
+	name, ok := cmd.Args[1].(*parse.StringNode)
+	if !ok {
+		return "", false
+	}
+
+	pipe := &parse.PipeNode{Cmds: []*parse.CommandNode{{Args: cmd.Args[2:]}}}
+
+	return name.Text, passesRoot(pipe, rootDot)
+}
+
+// position returns the 1-based line and column of node in tree.
+func position(tree *parse.Tree, node parse.Node) (line, col int) {
+	location, _ := tree.ErrorContext(node)
+
+	// location is "name:line:col"; the name may itself contain colons.
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0, 0
+	}
+
+	line, _ = strconv.Atoi(parts[len(parts)-2])
+	col, _ = strconv.Atoi(parts[len(parts)-1])
+
+	// ErrorContext reports a 0-based column.
+	return line, col + 1
+}
@@ -44,6 +44,17 @@ func NewWriterWithOutputs(out, errOut io.Writer, noColor bool) *Writer {
 	}
 }
 
+// Out returns the writer used for standard output. Useful for streaming
+// subprocess output through the same destination as styled messages.
+func (w *Writer) Out() io.Writer {
+	return w.out
+}
+
+// ErrOut returns the writer used for warnings and errors.
+func (w *Writer) ErrOut() io.Writer {
+	return w.errOut
+}
+
 // Success prints a success message with a green checkmark prefix.
 func (w *Writer) Success(msg string) {
 	writeLine(w.out, w.styled(colorGreen, "\u2713"), msg)
@@ -98,3 +98,13 @@ func TestWriter_Bold_WithColor(t *testing.T) {
 	result := w.Bold("text")
 	assert.Contains(t, result, "\033[1m")
 }
+
+func TestWriter_OutAndErrOut(t *testing.T) {
+	t.Parallel()
+
+	var out, errOut bytes.Buffer
+	w := ui.NewWriterWithOutputs(&out, &errOut, true)
+
+	assert.Same(t, &out, w.Out())
+	assert.Same(t, &errOut, w.ErrOut())
+}